@@ -5,6 +5,9 @@
 package indexer
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -16,28 +19,196 @@ type PackedVertex struct {
 	Norm     mgl32.Vec3
 }
 
-// Doesn't work like in C++ because Go maps use ==
-/*func IndexVBO(vertices []mgl32.Vec3, uvs []mgl32.Vec2, normals []mgl32.Vec3) (outIndices []uint16, outVertices []mgl32.Vec3, outUVs []mgl32.Vec2, outNorms []mgl32.Vec3) {
-	vertToOutIndex := make(map[PackedVertex]uint16, 0)
-
+// IndexVBO indexes unindexed vertices, such as the triangles from objloader, merging each vertex into the first
+// earlier one that's similar to it like IndexVBOSlow does: each of their position, UV and normal components are equal
+// within a relative threshold of .01, by mgl32.FloatEqualThreshold. It gives the same result, but hashes the vertices
+// to take about linear rather than quadratic time. The C++ tutorial's map only finds exactly equal vertices, which
+// Go's maps can't improve on. It panics if there are too many distinct vertices for uint16 indices.
+func IndexVBO(vertices []mgl32.Vec3, uvs []mgl32.Vec2, normals []mgl32.Vec3) (outIndices []uint16, outVertices []mgl32.Vec3, outUVs []mgl32.Vec2, outNorms []mgl32.Vec3) {
+	packed := make([]PackedVertex, len(vertices))
 	for i := range vertices {
-		packed := PackedVertex{vertices[i], uvs[i], normals[i]}
+		packed[i] = PackedVertex{vertices[i], uvs[i], normals[i]}
+	}
 
-		index, ok := vertToOutIndex[packed]
-		if ok {
-			outIndices = append(outIndices, index)
-		} else {
-			outVertices = append(outVertices, vertices[i])
-			outUVs = append(outUVs, uvs[i])
-			outNorms = append(outNorms, normals[i])
-			index = uint16(len(outVertices) - 1)
-			outIndices = append(outIndices, index)
-			vertToOutIndex[packed] = index
-		}
+	welded := weld(packed, similarCell, adjacentCells, similarVertices)
+	outIndices, err := welded.Indices16()
+	if err != nil {
+		panic(err)
+	}
+	for _, v := range welded.Vertices {
+		outVertices = append(outVertices, v.Position)
+		outUVs = append(outUVs, v.UV)
+		outNorms = append(outNorms, v.Norm)
 	}
 
 	return
-}*/
+}
+
+// Welded is a set of vertices without duplicates, and the indices into them that make up the original vertices.
+type Welded struct {
+	Vertices []PackedVertex
+	Indices  []uint32
+}
+
+// Weld merges vertices whose position, UV and normal components each differ by at most tolerance, keeping the first
+// of them. Each vertex is merged with the first kept vertex it's close enough to, so which vertices merge depends on
+// their order when they form a chain of close vertices. A tolerance of 0 merges only equal vertices.
+//
+// Vertices are found by hashing their positions into a grid of cells as wide as the tolerance, so only the vertices
+// in the neighboring cells are compared, which takes about linear time rather than quadratic. Without a tolerance,
+// whole vertices are hashed.
+func Weld(vertices []PackedVertex, tolerance float32) *Welded {
+	return weld(vertices,
+		func(v PackedVertex) weldKey { return weldCell(v, tolerance) },
+		func(cell weldKey) []weldKey { return weldNeighbors(cell, tolerance) },
+		func(a, b PackedVertex) bool { return closeVertices(a, b, tolerance) })
+}
+
+// weld merges each vertex into the first kept vertex that's close to it. Close vertices must be in neighboring cells.
+func weld(vertices []PackedVertex, cellOf func(PackedVertex) weldKey, neighbors func(weldKey) []weldKey,
+	close func(a, b PackedVertex) bool) *Welded {
+	w := &Welded{Indices: make([]uint32, len(vertices))}
+	grid := make(map[weldKey][]uint32)
+
+	for i, v := range vertices {
+		cell := cellOf(v)
+		index, found := uint32(0), false
+		for _, neighbor := range neighbors(cell) {
+			for _, candidate := range grid[neighbor] {
+				if (!found || candidate < index) && close(v, w.Vertices[candidate]) {
+					index, found = candidate, true
+				}
+			}
+		}
+
+		if !found {
+			index = uint32(len(w.Vertices))
+			w.Vertices = append(w.Vertices, v)
+			grid[cell] = append(grid[cell], index)
+		}
+		w.Indices[i] = index
+	}
+
+	return w
+}
+
+// weldKey is a cell of the grid: the cell of the position with a tolerance, or all the components of the vertex
+// without one.
+type weldKey [8]int64
+
+// weldCell returns the grid cell of a vertex.
+func weldCell(v PackedVertex, tolerance float32) weldKey {
+	var cell weldKey
+	if tolerance == 0 {
+		components := []float32{v.Position[0], v.Position[1], v.Position[2], v.UV[0], v.UV[1], v.Norm[0], v.Norm[1], v.Norm[2]}
+		for i, x := range components {
+			// Add 0 to make -0 equal to 0
+			cell[i] = int64(math.Float32bits(x + 0))
+		}
+		return cell
+	}
+
+	for i, x := range v.Position {
+		cell[i] = int64(math.Floor(float64(x / tolerance)))
+	}
+	return cell
+}
+
+// weldNeighbors returns the cells that may hold vertices within tolerance of those in cell.
+func weldNeighbors(cell weldKey, tolerance float32) []weldKey {
+	if tolerance == 0 {
+		// Equal vertices are in the same cell
+		return []weldKey{cell}
+	}
+	return adjacentCells(cell)
+}
+
+// adjacentCells returns cell and the cells around it.
+func adjacentCells(cell weldKey) []weldKey {
+	neighbors := make([]weldKey, 0, 27)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				neighbors = append(neighbors, weldKey{cell[0] + dx, cell[1] + dy, cell[2] + dz})
+			}
+		}
+	}
+	return neighbors
+}
+
+// similarCellMin and similarCellRatio lay out the cells that similarCell puts numbers in. Numbers smaller than
+// similarCellMin, which are similar to 0 if they're within .01*.01 of it, share a cell. Larger ones are in cells
+// similarCellRatio times as wide as the last, a little more than the ratio of similar numbers, 1.01/.99, so that
+// similar numbers are at most one cell apart despite rounding.
+const (
+	similarCellMin   = 1e-4
+	similarCellRatio = 1.03
+)
+
+// similarCell returns the grid cell of the position of a vertex for IndexVBO: for each component, 0 if it's small,
+// and otherwise a cell number that grows with its logarithm, negated for negative numbers. The cells of similar
+// numbers differ by at most 1, as a number with the smallest magnitude in its cell is in cell 1 or -1.
+func similarCell(v PackedVertex) weldKey {
+	var cell weldKey
+	for i, x := range v.Position {
+		abs := math.Abs(float64(x))
+		switch {
+		case abs < similarCellMin:
+			cell[i] = 0
+		case math.IsInf(abs, 0) || math.IsNaN(abs):
+			// Infinities are only similar to themselves, and NaNs to nothing
+			cell[i] = math.MaxInt32
+		default:
+			cell[i] = int64(math.Log(abs/similarCellMin)/math.Log(similarCellRatio)) + 1
+		}
+		if x < 0 {
+			cell[i] = -cell[i]
+		}
+	}
+	return cell
+}
+
+// similarVertices returns whether two vertices are similar, as IndexVBOSlow compares them.
+func similarVertices(a, b PackedVertex) bool {
+	for i := range a.Position {
+		if !mgl32.FloatEqualThreshold(a.Position[i], b.Position[i], .01) || !mgl32.FloatEqualThreshold(a.Norm[i], b.Norm[i], .01) {
+			return false
+		}
+	}
+	return mgl32.FloatEqualThreshold(a.UV[0], b.UV[0], .01) && mgl32.FloatEqualThreshold(a.UV[1], b.UV[1], .01)
+}
+
+func closeVertices(a, b PackedVertex, tolerance float32) bool {
+	for i := range a.Position {
+		if mgl32.Abs(a.Position[i]-b.Position[i]) > tolerance || mgl32.Abs(a.Norm[i]-b.Norm[i]) > tolerance {
+			return false
+		}
+	}
+	return mgl32.Abs(a.UV[0]-b.UV[0]) <= tolerance && mgl32.Abs(a.UV[1]-b.UV[1]) <= tolerance
+}
+
+// Indices16 returns the indices as uint16s, which take half the memory (GL_UNSIGNED_SHORT), or an error if there are
+// too many vertices to index with them.
+func (w *Welded) Indices16() ([]uint16, error) {
+	if len(w.Vertices) > math.MaxUint16+1 {
+		return nil, fmt.Errorf("%d vertices are too many for 16 bit indices", len(w.Vertices))
+	}
+
+	indices := make([]uint16, len(w.Indices))
+	for i, index := range w.Indices {
+		indices[i] = uint16(index)
+	}
+	return indices, nil
+}
+
+// CompressionRatio is the number of vertices before welding over the number after, so 6 means each vertex was shared
+// by 6 on average. It's 1 if there were no vertices.
+func (w *Welded) CompressionRatio() float64 {
+	if len(w.Vertices) == 0 {
+		return 1
+	}
+	return float64(len(w.Indices)) / float64(len(w.Vertices))
+}
 
 func IndexVBOSlow(vertices []mgl32.Vec3, uvs []mgl32.Vec2, normals []mgl32.Vec3) (outIndices []uint16, outVertices []mgl32.Vec3, outUVs []mgl32.Vec2, outNorms []mgl32.Vec3) {
 
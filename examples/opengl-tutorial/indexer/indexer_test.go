@@ -0,0 +1,159 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package indexer
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestWeldCube(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	cube := mgl32.CubeMesh(2)
+
+	// Unindex the cube, as an OBJ loader would, with a little noise
+	var vertices []PackedVertex
+	for _, i := range cube.Indices {
+		jitter := mgl32.Vec3{rand.Float32(), rand.Float32(), rand.Float32()}.Mul(.004)
+		vertices = append(vertices, PackedVertex{cube.Positions[i].Add(jitter), cube.UVs[i], cube.Normals[i]})
+	}
+
+	welded := Weld(vertices, .005)
+	if len(welded.Vertices) != len(cube.Positions) || welded.CompressionRatio() != 1.5 {
+		t.Errorf("Welding the cube's %d vertices gives %d vertices, ratio %v, expected %d and 1.5",
+			len(vertices), len(welded.Vertices), welded.CompressionRatio(), len(cube.Positions))
+	}
+	for i, index := range welded.Indices {
+		if !closeVertices(welded.Vertices[index], vertices[i], .005) {
+			t.Errorf("Vertex %d is %v, but welded to %v", i, vertices[i], welded.Vertices[index])
+		}
+	}
+
+	indices, vs, uvs, norms := IndexVBO(vertexAttributes(vertices))
+	if len(indices) != len(vertices) || len(vs) != len(cube.Positions) || len(uvs) != len(vs) || len(norms) != len(vs) {
+		t.Errorf("IndexVBO gives %d indices and %d vertices", len(indices), len(vs))
+	}
+	if _, slowVertices, _, _ := IndexVBOSlow(vertexAttributes(vertices)); len(slowVertices) != len(vs) {
+		t.Errorf("IndexVBO gives %d vertices, but IndexVBOSlow %d", len(vs), len(slowVertices))
+	}
+
+	// Without a tolerance only equal vertices merge, with 0 equal to -0
+	if welded := Weld(vertices, 0); len(welded.Vertices) != len(vertices) {
+		t.Errorf("Welding noisy vertices without a tolerance gives %d of %d", len(welded.Vertices), len(vertices))
+	}
+	exact := []PackedVertex{{Position: mgl32.Vec3{0, 1, 2}}, {Position: mgl32.Vec3{float32(math.Copysign(0, -1)), 1, 2}}, {Norm: mgl32.Vec3{1, 0, 0}}}
+	if welded := Weld(exact, 0); !reflect.DeepEqual(welded.Indices, []uint32{0, 0, 1}) {
+		t.Errorf("Welding without a tolerance gives indices %v", welded.Indices)
+	}
+}
+
+func vertexAttributes(vertices []PackedVertex) (positions []mgl32.Vec3, uvs []mgl32.Vec2, normals []mgl32.Vec3) {
+	for _, v := range vertices {
+		positions, uvs, normals = append(positions, v.Position), append(uvs, v.UV), append(normals, v.Norm)
+	}
+	return
+}
+
+func TestWeldMatchesBruteForce(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	const tolerance = .1
+
+	// Points on a coarse grid, with noise about as large as the tolerance, so some nearby points weld and some don't
+	vertices := make([]PackedVertex, 2000)
+	for i := range vertices {
+		vertices[i].Position = mgl32.Vec3{float32(rand.Intn(5)), float32(rand.Intn(5)), float32(rand.Intn(5))}
+		for j := range vertices[i].Position {
+			vertices[i].Position[j] += rand.Float32() * tolerance * 2
+		}
+		vertices[i].UV = mgl32.Vec2{float32(rand.Intn(2)), 0}
+	}
+
+	// The first kept vertex within the tolerance, by comparing with every one
+	var expected []uint32
+	var kept []PackedVertex
+	for _, v := range vertices {
+		index := -1
+		for j, k := range kept {
+			if closeVertices(v, k, tolerance) {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			index = len(kept)
+			kept = append(kept, v)
+		}
+		expected = append(expected, uint32(index))
+	}
+
+	welded := Weld(vertices, tolerance)
+	if !reflect.DeepEqual(welded.Indices, expected) || !reflect.DeepEqual(welded.Vertices, kept) {
+		t.Errorf("Welding gives %d vertices, expected %d", len(welded.Vertices), len(kept))
+	}
+}
+
+func TestIndexVBOMatchesSlow(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// Components from 1e-6 to 1e4 of either sign, and some zeros, where relative and absolute tolerances disagree
+	component := func() float32 {
+		if rand.Intn(8) == 0 {
+			return 0
+		}
+		x := float32(math.Pow(10, rand.Float64()*10-6))
+		if rand.Intn(2) == 0 {
+			x = -x
+		}
+		return x
+	}
+	base := make([]PackedVertex, 200)
+	for i := range base {
+		base[i] = PackedVertex{
+			mgl32.Vec3{component(), component(), component()},
+			mgl32.Vec2{component(), component()},
+			mgl32.Vec3{component(), component(), component()},
+		}
+	}
+
+	// Copies of the base vertices with positions scaled by up to 3%, so that some are similar and some aren't
+	vertices := make([]PackedVertex, 3000)
+	for i := range vertices {
+		v := base[rand.Intn(len(base))]
+		for j := range v.Position {
+			v.Position[j] *= 1 + (rand.Float32()*2-1)*.03
+		}
+		vertices[i] = v
+	}
+
+	indices, vs, uvs, norms := IndexVBO(vertexAttributes(vertices))
+	slowIndices, slowVertices, slowUVs, slowNorms := IndexVBOSlow(vertexAttributes(vertices))
+	if !reflect.DeepEqual(indices, slowIndices) || !reflect.DeepEqual(vs, slowVertices) ||
+		!reflect.DeepEqual(uvs, slowUVs) || !reflect.DeepEqual(norms, slowNorms) {
+		t.Errorf("IndexVBO gives %d vertices, but IndexVBOSlow %d", len(vs), len(slowVertices))
+	}
+	if len(vs) <= len(base) || len(vs) >= len(vertices) {
+		t.Errorf("IndexVBO merges %d vertices into %d, expected some but not all to be similar", len(vertices), len(vs))
+	}
+}
+
+func TestIndices16(t *testing.T) {
+	vertices := make([]PackedVertex, math.MaxUint16+2)
+	for i := range vertices {
+		vertices[i].UV[0] = float32(i)
+	}
+
+	welded := Weld(vertices[:math.MaxUint16+1], 0)
+	if indices, err := welded.Indices16(); err != nil || indices[math.MaxUint16] != math.MaxUint16 {
+		t.Errorf("16 bit indices of %d vertices fail: %v", len(welded.Vertices), err)
+	}
+	if _, err := Weld(vertices, 0).Indices16(); err == nil {
+		t.Errorf("16 bit indices of %d vertices succeed", len(vertices))
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package objloader
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTutorialObjects(t *testing.T) {
+	// Some name material libraries that aren't in the repository, which LoadObject doesn't need
+	paths, err := filepath.Glob("../tutorial*/*.obj")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("Finding the tutorials' objects gives %v and %v", paths, err)
+	}
+
+	for _, path := range paths {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("Loading %s panics: %v", path, r)
+				}
+			}()
+
+			mesh := LoadObject(path, true)
+			if n := len(mesh.Vertices); n == 0 || n%3 != 0 || len(mesh.UVs) != n || len(mesh.Normals) != n {
+				t.Errorf("%s has %d vertices, %d UVs and %d normals", path, n, len(mesh.UVs), len(mesh.Normals))
+			}
+		}()
+	}
+}
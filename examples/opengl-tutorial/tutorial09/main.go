@@ -76,7 +76,7 @@ func main() {
 
 	meshObj := objloader.LoadObject("suzanne.obj", true)
 
-	indices, indexedVertices, indexedUVs, indexedNormals := indexer.IndexVBOSlow(meshObj.Vertices, meshObj.UVs, meshObj.Normals)
+	indices, indexedVertices, indexedUVs, indexedNormals := indexer.IndexVBO(meshObj.Vertices, meshObj.UVs, meshObj.Normals)
 
 	vertexBuffer := gl.GenBuffer()
 	defer vertexBuffer.Delete()
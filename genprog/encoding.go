@@ -0,0 +1,86 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// encodingType is a vector or matrix type to generate encoding methods for.
+type encodingType struct {
+	Name     string
+	Receiver string
+	Format   bool // Whether to generate Format, which Mat4 has by hand so it can print as a table
+}
+
+// encodingTemplate holds the methods of each type, which encode its elements with the helpers in encodingStatic.go.
+var encodingTemplate = template.Must(template.New("encoding").Parse(`// Copyright 2014 The go-gl/mathgl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file is generated by genprog; change genprog/encoding.go instead.
+
+package mgl32
+
+import (
+	"encoding/json"
+	"fmt"
+)
+{{range .}}
+// MarshalText implements encoding.TextMarshaler.
+func ({{.Receiver}} {{.Name}}) MarshalText() ([]byte, error) {
+	return marshalText({{.Receiver}}[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func ({{.Receiver}} *{{.Name}}) UnmarshalText(text []byte) error {
+	return unmarshalText(text, {{.Receiver}}[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func ({{.Receiver}} {{.Name}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal({{.Receiver}}[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func ({{.Receiver}} *{{.Name}}) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, {{.Receiver}}[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func ({{.Receiver}} {{.Name}}) MarshalBinary() ([]byte, error) {
+	return marshalBinary({{.Receiver}}[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func ({{.Receiver}} *{{.Name}}) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, {{.Receiver}}[:])
+}
+{{if .Format}}
+// Format implements fmt.Formatter.
+func ({{.Receiver}} {{.Name}}) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, {{.Receiver}}, {{.Receiver}}[:])
+}
+{{end}}{{end}}`))
+
+func GenEncoding() string {
+	var types []encodingType
+	for m := 2; m <= 4; m++ {
+		types = append(types, encodingType{Name: GenMatName(1, m), Receiver: "v", Format: true})
+	}
+	for m := 2; m <= 4; m++ {
+		for n := 2; n <= 4; n++ {
+			name := GenMatName(m, n)
+			types = append(types, encodingType{Name: name, Receiver: "m", Format: name != "Mat4"})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := encodingTemplate.Execute(&buf, types); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
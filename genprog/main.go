@@ -38,6 +38,17 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+
+	encodingf, err := os.Create("../mgl32/encoding.go")
+	if err != nil {
+		panic(err)
+	}
+	defer encodingf.Close()
+
+	_, err = encodingf.Write([]byte(GenEncoding()))
+	if err != nil {
+		panic(err)
+	}
 	//fmt.Println(mats)
 	//fmt.Println("Done")
 }
@@ -695,7 +706,7 @@ func GenDet(m int) string {
 
 	switch m {
 	case 2:
-		s += "m[0] * m[2] - m[1] * m[3]"
+		s += "m[0] * m[3] - m[1] * m[2]"
 	case 3:
 		s += "m[0]*m[4]*m[8] + m[3] * m[7] * m[2] + m[6] * m[1] * m[5] - m[6] * m[4] * m[2] - m[3] * m[1] * m[8] - m[0] * m[7] * m[5]"
 	case 4:
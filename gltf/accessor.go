@@ -0,0 +1,313 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// accessor is a typed view of the data in a buffer view, with sparse substitutions.
+type accessor struct {
+	BufferView    *int   `json:"bufferView"`
+	ByteOffset    int    `json:"byteOffset"`
+	ComponentType int    `json:"componentType"`
+	Normalized    bool   `json:"normalized"`
+	Count         int    `json:"count"`
+	Type          string `json:"type"`
+	Sparse        *struct {
+		Count   int `json:"count"`
+		Indices struct {
+			BufferView    int `json:"bufferView"`
+			ByteOffset    int `json:"byteOffset"`
+			ComponentType int `json:"componentType"`
+		} `json:"indices"`
+		Values struct {
+			BufferView int `json:"bufferView"`
+			ByteOffset int `json:"byteOffset"`
+		} `json:"values"`
+	} `json:"sparse"`
+}
+
+// Component types
+const (
+	componentByte          = 5120
+	componentUnsignedByte  = 5121
+	componentShort         = 5122
+	componentUnsignedShort = 5123
+	componentUnsignedInt   = 5125
+	componentFloat         = 5126
+)
+
+// maxByteStride is the largest stride between the elements of a buffer view.
+const maxByteStride = 252
+
+var componentSizes = map[int]int{
+	componentByte: 1, componentUnsignedByte: 1, componentShort: 2, componentUnsignedShort: 2,
+	componentUnsignedInt: 4, componentFloat: 4,
+}
+
+// elementShapes holds the columns and rows of each element type.
+var elementShapes = map[string][2]int{
+	"SCALAR": {1, 1}, "VEC2": {1, 2}, "VEC3": {1, 3}, "VEC4": {1, 4}, "MAT2": {2, 2}, "MAT3": {3, 3}, "MAT4": {4, 4},
+}
+
+// elementLayout is where the components of an element are.
+type elementLayout struct {
+	componentType, componentSize int
+	columns, rows, columnStride  int
+}
+
+// size returns the number of bytes of an element.
+func (l elementLayout) size() int {
+	return l.columns * l.columnStride
+}
+
+// read reads count elements from data, stride bytes apart, into dst.
+func (l elementLayout) read(data []byte, stride, count int, dst []float64) {
+	components := l.columns * l.rows
+	for e := 0; e < count; e++ {
+		for c := 0; c < l.columns; c++ {
+			for r := 0; r < l.rows; r++ {
+				dst[e*components+c*l.rows+r] = readComponent(data[e*stride+c*l.columnStride+r*l.componentSize:], l.componentType)
+			}
+		}
+	}
+}
+
+// readComponent reads a component, exactly.
+func readComponent(b []byte, componentType int) float64 {
+	switch componentType {
+	case componentByte:
+		return float64(int8(b[0]))
+	case componentUnsignedByte:
+		return float64(b[0])
+	case componentShort:
+		return float64(int16(binary.LittleEndian.Uint16(b)))
+	case componentUnsignedShort:
+		return float64(binary.LittleEndian.Uint16(b))
+	case componentUnsignedInt:
+		return float64(binary.LittleEndian.Uint32(b))
+	}
+	return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+}
+
+// view returns the data of buffer view i from offset on, after checking it holds count elements of the given size,
+// and the stride between them.
+func (d *decoder) view(i, offset, count, size int) (data []byte, stride int, err error) {
+	if i < 0 || i >= len(d.doc.BufferViews) {
+		return nil, 0, fmt.Errorf("buffer view %d is out of range", i)
+	}
+	v := d.doc.BufferViews[i]
+	if v.Buffer < 0 || v.Buffer >= len(d.buffers) || v.ByteOffset < 0 || v.ByteLength < 0 ||
+		v.ByteOffset > len(d.buffers[v.Buffer]) || v.ByteLength > len(d.buffers[v.Buffer])-v.ByteOffset {
+		return nil, 0, fmt.Errorf("buffer view %d is outside its buffer", i)
+	}
+
+	stride = size
+	if v.ByteStride < 0 || v.ByteStride > maxByteStride {
+		return nil, 0, fmt.Errorf("buffer view %d has stride %d", i, v.ByteStride)
+	} else if v.ByteStride != 0 {
+		stride = v.ByteStride
+	}
+	// Each element takes at least a byte, so checking count first keeps the end's offset from overflowing
+	if offset < 0 || count < 0 || offset > v.ByteLength ||
+		count > 0 && (count > v.ByteLength || offset+(count-1)*stride+size > v.ByteLength) {
+		return nil, 0, fmt.Errorf("%d elements of %d bytes, %d apart from byte %d, run past the end of buffer view %d",
+			count, size, stride, offset, i)
+	}
+
+	return d.buffers[v.Buffer][v.ByteOffset+offset : v.ByteOffset+v.ByteLength], stride, nil
+}
+
+// bufferBytes returns the total size of the buffers.
+func (d *decoder) bufferBytes() int {
+	total := 0
+	for _, b := range d.buffers {
+		total += len(b)
+	}
+	return total
+}
+
+// elements decodes the components of accessor i, exactly, and returns the number of components of each element.
+func (d *decoder) elements(i int) (values []float64, size int, a *accessor, err error) {
+	if i < 0 || i >= len(d.doc.Accessors) {
+		return nil, 0, nil, fmt.Errorf("accessor %d is out of range", i)
+	}
+	a = &d.doc.Accessors[i]
+
+	componentSize, ok := componentSizes[a.ComponentType]
+	shape, ok2 := elementShapes[a.Type]
+	if !ok || !ok2 || a.Count < 0 {
+		return nil, 0, nil, fmt.Errorf("accessor %d has component type %d, type %q and count %d", i, a.ComponentType, a.Type, a.Count)
+	}
+	l := elementLayout{componentType: a.ComponentType, componentSize: componentSize, columns: shape[0], rows: shape[1]}
+	l.columnStride = l.rows * componentSize
+	if l.columns > 1 {
+		// Matrix columns start on 4 byte boundaries
+		l.columnStride = (l.columnStride + 3) &^ 3
+	}
+
+	// The data is checked to hold the elements before they're allocated
+	size = l.columns * l.rows
+	if a.BufferView != nil {
+		data, stride, err := d.view(*a.BufferView, a.ByteOffset, a.Count, l.size())
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("accessor %d: %v", i, err)
+		}
+		values = make([]float64, a.Count*size)
+		l.read(data, stride, a.Count, values)
+	} else if total := d.bufferBytes(); a.Count > total/size {
+		// Without a buffer view the elements are zeros, except the sparse ones, so nothing else limits the count. Real
+		// files have no more components than buffer bytes, as other accessors with the same count hold data.
+		return nil, 0, nil, fmt.Errorf("accessor %d has %d elements of %d components and no buffer view, more than the %d bytes of buffers",
+			i, a.Count, size, total)
+	} else {
+		values = make([]float64, a.Count*size)
+	}
+
+	if s := a.Sparse; s != nil {
+		if s.Count < 0 || s.Count > a.Count {
+			return nil, 0, nil, fmt.Errorf("accessor %d: %d sparse elements of %d", i, s.Count, a.Count)
+		}
+		indexLayout := elementLayout{componentType: s.Indices.ComponentType, columns: 1, rows: 1}
+		indexLayout.componentSize = componentSizes[indexLayout.componentType]
+		indexLayout.columnStride = indexLayout.componentSize
+		if t := indexLayout.componentType; t != componentUnsignedByte && t != componentUnsignedShort && t != componentUnsignedInt {
+			return nil, 0, nil, fmt.Errorf("accessor %d: sparse indices have component type %d", i, t)
+		}
+
+		indexData, _, err := d.view(s.Indices.BufferView, s.Indices.ByteOffset, s.Count, indexLayout.size())
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("accessor %d: sparse indices: %v", i, err)
+		}
+		valueData, _, err := d.view(s.Values.BufferView, s.Values.ByteOffset, s.Count, l.size())
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("accessor %d: sparse values: %v", i, err)
+		}
+
+		// Sparse data is tightly packed, whatever the buffer views' strides
+		indices, sparseValues := make([]float64, s.Count), make([]float64, s.Count*size)
+		indexLayout.read(indexData, indexLayout.size(), s.Count, indices)
+		l.read(valueData, l.size(), s.Count, sparseValues)
+		for j, index := range indices {
+			if int(index) >= a.Count {
+				return nil, 0, nil, fmt.Errorf("accessor %d: sparse index %v is out of range", i, index)
+			}
+			copy(values[int(index)*size:(int(index)+1)*size], sparseValues[j*size:(j+1)*size])
+		}
+	}
+
+	return values, size, a, nil
+}
+
+// floats decodes accessor i into floats, scaling normalized integers to [0,1] or [-1,1], and returns the number of
+// components of each element.
+func (d *decoder) floats(i int) (values []float32, size int, err error) {
+	raw, size, a, err := d.elements(i)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scale, min := 1., math.Inf(-1)
+	if a.Normalized {
+		switch a.ComponentType {
+		case componentByte:
+			scale, min = 127, -1
+		case componentUnsignedByte:
+			scale = 255
+		case componentShort:
+			scale, min = 32767, -1
+		case componentUnsignedShort:
+			scale = 65535
+		case componentUnsignedInt:
+			scale = math.MaxUint32
+		}
+	}
+
+	values = make([]float32, len(raw))
+	for j, v := range raw {
+		values[j] = float32(math.Max(v/scale, min))
+	}
+	return values, size, nil
+}
+
+// uints decodes accessor i, which must have an unsigned integer component type, and returns the number of components
+// of each element.
+func (d *decoder) uints(i int) (values []uint32, size int, err error) {
+	raw, size, a, err := d.elements(i)
+	if err != nil {
+		return nil, 0, err
+	}
+	if t := a.ComponentType; t != componentUnsignedByte && t != componentUnsignedShort && t != componentUnsignedInt {
+		return nil, 0, fmt.Errorf("accessor %d has component type %d, expected an unsigned integer", i, t)
+	}
+
+	values = make([]uint32, len(raw))
+	for j, v := range raw {
+		values[j] = uint32(v)
+	}
+	return values, size, nil
+}
+
+// vectors decodes accessor i, which must have n components per element.
+func (d *decoder) vectors(i, n int) ([]float32, error) {
+	values, size, err := d.floats(i)
+	if err == nil && size != n {
+		err = fmt.Errorf("accessor %d has %d components, expected %d", i, size, n)
+	}
+	return values, err
+}
+
+func (d *decoder) vec2s(i int) ([]mgl32.Vec2, error) {
+	values, err := d.vectors(i, 2)
+	if err != nil {
+		return nil, err
+	}
+	v := make([]mgl32.Vec2, len(values)/2)
+	for j := range v {
+		copy(v[j][:], values[2*j:])
+	}
+	return v, nil
+}
+
+func (d *decoder) vec3s(i int) ([]mgl32.Vec3, error) {
+	values, err := d.vectors(i, 3)
+	if err != nil {
+		return nil, err
+	}
+	v := make([]mgl32.Vec3, len(values)/3)
+	for j := range v {
+		copy(v[j][:], values[3*j:])
+	}
+	return v, nil
+}
+
+func (d *decoder) vec4s(i int) ([]mgl32.Vec4, error) {
+	values, err := d.vectors(i, 4)
+	if err != nil {
+		return nil, err
+	}
+	v := make([]mgl32.Vec4, len(values)/4)
+	for j := range v {
+		copy(v[j][:], values[4*j:])
+	}
+	return v, nil
+}
+
+func (d *decoder) mat4s(i int) ([]mgl32.Mat4, error) {
+	values, err := d.vectors(i, 16)
+	if err != nil {
+		return nil, err
+	}
+	m := make([]mgl32.Mat4, len(values)/16)
+	for j := range m {
+		copy(m[j][:], values[16*j:])
+	}
+	return m, nil
+}
@@ -0,0 +1,266 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Interpolation is how a sampler interpolates between keyframes.
+type Interpolation int
+
+const (
+	// Linear interpolates linearly, or with QuatSlerp for rotations.
+	Linear Interpolation = iota
+	// Step holds each keyframe's value until the next keyframe.
+	Step
+	// CubicSpline interpolates with a cubic Hermite spline, with tangents given at each keyframe.
+	CubicSpline
+)
+
+var interpolations = map[string]Interpolation{"LINEAR": Linear, "STEP": Step, "CUBICSPLINE": CubicSpline}
+
+func (i Interpolation) String() string {
+	for name, value := range interpolations {
+		if value == i {
+			return name
+		}
+	}
+	return fmt.Sprintf("Interpolation(%d)", int(i))
+}
+
+// Path is the property of a node an animation channel changes.
+type Path int
+
+const (
+	Translation Path = iota
+	Rotation
+	Scale
+	Weights // Morph target weights, which Apply leaves alone
+)
+
+var paths = map[string]Path{"translation": Translation, "rotation": Rotation, "scale": Scale, "weights": Weights}
+
+func (p Path) String() string {
+	for name, value := range paths {
+		if value == p {
+			return name
+		}
+	}
+	return fmt.Sprintf("Path(%d)", int(p))
+}
+
+// Animation is a set of channels that animate nodes together.
+type Animation struct {
+	Name     string
+	Channels []Channel
+	Samplers []Sampler
+}
+
+// Channel animates a property of a node with a sampler.
+type Channel struct {
+	Node    int // The node, or -1 if the file doesn't say
+	Path    Path
+	Sampler int // An index into the animation's samplers
+}
+
+// Sampler holds keyframes, at increasing times in seconds, and interpolates between them. Before the first keyframe
+// and after the last, it holds their values.
+type Sampler struct {
+	Interpolation Interpolation
+	Times         []float32
+
+	// Values holds Size numbers for each keyframe. With CubicSpline interpolation, each keyframe has an in-tangent, a
+	// value and an out-tangent, in that order, so it has 3*Size numbers.
+	Values []float32
+	Size   int
+}
+
+// animation decodes animation i, whose channels target nodes out of numNodes.
+func (d *decoder) animation(i, numNodes int) (Animation, error) {
+	a := d.doc.Animations[i]
+	animation := Animation{Name: a.Name}
+
+	for j, s := range a.Samplers {
+		sampler, err := d.sampler(s.Input, s.Output, s.Interpolation)
+		if err != nil {
+			return Animation{}, fmt.Errorf("sampler %d: %v", j, err)
+		}
+		animation.Samplers = append(animation.Samplers, sampler)
+	}
+
+	for j, c := range a.Channels {
+		path, ok := paths[c.Target.Path]
+		channel := Channel{Node: optional(c.Target.Node), Path: path, Sampler: c.Sampler}
+		switch {
+		case !ok:
+			return Animation{}, fmt.Errorf("channel %d: path %q isn't supported", j, c.Target.Path)
+		case channel.Node >= numNodes:
+			return Animation{}, fmt.Errorf("channel %d: node %d is out of range", j, channel.Node)
+		case c.Sampler < 0 || c.Sampler >= len(animation.Samplers):
+			return Animation{}, fmt.Errorf("channel %d: sampler %d is out of range", j, c.Sampler)
+		}
+
+		size := animation.Samplers[c.Sampler].Size
+		if expected := map[Path]int{Translation: 3, Rotation: 4, Scale: 3}[path]; expected != 0 && size != expected {
+			return Animation{}, fmt.Errorf("channel %d: %s has %d components, expected %d", j, path, size, expected)
+		}
+		animation.Channels = append(animation.Channels, channel)
+	}
+
+	return animation, nil
+}
+
+// sampler decodes a sampler from its input and output accessors.
+func (d *decoder) sampler(input, output int, interpolation string) (Sampler, error) {
+	s := Sampler{Interpolation: Linear}
+	if interpolation != "" {
+		var ok bool
+		if s.Interpolation, ok = interpolations[interpolation]; !ok {
+			return Sampler{}, fmt.Errorf("interpolation %q isn't supported", interpolation)
+		}
+	}
+
+	var err error
+	if s.Times, err = d.vectors(input, 1); err != nil {
+		return Sampler{}, err
+	}
+	if len(s.Times) == 0 {
+		return Sampler{}, fmt.Errorf("accessor %d has no keyframes", input)
+	}
+	for k := 1; k < len(s.Times); k++ {
+		if s.Times[k] <= s.Times[k-1] {
+			return Sampler{}, fmt.Errorf("keyframe times %v and %v don't increase", s.Times[k-1], s.Times[k])
+		}
+	}
+
+	values, size, err := d.floats(output)
+	if err != nil {
+		return Sampler{}, err
+	}
+	perKeyframe := 1
+	if s.Interpolation == CubicSpline {
+		perKeyframe = 3
+	}
+
+	// Morph target weights are scalars, as many for each keyframe as the mesh has targets
+	elements := len(values) / size
+	if elements%(perKeyframe*len(s.Times)) != 0 {
+		return Sampler{}, fmt.Errorf("%d outputs for %d %s keyframes", elements, len(s.Times), s.Interpolation)
+	}
+	s.Values, s.Size = values, len(values)/(perKeyframe*len(s.Times))
+
+	return s, nil
+}
+
+// key returns the keyframe at or before time t, and how far t is from it to the next keyframe, from 0 to 1.
+func (s *Sampler) key(t float32) (k int, frac float32) {
+	n := len(s.Times)
+	if t <= s.Times[0] {
+		return 0, 0
+	}
+	if t >= s.Times[n-1] {
+		return n - 1, 0
+	}
+
+	k = sort.Search(n, func(i int) bool { return s.Times[i] > t }) - 1
+	return k, (t - s.Times[k]) / (s.Times[k+1] - s.Times[k])
+}
+
+// keyValue returns the value of keyframe k.
+func (s *Sampler) keyValue(k int) []float32 {
+	if s.Interpolation == CubicSpline {
+		return s.Values[(3*k+1)*s.Size : (3*k+2)*s.Size]
+	}
+	return s.Values[k*s.Size : (k+1)*s.Size]
+}
+
+// Sample sets value, which must have Size elements, to the value at time t, interpolating each number separately.
+func (s *Sampler) Sample(t float32, value []float32) {
+	k, frac := s.key(t)
+	if frac == 0 || s.Interpolation == Step {
+		copy(value, s.keyValue(k))
+		return
+	}
+
+	v0, v1 := s.keyValue(k), s.keyValue(k+1)
+	if s.Interpolation == Linear {
+		for i := range value {
+			value[i] = v0[i] + (v1[i]-v0[i])*frac
+		}
+		return
+	}
+
+	// The Hermite basis functions, with tangents scaled from per second to per keyframe
+	dt := s.Times[k+1] - s.Times[k]
+	out0 := s.Values[(3*k+2)*s.Size : (3*k+3)*s.Size]
+	in1 := s.Values[(3*k+3)*s.Size : (3*k+4)*s.Size]
+	f2, f3 := frac*frac, frac*frac*frac
+	h00, h10, h01, h11 := 2*f3-3*f2+1, (f3-2*f2+frac)*dt, -2*f3+3*f2, (f3-f2)*dt
+	for i := range value {
+		value[i] = h00*v0[i] + h10*out0[i] + h01*v1[i] + h11*in1[i]
+	}
+}
+
+// Vec3 returns the value at time t of a sampler of translations or scales.
+func (s *Sampler) Vec3(t float32) mgl32.Vec3 {
+	var v mgl32.Vec3
+	s.Sample(t, v[:])
+	return v
+}
+
+// Quat returns the value at time t of a sampler of rotations. Linear interpolation takes the shortest path between
+// keyframes, at a constant speed.
+func (s *Sampler) Quat(t float32) mgl32.Quat {
+	if k, frac := s.key(t); s.Interpolation == Linear && frac != 0 {
+		q0, q1 := quat(s.keyValue(k)), quat(s.keyValue(k+1))
+		if q0.Dot(q1) < 0 {
+			q1 = q1.Scale(-1)
+		}
+		return mgl32.QuatSlerp(q0, q1, frac)
+	}
+
+	var v [4]float32
+	s.Sample(t, v[:])
+	return quat(v[:]).Normalize()
+}
+
+// quat returns the quaternion of glTF's X, Y, Z, W order.
+func quat(v []float32) mgl32.Quat {
+	return mgl32.Quat{W: v[3], V: mgl32.Vec3{v[0], v[1], v[2]}}
+}
+
+// Duration returns the time of the animation's last keyframe.
+func (a *Animation) Duration() float32 {
+	var duration float32
+	for _, s := range a.Samplers {
+		if end := s.Times[len(s.Times)-1]; end > duration {
+			duration = end
+		}
+	}
+	return duration
+}
+
+// Apply sets the translations, rotations and scales of the nodes the animation targets to their values at time t.
+func (a *Animation) Apply(t float32, nodes []Node) {
+	for _, c := range a.Channels {
+		if c.Node < 0 {
+			continue
+		}
+
+		s, n := &a.Samplers[c.Sampler], &nodes[c.Node]
+		switch c.Path {
+		case Translation:
+			n.Translation = s.Vec3(t)
+		case Rotation:
+			n.Rotation = s.Quat(t)
+		case Scale:
+			n.Scale = s.Vec3(t)
+		}
+	}
+}
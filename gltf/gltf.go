@@ -0,0 +1,625 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gltf reads glTF 2.0 models, as JSON .gltf files or binary .glb files, into mgl32 meshes, node transforms,
+// skins and animations.
+//
+// Accessors of any component type are decoded, with normalized integers scaled as the specification says, and sparse
+// accessors are applied. Triangle lists, strips and fans are read into triangle lists; points and lines aren't
+// supported. Materials, textures, cameras and morph targets are left to other code: primitives keep only their
+// material's index.
+package gltf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Model is a model read from a glTF file. Nodes, meshes, skins and animations refer to each other by index, as in the
+// file, with -1 for none.
+type Model struct {
+	Scenes []Scene
+	Scene  int // The scene to show, or -1 if the file doesn't say
+
+	Nodes      []Node
+	Meshes     []Mesh
+	Skins      []Skin
+	Animations []Animation
+}
+
+// Scene is a set of root nodes to draw.
+type Scene struct {
+	Name  string
+	Nodes []int
+}
+
+// Node is a node of the scene hierarchy. Its transform is relative to its parent. Nodes given as a matrix are split
+// into translation, rotation and scale, which is exact for the matrices glTF allows.
+type Node struct {
+	Name     string
+	Parent   int
+	Children []int
+	Mesh     int
+	Skin     int
+
+	Translation mgl32.Vec3
+	Rotation    mgl32.Quat
+	Scale       mgl32.Vec3
+}
+
+// Mesh is a glTF mesh, which is drawn in one or more parts with different materials.
+type Mesh struct {
+	Name       string
+	Primitives []Primitive
+}
+
+// Primitive is a part of a mesh.
+type Primitive struct {
+	// Mesh holds the POSITION, NORMAL, TANGENT and TEXCOORD_0 attributes, and the indices, which count up from 0 if the
+	// file has none. Attributes the file doesn't have are nil.
+	//
+	// UVs are flipped from glTF's convention, with (0,0) in the top left corner of the texture, to mgl32's, with (0,0)
+	// in the bottom left. glTF's bitangents point up in the texture, so the tangents need no change.
+	Mesh *mgl32.Mesh
+
+	Colors  []mgl32.Vec4 // COLOR_0, with alpha 1 for RGB colors
+	Joints  [][4]uint16  // JOINTS_0, indices into the joints of the node's skin
+	Weights []mgl32.Vec4 // WEIGHTS_0
+
+	Material int
+}
+
+// Skin is a set of joints that deform the meshes of the nodes that use it.
+type Skin struct {
+	Name     string
+	Joints   []int // Node indices
+	Skeleton int   // The common root of the joints, or -1 if the file doesn't say
+
+	// InverseBindMatrices transform from model space to the space of each joint in its bind pose. They're identities if
+	// the file has none.
+	InverseBindMatrices []mgl32.Mat4
+}
+
+// document is the JSON part of a glTF file, as far as the package reads it.
+type document struct {
+	Asset struct {
+		Version    string `json:"version"`
+		MinVersion string `json:"minVersion"`
+	} `json:"asset"`
+	ExtensionsRequired []string `json:"extensionsRequired"`
+
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Name  string `json:"name"`
+		Nodes []int  `json:"nodes"`
+	} `json:"scenes"`
+
+	Nodes []struct {
+		Name        string       `json:"name"`
+		Children    []int        `json:"children"`
+		Mesh        *int         `json:"mesh"`
+		Skin        *int         `json:"skin"`
+		Matrix      *[16]float32 `json:"matrix"`
+		Translation *[3]float32  `json:"translation"`
+		Rotation    *[4]float32  `json:"rotation"`
+		Scale       *[3]float32  `json:"scale"`
+	} `json:"nodes"`
+
+	Meshes []struct {
+		Name       string `json:"name"`
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+
+	Skins []struct {
+		Name                string `json:"name"`
+		InverseBindMatrices *int   `json:"inverseBindMatrices"`
+		Skeleton            *int   `json:"skeleton"`
+		Joints              []int  `json:"joints"`
+	} `json:"skins"`
+
+	Animations []struct {
+		Name     string `json:"name"`
+		Channels []struct {
+			Sampler int `json:"sampler"`
+			Target  struct {
+				Node *int   `json:"node"`
+				Path string `json:"path"`
+			} `json:"target"`
+		} `json:"channels"`
+		Samplers []struct {
+			Input         int    `json:"input"`
+			Interpolation string `json:"interpolation"`
+			Output        int    `json:"output"`
+		} `json:"samplers"`
+	} `json:"animations"`
+
+	Accessors   []accessor `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	} `json:"buffers"`
+}
+
+// supportedExtensions are the extensions a file may require. KHR_mesh_quantization only allows more component types,
+// which are read like any others.
+var supportedExtensions = map[string]bool{"KHR_mesh_quantization": true}
+
+// GLB header and chunk types
+const (
+	glbMagic     = 0x46546c67 // "glTF"
+	glbChunkJSON = 0x4e4f534a // "JSON"
+	glbChunkBIN  = 0x004e4942 // "BIN\x00"
+)
+
+// Primitive modes
+const (
+	modeTriangles     = 4
+	modeTriangleStrip = 5
+	modeTriangleFan   = 6
+)
+
+// Load reads a .gltf or .glb file, and the buffers it refers to, relative to it. Errors name the file.
+func Load(path string) (*Model, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := decode(data, func(uri string) ([]byte, error) {
+		name, err := url.PathUnescape(uri)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadFile(filepath.Join(filepath.Dir(path), filepath.FromSlash(name)))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return model, nil
+}
+
+// Read reads a .gltf or .glb file whose buffers are all embedded, in data URIs or the GLB binary chunk.
+func Read(r io.Reader) (*Model, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(data, func(uri string) ([]byte, error) {
+		return nil, fmt.Errorf("buffer %q is a separate file, which needs Load", uri)
+	})
+}
+
+// decode decodes a .gltf or .glb file, calling readFile for buffers that aren't embedded.
+func decode(data []byte, readFile func(uri string) ([]byte, error)) (*Model, error) {
+	jsonData, bin := data, []byte(nil)
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == glbMagic {
+		var err error
+		if jsonData, bin, err = splitGLB(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var doc document
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Asset.Version, "2.") || doc.Asset.MinVersion != "" && doc.Asset.MinVersion != "2.0" {
+		return nil, fmt.Errorf("glTF version %s isn't supported", doc.Asset.Version)
+	}
+	for _, extension := range doc.ExtensionsRequired {
+		if !supportedExtensions[extension] {
+			return nil, fmt.Errorf("extension %s isn't supported", extension)
+		}
+	}
+
+	d := &decoder{doc: &doc}
+	for i, b := range doc.Buffers {
+		var data []byte
+		var err error
+		switch {
+		case b.URI == "" && i == 0 && bin != nil:
+			data = bin
+		case b.URI == "":
+			err = errors.New("has no data")
+		case strings.HasPrefix(b.URI, "data:"):
+			data, err = decodeDataURI(b.URI)
+		default:
+			data, err = readFile(b.URI)
+		}
+		if err == nil && len(data) < b.ByteLength {
+			err = fmt.Errorf("has %d bytes, expected %d", len(data), b.ByteLength)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("buffer %d: %v", i, err)
+		}
+		d.buffers = append(d.buffers, data)
+	}
+
+	return d.model()
+}
+
+// splitGLB returns the JSON and binary chunks of a GLB file. The binary chunk is nil if there isn't one.
+func splitGLB(data []byte) (jsonData, bin []byte, err error) {
+	if len(data) < 12 || binary.LittleEndian.Uint32(data[4:]) != 2 {
+		return nil, nil, errors.New("GLB header is invalid or not version 2")
+	}
+	if length := binary.LittleEndian.Uint32(data[8:]); int(length) > len(data) {
+		return nil, nil, fmt.Errorf("GLB file has %d bytes, expected %d", len(data), length)
+	}
+
+	for chunks := data[12:]; len(chunks) >= 8; {
+		length, chunkType := binary.LittleEndian.Uint32(chunks), binary.LittleEndian.Uint32(chunks[4:])
+		if int(length) > len(chunks)-8 {
+			return nil, nil, errors.New("GLB chunk runs past the end of the file")
+		}
+		chunk := chunks[8 : 8+length]
+		chunks = chunks[8+length:]
+
+		switch {
+		case chunkType == glbChunkJSON && jsonData == nil:
+			jsonData = chunk
+		case chunkType == glbChunkBIN && bin == nil:
+			bin = chunk
+		}
+	}
+
+	if jsonData == nil {
+		return nil, nil, errors.New("GLB file has no JSON chunk")
+	}
+	return jsonData, bin, nil
+}
+
+// decodeDataURI decodes the data of a base64 data URI.
+func decodeDataURI(uri string) ([]byte, error) {
+	i := strings.Index(uri, ";base64,")
+	if i < 0 {
+		return nil, errors.New("data URI isn't base64")
+	}
+	return base64.StdEncoding.DecodeString(uri[i+len(";base64,"):])
+}
+
+// decoder holds the state of decoding a glTF file.
+type decoder struct {
+	doc     *document
+	buffers [][]byte
+}
+
+// model builds the model from the document.
+func (d *decoder) model() (*Model, error) {
+	doc := d.doc
+	m := &Model{Scene: -1}
+	if err := checkIndex(doc.Scene, len(doc.Scenes), "scene"); err != nil {
+		return nil, err
+	}
+	if doc.Scene != nil {
+		m.Scene = *doc.Scene
+	}
+	for i, s := range doc.Scenes {
+		for _, n := range s.Nodes {
+			if n < 0 || n >= len(doc.Nodes) {
+				return nil, fmt.Errorf("scene %d: node %d is out of range", i, n)
+			}
+		}
+		m.Scenes = append(m.Scenes, Scene{Name: s.Name, Nodes: s.Nodes})
+	}
+
+	m.Nodes = make([]Node, len(doc.Nodes))
+	for i := range m.Nodes {
+		m.Nodes[i].Parent = -1
+	}
+	for i, n := range doc.Nodes {
+		if err := checkIndex(n.Mesh, len(doc.Meshes), "mesh"); err != nil {
+			return nil, fmt.Errorf("node %d: %v", i, err)
+		}
+		if err := checkIndex(n.Skin, len(doc.Skins), "skin"); err != nil {
+			return nil, fmt.Errorf("node %d: %v", i, err)
+		}
+		node := &m.Nodes[i]
+		node.Name, node.Children, node.Mesh, node.Skin = n.Name, n.Children, optional(n.Mesh), optional(n.Skin)
+		for _, c := range n.Children {
+			if c < 0 || c >= len(m.Nodes) || m.Nodes[c].Parent >= 0 {
+				return nil, fmt.Errorf("node %d: child %d is out of range or has another parent", i, c)
+			}
+			m.Nodes[c].Parent = i
+		}
+
+		node.Translation, node.Rotation, node.Scale = mgl32.Vec3{}, mgl32.QuatIdent(), mgl32.Vec3{1, 1, 1}
+		if n.Matrix != nil {
+			node.Translation, node.Rotation, node.Scale = decompose(mgl32.Mat4(*n.Matrix))
+		}
+		if n.Translation != nil {
+			node.Translation = *n.Translation
+		}
+		if r := n.Rotation; r != nil {
+			node.Rotation = mgl32.Quat{W: r[3], V: mgl32.Vec3{r[0], r[1], r[2]}}
+		}
+		if n.Scale != nil {
+			node.Scale = *n.Scale
+		}
+	}
+	for i := range m.Nodes {
+		// A node can have no more ancestors than there are nodes
+		p := m.Nodes[i].Parent
+		for steps := 0; p >= 0; steps++ {
+			if steps == len(m.Nodes) {
+				return nil, fmt.Errorf("node %d is its own ancestor", i)
+			}
+			p = m.Nodes[p].Parent
+		}
+	}
+
+	for i := range doc.Meshes {
+		mesh, err := d.mesh(i)
+		if err != nil {
+			return nil, fmt.Errorf("mesh %d: %v", i, err)
+		}
+		m.Meshes = append(m.Meshes, mesh)
+	}
+
+	for i, s := range doc.Skins {
+		if err := checkIndex(s.Skeleton, len(doc.Nodes), "skeleton node"); err != nil {
+			return nil, fmt.Errorf("skin %d: %v", i, err)
+		}
+		for _, j := range s.Joints {
+			if j < 0 || j >= len(doc.Nodes) {
+				return nil, fmt.Errorf("skin %d: joint node %d is out of range", i, j)
+			}
+		}
+		skin := Skin{Name: s.Name, Joints: s.Joints, Skeleton: optional(s.Skeleton)}
+		if s.InverseBindMatrices != nil {
+			matrices, err := d.mat4s(*s.InverseBindMatrices)
+			if err == nil && len(matrices) < len(s.Joints) {
+				err = fmt.Errorf("%d inverse bind matrices for %d joints", len(matrices), len(s.Joints))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("skin %d: %v", i, err)
+			}
+			skin.InverseBindMatrices = matrices[:len(s.Joints)]
+		} else {
+			skin.InverseBindMatrices = make([]mgl32.Mat4, len(s.Joints))
+			for j := range skin.InverseBindMatrices {
+				skin.InverseBindMatrices[j] = mgl32.Ident4()
+			}
+		}
+		m.Skins = append(m.Skins, skin)
+	}
+
+	for i := range doc.Animations {
+		animation, err := d.animation(i, len(m.Nodes))
+		if err != nil {
+			return nil, fmt.Errorf("animation %d: %v", i, err)
+		}
+		m.Animations = append(m.Animations, animation)
+	}
+
+	return m, nil
+}
+
+// optional returns an optional index, or -1 if it's absent.
+func optional(i *int) int {
+	if i == nil {
+		return -1
+	}
+	return *i
+}
+
+// checkIndex checks that an optional index is absent or less than n.
+func checkIndex(i *int, n int, name string) error {
+	if i != nil && (*i < 0 || *i >= n) {
+		return fmt.Errorf("%s %d is out of range", name, *i)
+	}
+	return nil
+}
+
+// decompose splits a matrix made of a translation, a rotation and a scale into them. A negative determinant is taken
+// to be a negative X scale.
+func decompose(m mgl32.Mat4) (translation mgl32.Vec3, rotation mgl32.Quat, scale mgl32.Vec3) {
+	translation = m.Col(3).Vec3()
+	scale = mgl32.Vec3{m.Col(0).Vec3().Len(), m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()}
+	if m.Mat3().Det() < 0 {
+		scale[0] = -scale[0]
+	}
+
+	var r mgl32.Mat4
+	for i := 0; i < 3; i++ {
+		if scale[i] != 0 {
+			r.SetCol(i, m.Col(i).Mul(1/scale[i]))
+		}
+	}
+	return translation, mgl32.Mat4ToQuat(r).Normalize(), scale
+}
+
+// mesh decodes mesh i.
+func (d *decoder) mesh(i int) (Mesh, error) {
+	m := d.doc.Meshes[i]
+	mesh := Mesh{Name: m.Name}
+	for j, p := range m.Primitives {
+		primitive, err := d.primitive(p.Attributes, p.Indices, p.Mode)
+		if err != nil {
+			return Mesh{}, fmt.Errorf("primitive %d: %v", j, err)
+		}
+		primitive.Material = optional(p.Material)
+		mesh.Primitives = append(mesh.Primitives, primitive)
+	}
+	return mesh, nil
+}
+
+// primitive decodes the attributes and indices of a primitive.
+func (d *decoder) primitive(attributes map[string]int, indices, mode *int) (Primitive, error) {
+	position, ok := attributes["POSITION"]
+	if !ok {
+		return Primitive{}, errors.New("primitive has no positions")
+	}
+
+	p := Primitive{Mesh: &mgl32.Mesh{}}
+	mesh := p.Mesh
+	var err error
+	if mesh.Positions, err = d.vec3s(position); err != nil {
+		return Primitive{}, fmt.Errorf("POSITION: %v", err)
+	}
+	n := len(mesh.Positions)
+
+	// Each attribute has the same number of elements as the positions
+	checked := func(name string, decode func(accessor int) (int, error)) {
+		accessor, ok := attributes[name]
+		if !ok || err != nil {
+			return
+		}
+		var count int
+		if count, err = decode(accessor); err == nil && count != n {
+			err = fmt.Errorf("%d elements, expected %d", count, n)
+		}
+		if err != nil {
+			err = fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	checked("NORMAL", func(a int) (n int, err error) {
+		mesh.Normals, err = d.vec3s(a)
+		return len(mesh.Normals), err
+	})
+	checked("TANGENT", func(a int) (n int, err error) {
+		mesh.Tangents, err = d.vec4s(a)
+		return len(mesh.Tangents), err
+	})
+	checked("TEXCOORD_0", func(a int) (n int, err error) {
+		mesh.UVs, err = d.vec2s(a)
+		for i := range mesh.UVs {
+			mesh.UVs[i][1] = 1 - mesh.UVs[i][1]
+		}
+		return len(mesh.UVs), err
+	})
+	checked("COLOR_0", func(a int) (n int, err error) {
+		values, size, err := d.floats(a)
+		if err != nil {
+			return 0, err
+		}
+		if size != 3 && size != 4 {
+			return 0, fmt.Errorf("%d components, expected 3 or 4", size)
+		}
+		p.Colors = make([]mgl32.Vec4, len(values)/size)
+		for i := range p.Colors {
+			p.Colors[i] = mgl32.Vec4{0, 0, 0, 1}
+			copy(p.Colors[i][:], values[i*size:(i+1)*size])
+		}
+		return len(p.Colors), nil
+	})
+	checked("JOINTS_0", func(a int) (n int, err error) {
+		values, size, err := d.uints(a)
+		if err != nil {
+			return 0, err
+		}
+		if size != 4 {
+			return 0, fmt.Errorf("%d components, expected 4", size)
+		}
+		p.Joints = make([][4]uint16, len(values)/4)
+		for i := range p.Joints {
+			for j := range p.Joints[i] {
+				p.Joints[i][j] = uint16(values[4*i+j])
+			}
+		}
+		return len(p.Joints), nil
+	})
+	checked("WEIGHTS_0", func(a int) (n int, err error) {
+		p.Weights, err = d.vec4s(a)
+		return len(p.Weights), err
+	})
+	if err != nil {
+		return Primitive{}, err
+	}
+
+	var order []uint32
+	if indices != nil {
+		var size int
+		if order, size, err = d.uints(*indices); err == nil && size != 1 {
+			err = fmt.Errorf("%d components, expected 1", size)
+		}
+		if err != nil {
+			return Primitive{}, fmt.Errorf("indices: %v", err)
+		}
+		for _, v := range order {
+			if int(v) >= n {
+				return Primitive{}, fmt.Errorf("index %d is out of range, with %d vertices", v, n)
+			}
+		}
+	} else {
+		order = make([]uint32, n)
+		for i := range order {
+			order[i] = uint32(i)
+		}
+	}
+
+	switch m := optional(mode); m {
+	case -1, modeTriangles:
+		mesh.Indices = order[:len(order)/3*3]
+	case modeTriangleStrip:
+		for i := 2; i < len(order); i++ {
+			// Every other triangle of a strip is wound the other way
+			if i%2 == 0 {
+				mesh.Indices = append(mesh.Indices, order[i-2], order[i-1], order[i])
+			} else {
+				mesh.Indices = append(mesh.Indices, order[i-1], order[i-2], order[i])
+			}
+		}
+	case modeTriangleFan:
+		for i := 2; i < len(order); i++ {
+			mesh.Indices = append(mesh.Indices, order[0], order[i-1], order[i])
+		}
+	default:
+		return Primitive{}, fmt.Errorf("mode %d isn't supported, only triangles", m)
+	}
+
+	return p, nil
+}
+
+// WorldTransforms returns the transform of each node from its own space to the space of the scene, for the nodes'
+// current translations, rotations and scales.
+func (m *Model) WorldTransforms() []mgl32.Mat4 {
+	world := make([]mgl32.Mat4, len(m.Nodes))
+	done := make([]bool, len(m.Nodes))
+
+	var transform func(i int) mgl32.Mat4
+	transform = func(i int) mgl32.Mat4 {
+		if !done[i] {
+			world[i], done[i] = m.Nodes[i].Transform(), true
+			if p := m.Nodes[i].Parent; p >= 0 {
+				world[i] = transform(p).Mul4(world[i])
+			}
+		}
+		return world[i]
+	}
+	for i := range m.Nodes {
+		transform(i)
+	}
+
+	return world
+}
+
+// Transform returns the transform from the node's space to its parent's: its scale, then its rotation, then its
+// translation.
+func (n *Node) Transform() mgl32.Mat4 {
+	t := n.Translation
+	return mgl32.Translate3D(t[0], t[1], t[2]).Mul4(n.Rotation.Normalize().Mat4()).Mul4(mgl32.Scale3D(n.Scale[0], n.Scale[1], n.Scale[2]))
+}
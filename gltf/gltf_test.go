@@ -0,0 +1,299 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testdata/rig.gltf is a quad skinned to a two joint leg, with an animation using each kind of interpolation. Its
+// buffer has interleaved positions and normals, normalized UVs and rotations, and a sparse accessor.
+
+func checkRig(t *testing.T, model *Model) {
+	if len(model.Meshes) != 1 || len(model.Meshes[0].Primitives) != 1 {
+		t.Fatalf("Model has meshes %+v, expected one with one primitive", model.Meshes)
+	}
+	p := model.Meshes[0].Primitives[0]
+	mesh := p.Mesh
+
+	positions := []mgl32.Vec3{{-.5, 0, 0}, {.5, 0, 0}, {.5, 2, 0}, {-.5, 2, 0}}
+	normals := []mgl32.Vec3{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {0, 0, 1}}
+	uvs := []mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	if !reflect.DeepEqual(mesh.Positions, positions) || !reflect.DeepEqual(mesh.Normals, normals) || !reflect.DeepEqual(mesh.UVs, uvs) {
+		t.Errorf("Vertices are %v, %v and %v, expected %v, %v and %v", mesh.Positions, mesh.Normals, mesh.UVs, positions, normals, uvs)
+	}
+	if indices := []uint32{0, 1, 2, 0, 2, 3}; !reflect.DeepEqual(mesh.Indices, indices) || mesh.Tangents != nil {
+		t.Errorf("Indices are %v and tangents %v, expected %v and none", mesh.Indices, mesh.Tangents, indices)
+	}
+	joints := [][4]uint16{{0, 0, 0, 0}, {0, 0, 0, 0}, {0, 1, 0, 0}, {0, 1, 0, 0}}
+	weights := []mgl32.Vec4{{1, 0, 0, 0}, {1, 0, 0, 0}, {.5, .5, 0, 0}, {.5, .5, 0, 0}}
+	if !reflect.DeepEqual(p.Joints, joints) || !reflect.DeepEqual(p.Weights, weights) || p.Colors != nil || p.Material != 0 {
+		t.Errorf("Primitive has joints %v, weights %v, colors %v and material %d", p.Joints, p.Weights, p.Colors, p.Material)
+	}
+
+	if model.Scene != 0 || !reflect.DeepEqual(model.Scenes, []Scene{{"Scene", []int{0, 1}}}) {
+		t.Errorf("Scenes are %v, with scene %d", model.Scenes, model.Scene)
+	}
+	for i, parent := range []int{-1, -1, 1, 2} {
+		if n := model.Nodes[i]; n.Parent != parent {
+			t.Errorf("Node %s has parent %d, expected %d", n.Name, n.Parent, parent)
+		}
+	}
+	if n := model.Nodes[0]; n.Mesh != 0 || n.Skin != 0 || n.Scale != (mgl32.Vec3{1, 1, 1}) || n.Rotation != mgl32.QuatIdent() {
+		t.Errorf("Body node is %+v", n)
+	}
+
+	// The knee is a matrix, which is split up
+	knee := model.Nodes[2]
+	rotation := mgl32.QuatRotate(math.Pi/2, mgl32.Vec3{0, 0, 1})
+	if !knee.Translation.ApproxEqual(mgl32.Vec3{0, 1, 0}) || !knee.Rotation.ApproxEqualThreshold(rotation, 1e-6) ||
+		!knee.Scale.ApproxEqual(mgl32.Vec3{1, 2, 1}) {
+		t.Errorf("Knee is %v, %v and %v, expected (0,1,0), %v and (1,2,1)", knee.Translation, knee.Rotation, knee.Scale, rotation)
+	}
+
+	// The inverse bind matrices undo the joints' transforms in the rest pose. With a zero expected, the threshold
+	// is squared, so 1e-3 is 1e-6 there
+	skin := model.Skins[0]
+	if skin.Name != "Leg" || !reflect.DeepEqual(skin.Joints, []int{1, 2}) || skin.Skeleton != 1 || len(skin.InverseBindMatrices) != 2 {
+		t.Fatalf("Skin is %+v", skin)
+	}
+	world := model.WorldTransforms()
+	for i, joint := range skin.Joints {
+		if m := world[joint].Mul4(skin.InverseBindMatrices[i]); !m.ApproxEqualThreshold(mgl32.Ident4(), 1e-3) {
+			t.Errorf("Joint %d times its inverse bind matrix is %v", joint, m)
+		}
+	}
+	if tip := world[3].Mul4x1(mgl32.Vec4{0, 0, 0, 1}); !tip.ApproxEqualThreshold(mgl32.Vec4{-2, 2, 0, 1}, 1e-3) {
+		t.Errorf("Tip is at %v, expected (-2,2,0)", tip)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	model, err := Load("testdata/rig.gltf")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	checkRig(t, model)
+
+	if _, err := Read(strings.NewReader(readFile(t, "testdata/rig.gltf"))); err == nil || !strings.Contains(err.Error(), "rig.bin") {
+		t.Errorf("Reading a file with a separate buffer gives error %v", err)
+	}
+	if _, err := Load("testdata/missing.gltf"); err == nil {
+		t.Error("Loading a missing file succeeded")
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// embedded returns the JSON of testdata/rig.gltf with its buffer's URI changed, or removed if uri is "".
+func embedded(t *testing.T, uri string) []byte {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(readFile(t, "testdata/rig.gltf")), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := doc["buffers"].([]interface{})[0].(map[string]interface{})
+	if uri == "" {
+		delete(buffer, "uri")
+	} else {
+		buffer["uri"] = uri
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestReadEmbedded(t *testing.T) {
+	bin := []byte(readFile(t, "testdata/rig.bin"))
+
+	model, err := Read(bytes.NewReader(embedded(t, "data:application/gltf-buffer;base64,"+base64.StdEncoding.EncodeToString(bin))))
+	if err != nil {
+		t.Fatalf("Reading with a data URI failed: %v", err)
+	}
+	checkRig(t, model)
+
+	// A GLB file has a header and chunks, each padded to 4 bytes
+	jsonChunk := embedded(t, "")
+	for len(jsonChunk)%4 != 0 {
+		jsonChunk = append(jsonChunk, ' ')
+	}
+	var glb bytes.Buffer
+	for _, v := range []interface{}{
+		uint32(glbMagic), uint32(2), uint32(12 + 8 + len(jsonChunk) + 8 + len(bin)),
+		uint32(len(jsonChunk)), uint32(glbChunkJSON), jsonChunk,
+		uint32(len(bin)), uint32(glbChunkBIN), bin,
+	} {
+		binary.Write(&glb, binary.LittleEndian, v)
+	}
+
+	model, err = Read(&glb)
+	if err != nil {
+		t.Fatalf("Reading GLB failed: %v", err)
+	}
+	checkRig(t, model)
+}
+
+func TestAnimation(t *testing.T) {
+	model, err := Load("testdata/rig.gltf")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	a := model.Animations[0]
+	if a.Name != "Walk" || len(a.Channels) != 3 || a.Duration() != 2 {
+		t.Fatalf("Animation is %+v, with duration %v", a, a.Duration())
+	}
+	expected := []Channel{{1, Rotation, 0}, {1, Translation, 1}, {3, Scale, 2}}
+	if !reflect.DeepEqual(a.Channels, expected) {
+		t.Errorf("Channels are %v, expected %v", a.Channels, expected)
+	}
+	for i, interpolation := range []Interpolation{Linear, Step, CubicSpline} {
+		if s := a.Samplers[i]; s.Interpolation != interpolation || s.Size != []int{4, 3, 3}[i] {
+			t.Errorf("Sampler %d has %s interpolation and size %d", i, s.Interpolation, s.Size)
+		}
+	}
+
+	// Rotations are normalized shorts, so only accurate to about 1e-4
+	rotation := &a.Samplers[0]
+	for _, c := range []struct {
+		time, degrees float32
+	}{{-1, 0}, {0, 0}, {.5, 45}, {.25, 22.5}, {1, 90}, {3, 90}} {
+		q := mgl32.QuatRotate(mgl32.DegToRad(c.degrees), mgl32.Vec3{0, 1, 0})
+		if r := rotation.Quat(c.time); !r.ApproxEqualThreshold(q, 1e-4) {
+			t.Errorf("Rotation at %v is %v, expected %v", c.time, r, q)
+		}
+	}
+
+	// The translations are sparse, with only the middle one set
+	translation := &a.Samplers[1]
+	for time, y := range map[float32]float32{-1: 0, 0: 0, .5: 0, 1: 2, 1.5: 2, 2: 0, 3: 0} {
+		if v := translation.Vec3(time); v != (mgl32.Vec3{0, y, 0}) {
+			t.Errorf("Translation at %v is %v, expected (0,%v,0)", time, v, y)
+		}
+	}
+
+	// The scale leaves 1 with a slope of 3 and arrives at 2 with a slope of 0
+	scale := &a.Samplers[2]
+	for time, s := range map[float32]float32{0: 1, .5: 1.875, 1: 2, 2: 2} {
+		if v := scale.Vec3(time); !v.ApproxEqual(mgl32.Vec3{s, s, s}) {
+			t.Errorf("Scale at %v is %v, expected %v", time, v, s)
+		}
+	}
+
+	a.Apply(1, model.Nodes)
+	hip, tip := model.Nodes[1], model.Nodes[3]
+	if !hip.Rotation.ApproxEqualThreshold(mgl32.QuatRotate(math.Pi/2, mgl32.Vec3{0, 1, 0}), 1e-4) ||
+		hip.Translation != (mgl32.Vec3{0, 2, 0}) || tip.Scale != (mgl32.Vec3{2, 2, 2}) {
+		t.Errorf("Applying the animation gives hip %+v and tip %+v", hip, tip)
+	}
+	if knee := model.Nodes[2]; !knee.Scale.ApproxEqual(mgl32.Vec3{1, 2, 1}) {
+		t.Errorf("Applying the animation changed the knee to %+v", knee)
+	}
+}
+
+// zeros is a data URI of 48 zero bytes, four positions at the origin.
+var zeros = "data:application/octet-stream;base64," + strings.Repeat("A", 64)
+
+// quadDocument returns a glTF document with a mesh of four positions and the given primitive mode.
+func quadDocument(mode int) string {
+	return `{"asset": {"version": "2.0"},
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "mode": ` + strconv.Itoa(mode) + `}]}],
+		"accessors": [{"bufferView": 0, "componentType": 5126, "count": 4, "type": "VEC3"}],
+		"bufferViews": [{"buffer": 0, "byteLength": 48}],
+		"buffers": [{"uri": "` + zeros + `", "byteLength": 48}]}`
+}
+
+func TestPrimitiveModes(t *testing.T) {
+	for mode, indices := range map[int][]uint32{
+		modeTriangles:     {0, 1, 2},
+		modeTriangleStrip: {0, 1, 2, 2, 1, 3},
+		modeTriangleFan:   {0, 1, 2, 0, 2, 3},
+	} {
+		model, err := Read(strings.NewReader(quadDocument(mode)))
+		if err != nil {
+			t.Fatalf("Reading mode %d failed: %v", mode, err)
+		}
+		p := model.Meshes[0].Primitives[0]
+		if !reflect.DeepEqual(p.Mesh.Indices, indices) || p.Mesh.Normals != nil || p.Mesh.UVs != nil || p.Material != -1 {
+			t.Errorf("Mode %d gives indices %v, expected %v", mode, p.Mesh.Indices, indices)
+		}
+		if model.Scene != -1 || model.Scenes != nil {
+			t.Errorf("Model without scenes has scene %d of %v", model.Scene, model.Scenes)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	valid := quadDocument(modeTriangles)
+	for _, c := range []struct {
+		old, new, message string
+	}{
+		{`"2.0"`, `"1.0"`, "glTF version 1.0 isn't supported"},
+		{`"asset"`, `"extensionsRequired": ["KHR_draco_mesh_compression"], "asset"`, "extension KHR_draco_mesh_compression isn't supported"},
+		{`"mode": 4`, `"mode": 1`, "mesh 0: primitive 0: mode 1 isn't supported, only triangles"},
+		{`"POSITION": 0`, `"POSITION": 1`, "mesh 0: primitive 0: POSITION: accessor 1 is out of range"},
+		{`"POSITION": 0`, `"POSITION": 0, "NORMAL": 0, "TEXCOORD_0": 0`, "mesh 0: primitive 0: TEXCOORD_0: accessor 0 has 3 components, expected 2"},
+		{`"count": 4`, `"count": 5`, "mesh 0: primitive 0: POSITION: accessor 0: 5 elements of 12 bytes, 12 apart from byte 0, run past the end of buffer view 0"},
+		{`"byteLength": 48}]}`, `"byteLength": 49}]}`, "buffer 0: has 48 bytes, expected 49"},
+		{`"meshes"`, `"nodes": [{"children": [1]}, {"children": [0]}], "meshes"`, "node 0 is its own ancestor"},
+		{`"meshes"`, `"nodes": [{"children": [2]}], "meshes"`, "node 0: child 2 is out of range or has another parent"},
+		{`"meshes"`, `"nodes": [{"mesh": 1}], "meshes"`, "node 0: mesh 1 is out of range"},
+		{`"meshes"`, `"nodes": [{"skin": 0}], "meshes"`, "node 0: skin 0 is out of range"},
+		{`"meshes"`, `"nodes": [{}], "skins": [{"joints": [0, 1]}], "meshes"`, "skin 0: joint node 1 is out of range"},
+		{`"meshes"`, `"nodes": [{}], "skins": [{"joints": [0], "skeleton": -1}], "meshes"`, "skin 0: skeleton node -1 is out of range"},
+		{`"meshes"`, `"scenes": [{"nodes": [0]}], "meshes"`, "scene 0: node 0 is out of range"},
+		{`"meshes"`, `"scene": 0, "meshes"`, "scene 0 is out of range"},
+		// Malformed accessors and buffer views give errors, rather than panicking or allocating without bound
+		{`"count": 4`, `"count": -1`, "mesh 0: primitive 0: POSITION: accessor 0 has component type 5126, type \"VEC3\" and count -1"},
+		{`"byteLength": 48}]`, `"byteLength": 48, "byteStride": 1000000}]`, "mesh 0: primitive 0: POSITION: accessor 0: buffer view 0 has stride 1000000"},
+		{`"count": 4`, `"count": 4, "byteOffset": 60`, "mesh 0: primitive 0: POSITION: accessor 0: 4 elements of 12 bytes, 12 apart from byte 60, run past the end of buffer view 0"},
+		{`"count": 4`, `"count": 1099511627776`, "mesh 0: primitive 0: POSITION: accessor 0: 1099511627776 elements of 12 bytes, 12 apart from byte 0, run past the end of buffer view 0"},
+		{`"bufferView": 0, "componentType": 5126, "count": 4`, `"componentType": 5126, "count": 1099511627776`,
+			"mesh 0: primitive 0: POSITION: accessor 0 has 1099511627776 elements of 3 components and no buffer view, more than the 48 bytes of buffers"},
+		{`"bufferView": 0, "componentType": 5126, "count": 4, "type": "VEC3"`, `"componentType": 5126, "count": 16777216, "type": "MAT4"`,
+			"mesh 0: primitive 0: POSITION: accessor 0 has 16777216 elements of 16 components and no buffer view, more than the 48 bytes of buffers"},
+		{`"byteLength": 48}]`, `"byteOffset": 9223372036854775800, "byteLength": 12}]`,
+			"mesh 0: primitive 0: POSITION: accessor 0: buffer view 0 is outside its buffer"},
+		{`"count": 4`, `"count": 4, "sparse": {"count": -1, "indices": {"bufferView": 0, "componentType": 5121}, "values": {"bufferView": 0}}`,
+			"mesh 0: primitive 0: POSITION: accessor 0: -1 sparse elements of 4"},
+		{`"count": 4`, `"count": 4, "sparse": {"count": 0, "indices": {"bufferView": 0, "byteOffset": 49, "componentType": 5121}, "values": {"bufferView": 0}}`,
+			"mesh 0: primitive 0: POSITION: accessor 0: sparse indices: 0 elements of 1 bytes, 1 apart from byte 49, run past the end of buffer view 0"},
+	} {
+		doc := strings.Replace(valid, c.old, c.new, 1)
+		if _, err := Read(strings.NewReader(doc)); err == nil || err.Error() != c.message {
+			t.Errorf("Reading with %s gives error %v, expected %q", c.new, err, c.message)
+		}
+	}
+
+	// A sparse-only accessor with no more components than buffer bytes is fine
+	sparseOnly := strings.Replace(valid, `"bufferView": 0, "componentType": 5126`, `"componentType": 5126`, 1)
+	if model, err := Read(strings.NewReader(sparseOnly)); err != nil || len(model.Meshes[0].Primitives[0].Mesh.Positions) != 4 {
+		t.Errorf("Reading a sparse-only accessor fails: %v", err)
+	}
+
+	if _, err := Read(strings.NewReader("glTF\x01\x00\x00\x00")); err == nil {
+		t.Error("Reading a GLB file of version 1 succeeded")
+	}
+}
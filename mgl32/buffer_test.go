@@ -0,0 +1,189 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.17
+// +build go1.17
+
+package mgl32
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testLight struct {
+	Position  Vec3
+	Intensity float32
+}
+
+// testBlock is
+//
+//	struct Light { vec3 Position; float Intensity; };
+//	float Time; vec3 Color; mat3 Normal; float Weights[3]; vec2 UV; Light Lights[2]; mat2 M; uint Flags;
+type testBlock struct {
+	Time    float32
+	Color   Vec3
+	Normal  Mat3
+	Weights [3]float32
+	UV      Vec2
+	Lights  [2]testLight
+	M       Mat2
+	Flags   uint32
+}
+
+// layoutExpectation is the expected layout of testBlock.
+type layoutExpectation struct {
+	rule      LayoutRule
+	offsets   []int
+	size      int
+	strides   map[string][2]int // Array and matrix strides
+	lightSize int
+}
+
+func TestBufferLayoutOffsets(t *testing.T) {
+	tests := []layoutExpectation{
+		{Std140, []int{0, 16, 32, 80, 128, 144, 176, 208}, 224,
+			map[string][2]int{"Normal": {0, 16}, "Weights": {16, 0}, "Lights": {16, 0}, "M": {0, 16}}, 16},
+		{Std430, []int{0, 16, 32, 80, 96, 112, 144, 160}, 176,
+			map[string][2]int{"Normal": {0, 16}, "Weights": {4, 0}, "Lights": {16, 0}, "M": {0, 8}}, 16},
+	}
+	alignment := 16
+	if floatBits == 64 {
+		// Doubles don't simply double everything, since arrays in std140 still round up to 16 bytes
+		tests = []layoutExpectation{
+			{Std140, []int{0, 32, 64, 160, 208, 224, 288, 320}, 352,
+				map[string][2]int{"Normal": {0, 32}, "Weights": {16, 0}, "Lights": {32, 0}, "M": {0, 16}}, 32},
+			{Std430, []int{0, 32, 64, 160, 192, 224, 288, 320}, 352,
+				map[string][2]int{"Normal": {0, 32}, "Weights": {8, 0}, "Lights": {32, 0}, "M": {0, 16}}, 32},
+		}
+		alignment = 32
+	}
+
+	for _, c := range tests {
+		l, err := NewBufferLayout(c.rule, &testBlock{})
+		if err != nil {
+			t.Fatalf("Layout of %v failed: %v", c.rule, err)
+		}
+		if l.Size != c.size || l.Alignment != alignment {
+			t.Errorf("Block in %v is %d bytes aligned to %d, expected %d aligned to %d", c.rule, l.Size, l.Alignment, c.size, alignment)
+		}
+		for i, f := range l.Fields {
+			if f.Offset != c.offsets[i] {
+				t.Errorf("Offset of %s in %v is %d, expected %d", f.Name, c.rule, f.Offset, c.offsets[i])
+			}
+			if s := c.strides[f.Name]; f.ArrayStride != s[0] || f.MatrixStride != s[1] {
+				t.Errorf("Strides of %s in %v are %d and %d, expected %v", f.Name, c.rule, f.ArrayStride, f.MatrixStride, s)
+			}
+		}
+		if f, ok := l.Field("Lights"); !ok || f.Layout == nil || f.Layout.Size != c.lightSize || f.Size != 2*c.lightSize {
+			t.Errorf("Lights in %v are %+v, expected 2 of %d bytes", c.rule, f, c.lightSize)
+		}
+	}
+
+	for _, bad := range []interface{}{nil, 3, Vec3{}, struct{ B bool }{}, struct{ S []Vec3 }{}, struct{ A [0]Vec3 }{}} {
+		if _, err := NewBufferLayout(Std140, bad); err == nil {
+			t.Errorf("Layout of %T succeeded", bad)
+		}
+	}
+}
+
+func TestBufferLayoutPut(t *testing.T) {
+	block := testBlock{
+		Time:    1.5,
+		Color:   Vec3{.1, .2, .3},
+		Normal:  Mat3{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		Weights: [3]float32{10, 11, 12},
+		UV:      Vec2{13, 14},
+		Lights:  [2]testLight{{Vec3{15, 16, 17}, 18}, {Vec3{19, 20, 21}, 22}},
+		M:       Mat2{23, 24, 25, 26},
+		Flags:   27,
+	}
+
+	for _, rule := range []LayoutRule{Std140, Std430} {
+		l, err := NewBufferLayout(rule, block)
+		if err != nil {
+			t.Fatalf("Layout of %v failed: %v", rule, err)
+		}
+
+		buf := bytes.Repeat([]byte{0xff}, l.Size)
+		if err := l.Put(buf, block); err != nil {
+			t.Fatalf("Put in %v failed: %v", rule, err)
+		}
+
+		// Check every scalar is where the offsets and strides say
+		at := func(offset int, expected []byte, what string) {
+			if got := buf[offset : offset+len(expected)]; !bytes.Equal(got, expected) {
+				t.Errorf("%s in %v at %d is %v, expected %v", what, rule, offset, got, expected)
+			}
+		}
+		f := func(name string) BufferField { field, _ := l.Field(name); return field }
+		at(f("Time").Offset, SliceBytes([]float32{block.Time}), "Time")
+		at(f("Color").Offset, SliceBytes([]Vec3{block.Color}), "Color")
+		for col := 0; col < 3; col++ {
+			at(f("Normal").Offset+col*f("Normal").MatrixStride, SliceBytes([]Vec3{block.Normal.Col(col)}), "Normal column")
+			at(f("Weights").Offset+col*f("Weights").ArrayStride, SliceBytes(block.Weights[col:col+1]), "Weight")
+		}
+		at(f("UV").Offset, SliceBytes([]Vec2{block.UV}), "UV")
+		for i, light := range block.Lights {
+			base := f("Lights").Offset + i*f("Lights").ArrayStride
+			lightLayout := f("Lights").Layout
+			at(base+lightLayout.Fields[0].Offset, SliceBytes([]Vec3{light.Position}), "Light position")
+			at(base+lightLayout.Fields[1].Offset, SliceBytes([]float32{light.Intensity}), "Light intensity")
+		}
+		at(f("M").Offset+f("M").MatrixStride, SliceBytes([]Vec2{block.M.Col(1)}), "M column")
+		at(f("Flags").Offset, SliceBytes([]uint32{block.Flags}), "Flags")
+
+		// Padding is untouched by Put, and zero with Append
+		if buf[12] != 0xff {
+			t.Errorf("Put changed padding in %v", rule)
+		}
+		appended, err := l.Append([]byte{1}, &block)
+		if err != nil || len(appended) != 1+l.Size || !bytes.Equal(appended[1:5], buf[:4]) || appended[13] != 0 {
+			t.Errorf("Append in %v gives %v, error %v", rule, appended, err)
+		}
+
+		if err := l.Put(buf[:l.Size-1], block); err == nil {
+			t.Errorf("Put into a short buffer succeeded")
+		}
+		if err := l.Put(buf, testLight{}); err == nil {
+			t.Errorf("Put of the wrong type succeeded")
+		}
+	}
+}
+
+func TestSliceViews(t *testing.T) {
+	vs := []Vec3{{1, 2, 3}, {4, 5, 6}}
+	floats := SliceFloats(vs)
+	if len(floats) != 6 || floats[4] != 5 {
+		t.Errorf("Floats of %v are %v", vs, floats)
+	}
+	floats[4] = 50
+	if vs[1][1] != 50 {
+		t.Errorf("Changing the floats of a []Vec3 didn't change it")
+	}
+	if b := SliceBytes(vs); len(b) != 6*len(SliceBytes([]float32{0})) {
+		t.Errorf("Bytes of %v are %v", vs, b)
+	}
+
+	if floats := SliceFloats([]Quat{QuatIdent()}); len(floats) != 4 || floats[0] != 1 {
+		t.Errorf("Floats of the identity quaternion are %v", floats)
+	}
+	if SliceFloats([]Mat4{}) != nil || SliceBytes([]Mat4(nil)) != nil {
+		t.Errorf("Views of empty slices aren't nil")
+	}
+
+	for _, bad := range []interface{}{Vec3{}, []int32{1}, []struct {
+		F float32
+		I int32
+	}{{}}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SliceFloats of %T didn't panic", bad)
+				}
+			}()
+			SliceFloats(bad)
+		}()
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func randomTriangles(rand *rand.Rand, n int) []Triangle {
+	triangles := make([]Triangle, n)
+	for i := range triangles {
+		center := Vec3{rand.Float32()*20 - 10, rand.Float32()*20 - 10, rand.Float32()*20 - 10}
+		for j := range triangles[i] {
+			triangles[i][j] = center.Add(Vec3{rand.Float32() - .5, rand.Float32() - .5, rand.Float32() - .5})
+		}
+	}
+
+	return triangles
+}
+
+// checkBVH compares every kind of query on the BVH with a brute force search.
+func checkBVH(t *testing.T, name string, rand *rand.Rand, b *BVH) {
+	n := len(b.Boxes)
+	randVec := func(scale float32) Vec3 {
+		return Vec3{(rand.Float32()*2 - 1) * scale, (rand.Float32()*2 - 1) * scale, (rand.Float32()*2 - 1) * scale}
+	}
+
+	for q := 0; q < 100; q++ {
+		r := Ray{randVec(15), randVec(1)}
+		expected := RayHit{Index: -1, T: float32(math.Inf(1))}
+		for i := 0; i < n; i++ {
+			if b.Triangles != nil {
+				if tHit, v, w, ok := b.Triangles[i].IntersectRay(r, expected.T); ok {
+					expected = RayHit{i, tHit, v, w}
+				}
+			} else if tHit, ok := b.Boxes[i].IntersectRay(r, expected.T); ok {
+				expected = RayHit{i, tHit, 0, 0}
+			}
+		}
+
+		hit, ok := b.Raycast(r, float32(math.Inf(1)))
+		if ok != (expected.Index >= 0) || (ok && !FloatEqualThreshold(hit.T, expected.T, 1e-5)) {
+			t.Errorf("%s: ray %v hits %v (%v), expected %v", name, r, hit, ok, expected)
+		}
+
+		box := AABB{randVec(10), Vec3{}}
+		box.Max = box.Min.Add(Vec3{rand.Float32() * 3, rand.Float32() * 3, rand.Float32() * 3})
+		center, radius := randVec(10), rand.Float32()*3
+		var expectedBox, expectedSphere []int
+		for i := 0; i < n; i++ {
+			if b.Triangles != nil {
+				if b.Triangles[i].IntersectsAABB(box) {
+					expectedBox = append(expectedBox, i)
+				}
+				if b.Triangles[i].IntersectsSphere(center, radius) {
+					expectedSphere = append(expectedSphere, i)
+				}
+			} else {
+				if b.Boxes[i].Intersects(box) {
+					expectedBox = append(expectedBox, i)
+				}
+				if b.Boxes[i].IntersectsSphere(center, radius) {
+					expectedSphere = append(expectedSphere, i)
+				}
+			}
+		}
+
+		if found := b.QueryAABB(box, nil); !sameIndices(found, expectedBox) {
+			t.Errorf("%s: box query found %v, expected %v", name, found, expectedBox)
+		}
+		if found := b.QuerySphere(center, radius, nil); !sameIndices(found, expectedSphere) {
+			t.Errorf("%s: sphere query found %v, expected %v", name, found, expectedSphere)
+		}
+
+		p := randVec(15)
+		bestDist := float32(math.Inf(1))
+		for i := 0; i < n; i++ {
+			var q Vec3
+			if b.Triangles != nil {
+				q = b.Triangles[i].ClosestPoint(p)
+			} else {
+				q = b.Boxes[i].ClosestPoint(p)
+			}
+			if d := q.Sub(p).Len(); d < bestDist {
+				bestDist = d
+			}
+		}
+		if _, closest, ok := b.Nearest(p, float32(math.Inf(1))); !ok || !FloatEqualThreshold(closest.Sub(p).Len(), bestDist, 1e-5) {
+			t.Errorf("%s: nearest primitive to %v is %v away, expected %v", name, p, closest.Sub(p).Len(), bestDist)
+		}
+		if _, _, ok := b.Nearest(p, bestDist*.99); ok && bestDist > 0 {
+			t.Errorf("%s: found a primitive nearer to %v than the nearest one", name, p)
+		}
+	}
+}
+
+func sameIndices(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sort.Ints(a)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestTriangleBVH(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	triangles := randomTriangles(rand, 2000)
+	b := NewTriangleBVH(triangles)
+	checkBVH(t, "triangle BVH", rand, b)
+
+	for _, tri := range triangles {
+		if !b.Bounds().Intersects(tri.Bounds()) || b.Bounds().Union(tri.Bounds()) != b.Bounds() {
+			t.Fatalf("BVH bounds %v don't contain triangle %v", b.Bounds(), tri)
+		}
+	}
+
+	// Animate the triangles in place, then refit
+	for i := range triangles {
+		offset := Vec3{rand.Float32() - .5, rand.Float32() - .5, rand.Float32() - .5}
+		for j := range triangles[i] {
+			triangles[i][j] = triangles[i][j].Mul(1.2).Add(offset)
+		}
+	}
+	b.Refit()
+	checkBVH(t, "refitted triangle BVH", rand, b)
+
+	sphere := UVSphereMesh(5, 32, 16)
+	checkBVH(t, "sphere mesh BVH", rand, NewTriangleBVH(sphere.Triangles()))
+}
+
+func TestBoxBVH(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	boxes := make([]AABB, 1000)
+	for i := range boxes {
+		boxes[i].Min = Vec3{rand.Float32()*20 - 10, rand.Float32()*20 - 10, rand.Float32()*20 - 10}
+		boxes[i].Max = boxes[i].Min.Add(Vec3{rand.Float32(), rand.Float32(), rand.Float32()})
+	}
+	// Identical boxes can't be split
+	for i := 0; i < 50; i++ {
+		boxes = append(boxes, boxes[0])
+	}
+
+	b := NewBVH(boxes)
+	checkBVH(t, "box BVH", rand, b)
+
+	empty := NewBVH(nil)
+	if _, ok := empty.Raycast(Ray{Vec3{}, Vec3{1, 0, 0}}, 1); ok {
+		t.Errorf("Ray hits empty BVH")
+	}
+	if found := empty.QuerySphere(Vec3{}, 1, nil); len(found) != 0 {
+		t.Errorf("Sphere query of empty BVH found %v", found)
+	}
+	if !empty.Bounds().IsEmpty() {
+		t.Errorf("Empty BVH has bounds %v", empty.Bounds())
+	}
+}
+
+func BenchmarkBVHRaycast(b *testing.B) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	bvh := NewTriangleBVH(randomTriangles(rand, 100000))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bvh.Raycast(Ray{Vec3{-20, 0, 0}, Vec3{1, rand.Float32()*.2 - .1, rand.Float32()*.2 - .1}}, float32(math.Inf(1)))
+	}
+}
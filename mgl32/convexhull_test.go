@@ -0,0 +1,150 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// checkHull verifies the hull is closed, convex, and contains every input point.
+func checkHull(t *testing.T, name string, h *ConvexHull3D) {
+	// Euler's formula for a closed triangle mesh
+	if v, e, f := len(h.Vertices), len(h.Edges), len(h.Faces); v-e+f != 2 || 2*e != 3*f {
+		t.Errorf("%s: hull with %d vertices, %d edges and %d faces is not closed", name, v, e, f)
+	}
+
+	for i, p := range h.Points {
+		if !h.Contains(p, 1e-4) {
+			t.Errorf("%s: point %d %v is outside the hull", name, i, p)
+			return
+		}
+	}
+
+	// Every hull vertex is on or behind every face, so every face is a supporting plane
+	for i, f := range h.Faces {
+		n := h.FaceNormal(i)
+		for _, v := range h.Vertices {
+			if n.Dot(h.Points[v].Sub(h.Points[f[0]])) > 1e-4 {
+				t.Errorf("%s: hull is not convex at face %d", name, i)
+				return
+			}
+		}
+	}
+}
+
+func TestConvexHull3DCube(t *testing.T) {
+	var points []Vec3
+	for x := -1; x <= 1; x++ {
+		for y := -1; y <= 1; y++ {
+			for z := -1; z <= 1; z++ {
+				points = append(points, Vec3{float32(x), float32(y), float32(z)})
+			}
+		}
+	}
+
+	h, err := NewConvexHull3D(points)
+	if err != nil {
+		t.Fatalf("Hull of cube failed: %v", err)
+	}
+	checkHull(t, "cube", h)
+
+	if len(h.Vertices) != 8 {
+		t.Errorf("Hull of cube has %d vertices, expected the 8 corners", len(h.Vertices))
+	}
+	if !FloatEqualThreshold(h.Volume(), 8, 1e-5) {
+		t.Errorf("Hull of cube has volume %v, expected 8", h.Volume())
+	}
+
+	if s := h.Support(Vec3{1, 2, -3}); s != (Vec3{1, 1, -1}) {
+		t.Errorf("Support point of cube is %v, expected %v", s, Vec3{1, 1, -1})
+	}
+}
+
+func TestConvexHull3DRotatedGrid(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// After rotating, the points on the grid's faces and edges are only coplanar and collinear up to rounding
+	for i := 0; i < 10; i++ {
+		axis := Vec3{float32(rand.NormFloat64()), float32(rand.NormFloat64()), float32(rand.NormFloat64())}.Normalize()
+		rotation := QuatRotate(rand.Float32()*2*math.Pi, axis)
+		var points []Vec3
+		for x := 0; x < 6; x++ {
+			for y := 0; y < 6; y++ {
+				for z := 0; z < 6; z++ {
+					points = append(points, rotation.Rotate(Vec3{float32(x), float32(y), float32(z)}))
+				}
+			}
+		}
+
+		h, err := NewConvexHull3D(points)
+		if err != nil {
+			t.Fatalf("Hull of rotated grid failed: %v", err)
+		}
+		checkHull(t, "rotated grid", h)
+		if len(h.Vertices) != 8 {
+			t.Errorf("Hull of rotated grid has %d vertices, expected the 8 corners", len(h.Vertices))
+		}
+		if !FloatEqualThreshold(h.Volume(), 125, 1e-4) {
+			t.Errorf("Hull of rotated grid has volume %v, expected 125", h.Volume())
+		}
+	}
+}
+
+func TestConvexHull3DRandom(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// Points on a sphere are all on the hull
+	sphere := make([]Vec3, 200)
+	for i := range sphere {
+		sphere[i] = Vec3{float32(rand.NormFloat64()), float32(rand.NormFloat64()), float32(rand.NormFloat64())}.Normalize()
+	}
+	h, err := NewConvexHull3D(sphere)
+	if err != nil {
+		t.Fatalf("Hull of sphere points failed: %v", err)
+	}
+	checkHull(t, "sphere", h)
+	if len(h.Vertices) != len(sphere) {
+		t.Errorf("Hull of points on a sphere has %d vertices, expected %d", len(h.Vertices), len(sphere))
+	}
+
+	cloud := make([]Vec3, 5000)
+	for i := range cloud {
+		cloud[i] = Vec3{rand.Float32()*10 - 5, rand.Float32()*4 - 2, rand.Float32() - .5}
+	}
+	h, err = NewConvexHull3D(cloud)
+	if err != nil {
+		t.Fatalf("Hull of point cloud failed: %v", err)
+	}
+	checkHull(t, "cloud", h)
+
+	// The support point of the hull is the support point of the whole cloud
+	for i := 0; i < 20; i++ {
+		dir := Vec3{float32(rand.NormFloat64()), float32(rand.NormFloat64()), float32(rand.NormFloat64())}
+		var best float32
+		for j, p := range cloud {
+			if d := p.Dot(dir); j == 0 || d > best {
+				best = d
+			}
+		}
+		if s := h.Support(dir); !FloatEqualThreshold(s.Dot(dir), best, 1e-5) {
+			t.Errorf("Support point %v in direction %v is not the furthest point", s, dir)
+		}
+	}
+}
+
+func TestConvexHull3DDegenerate(t *testing.T) {
+	if _, err := NewConvexHull3D([]Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}); err == nil {
+		t.Errorf("Hull of three points did not fail")
+	}
+	if _, err := NewConvexHull3D([]Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {.5, .5, 0}}); err == nil {
+		t.Errorf("Hull of coplanar points did not fail")
+	}
+	if _, err := NewConvexHull3D([]Vec3{{0, 0, 0}, {1, 1, 1}, {2, 2, 2}, {3, 3, 3}}); err == nil {
+		t.Errorf("Hull of collinear points did not fail")
+	}
+}
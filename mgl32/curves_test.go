@@ -0,0 +1,135 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChoose(t *testing.T) {
+	pascal := [][]int{{1}, {1, 1}, {1, 2, 1}, {1, 3, 3, 1}, {1, 4, 6, 4, 1}, {1, 5, 10, 10, 5, 1}}
+	for n, row := range pascal {
+		for k, answer := range row {
+			if c := choose(n, k); c != answer {
+				t.Errorf("choose(%d,%d) = %d, expected %d", n, k, c, answer)
+			}
+		}
+	}
+}
+
+func TestBezierCurveMatchesCubic(t *testing.T) {
+	cPoints := []Vec2{{0, 0}, {1, 2}, {3, 2}, {4, 0}}
+	for _, tt := range []float32{0, .25, .5, .75, 1} {
+		general := BezierCurve2D(tt, cPoints)
+		cubic := CubicBezierCurve2D(tt, cPoints[0], cPoints[1], cPoints[2], cPoints[3])
+		if !general.ApproxEqualThreshold(cubic, 1e-5) {
+			t.Errorf("BezierCurve2D(%v) = %v, CubicBezierCurve2D gives %v", tt, general, cubic)
+		}
+	}
+}
+
+func TestBezierArcLengthStraightLine(t *testing.T) {
+	// Control points bunched at the start make t-spacing very uneven
+	cPoints := []Vec2{{0, 0}, {0.1, 0}, {0.2, 0}, {10, 0}}
+	table := NewBezierArcLength2D(cPoints, 256)
+
+	if !FloatEqualThreshold(table.Len(), 10, 1e-4) {
+		t.Errorf("Length of straight curve is %v, expected 10", table.Len())
+	}
+
+	line := table.MakeCurve(11)
+	for i, p := range line {
+		if !FloatEqualThreshold(p[0], float32(i), 1e-2) {
+			t.Errorf("Point %d at constant speed is %v, expected x=%d", i, p, i)
+		}
+	}
+}
+
+func TestBezierCurveLengthQuarterCircle(t *testing.T) {
+	// Standard cubic approximation of a unit quarter circle
+	k := float32(0.5522847498)
+	cPoints := []Vec3{{1, 0, 0}, {1, k, 0}, {k, 1, 0}, {0, 1, 0}}
+
+	length := BezierCurveLength3D(512, cPoints)
+	if !FloatEqualThreshold(length, math.Pi/2, 1e-3) {
+		t.Errorf("Quarter circle length is %v, expected %v", length, math.Pi/2)
+	}
+}
+
+func TestBezierSplit(t *testing.T) {
+	cPoints := []Vec2{{0, 0}, {1, 2}, {3, 2}, {4, 0}}
+	left, right := BezierSplit2D(.3, cPoints)
+
+	for _, u := range []float32{0, .5, 1} {
+		if l, o := BezierCurve2D(u, left), BezierCurve2D(.3*u, cPoints); !l.ApproxEqualThreshold(o, 1e-5) {
+			t.Errorf("Left half at %v is %v, original curve gives %v", u, l, o)
+		}
+		if r, o := BezierCurve2D(u, right), BezierCurve2D(.3+.7*u, cPoints); !r.ApproxEqualThreshold(o, 1e-5) {
+			t.Errorf("Right half at %v is %v, original curve gives %v", u, r, o)
+		}
+	}
+}
+
+func TestBezierBounds(t *testing.T) {
+	cPoints := []Vec2{{0, 0}, {1, 2}, {3, 2}, {4, 0}}
+	min, max := BezierBounds2D(cPoints)
+
+	// The peak is at t=.5, where y = 3*.125*2 + 3*.125*2 = 1.5
+	if !min.ApproxEqualThreshold(Vec2{0, 0}, 1e-5) || !max.ApproxEqualThreshold(Vec2{4, 1.5}, 1e-5) {
+		t.Errorf("Bezier bounds are %v %v, expected [0 0] [4 1.5]", min, max)
+	}
+}
+
+func TestBezierClosestPoint(t *testing.T) {
+	cPoints := []Vec2{{0, 0}, {1, 2}, {3, 2}, {4, 0}}
+
+	tt, p := BezierClosestPoint2D(Vec2{2, 5}, cPoints)
+	if !FloatEqualThreshold(tt, .5, 1e-4) || !p.ApproxEqualThreshold(Vec2{2, 1.5}, 1e-4) {
+		t.Errorf("Closest point is %v at t=%v, expected [2 1.5] at t=.5", p, tt)
+	}
+
+	for _, u := range []float32{.1, .4, .9} {
+		on := BezierCurve2D(u, cPoints)
+		if tt, _ := BezierClosestPoint2D(on, cPoints); !FloatEqualThreshold(tt, u, 1e-3) {
+			t.Errorf("Closest point to curve point at t=%v gives t=%v", u, tt)
+		}
+	}
+}
+
+func TestBezierRotationMinimizingFrames(t *testing.T) {
+	cPoints := []Vec3{{0, 0, 0}, {0, 0, 1}, {1, 1, 2}, {2, 0, 3}}
+	frames := MakeBezierRotationMinimizingFrames3D(32, cPoints, Vec3{1, 0, 0})
+
+	for i, f := range frames {
+		if !FloatEqualThreshold(f.Tangent.Len(), 1, 1e-4) || !FloatEqualThreshold(f.Normal.Len(), 1, 1e-4) || !FloatEqualThreshold(f.Binormal.Len(), 1, 1e-4) {
+			t.Errorf("Frame %d is not normalized: %v", i, f)
+		}
+		if Abs(f.Tangent.Dot(f.Normal)) > 1e-4 || Abs(f.Tangent.Dot(f.Binormal)) > 1e-4 || Abs(f.Normal.Dot(f.Binormal)) > 1e-4 {
+			t.Errorf("Frame %d is not orthogonal: %v", i, f)
+		}
+		if i > 0 && f.Normal.Dot(frames[i-1].Normal) < .9 {
+			t.Errorf("Frame %d twists too far from the previous frame", i)
+		}
+	}
+
+	if !frames[0].Normal.ApproxEqualThreshold(Vec3{1, 0, 0}, 1e-5) {
+		t.Errorf("First frame normal is %v, expected [1 0 0]", frames[0].Normal)
+	}
+}
+
+func TestBezierFrenetFrame(t *testing.T) {
+	k := float32(0.5522847498)
+	cPoints := []Vec3{{1, 0, 0}, {1, k, 0}, {k, 1, 0}, {0, 1, 0}}
+	f := BezierFrenetFrame3D(.5, cPoints)
+
+	// On a circle around the origin, the normal points back towards the origin
+	if !f.Normal.ApproxEqualThreshold(f.Position.Normalize().Mul(-1), 1e-3) {
+		t.Errorf("Frenet normal is %v, expected to point towards the center from %v", f.Normal, f.Position)
+	}
+	if !f.Binormal.ApproxEqualThreshold(Vec3{0, 0, 1}, 1e-4) {
+		t.Errorf("Frenet binormal is %v, expected [0 0 1]", f.Binormal)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// checkDelaunay verifies the half-edge structure is consistent, every triangle is counter-clockwise,
+// the triangles exactly cover the convex hull, and (unless constrained) no point is inside any circumcircle.
+func checkDelaunay(t *testing.T, d *Delaunay, checkCircles bool) {
+	if len(d.Triangles) != len(d.Halfedges) {
+		t.Fatalf("Triangulation has %d vertex entries but %d half-edges", len(d.Triangles), len(d.Halfedges))
+	}
+
+	var area float64
+	for e, opp := range d.Halfedges {
+		if opp != -1 {
+			if d.Halfedges[opp] != int32(e) {
+				t.Fatalf("Half-edge %d's opposite %d does not point back", e, opp)
+			}
+			if d.Triangles[e] != d.Triangles[NextHalfedge(int(opp))] || d.Triangles[opp] != d.Triangles[NextHalfedge(e)] {
+				t.Fatalf("Half-edge %d and its opposite %d do not share end points", e, opp)
+			}
+		}
+
+		if e%3 == 0 {
+			o := d.orient(int(d.Triangles[e]), int(d.Triangles[e+1]), int(d.Triangles[e+2]))
+			if o <= 0 {
+				t.Fatalf("Triangle %d is not counter-clockwise", e/3)
+			}
+			area += o / 2
+		}
+	}
+
+	hull := make([]Vec2, len(d.Hull))
+	for i, p := range d.Hull {
+		hull[i] = d.Points[p]
+	}
+	if hullArea := float64(PolygonSignedArea(hull)); hullArea <= 0 || (area-hullArea)/hullArea > 1e-5 || (hullArea-area)/hullArea > 1e-5 {
+		t.Errorf("Triangles cover an area of %v, but the hull has an area of %v", area, hullArea)
+	}
+
+	if !checkCircles {
+		return
+	}
+	for e := 0; e < len(d.Triangles); e += 3 {
+		for p := range d.Points {
+			if d.inedges[p] != -1 && !d.constrained[e] && d.inCircle(int(d.Triangles[e]), int(d.Triangles[e+1]), int(d.Triangles[e+2]), p) {
+				t.Fatalf("Point %d is inside the circumcircle of triangle %d", p, e/3)
+			}
+		}
+	}
+}
+
+func TestDelaunaySquare(t *testing.T) {
+	d, err := NewDelaunay([]Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {.5, .5}})
+	if err != nil {
+		t.Fatalf("Triangulating square failed: %v", err)
+	}
+	checkDelaunay(t, d, true)
+
+	if d.NumTriangles() != 4 || len(d.Hull) != 4 {
+		t.Errorf("Square with center has %d triangles and %d hull points, expected 4 and 4", d.NumTriangles(), len(d.Hull))
+	}
+	if neighbors := d.Neighbors(4); len(neighbors) != 4 {
+		t.Errorf("Center point has neighbors %v, expected all four corners", neighbors)
+	}
+	if neighbors := d.Neighbors(0); len(neighbors) != 3 {
+		t.Errorf("Corner point has neighbors %v, expected the center and two adjacent corners", neighbors)
+	}
+}
+
+func TestDelaunayRandom(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := make([]Vec2, 500)
+	for i := range points {
+		points[i] = Vec2{rand.Float32()*100 - 50, rand.Float32()*100 - 50}
+	}
+	// Duplicates must be skipped
+	points = append(points, points[0], points[1])
+
+	d, err := NewDelaunay(points)
+	if err != nil {
+		t.Fatalf("Triangulating random points failed: %v", err)
+	}
+	checkDelaunay(t, d, true)
+
+	// Euler's formula for a triangulation of n points with h on the hull
+	if expected := 2*(len(points)-2) - len(d.Hull) - 2; d.NumTriangles() != expected {
+		t.Errorf("Triangulation has %d triangles, expected %d", d.NumTriangles(), expected)
+	}
+	if hull := ConvexHull2D(points); len(hull) != len(d.Hull) {
+		t.Errorf("Triangulation hull has %d points, ConvexHull2D has %d", len(d.Hull), len(hull))
+	}
+}
+
+func TestDelaunayGrid(t *testing.T) {
+	// Grids are full of cocircular points, which stress the degenerate cases
+	var points []Vec2
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			points = append(points, Vec2{float32(x), float32(y)})
+		}
+	}
+
+	d, err := NewDelaunay(points)
+	if err != nil {
+		t.Fatalf("Triangulating grid failed: %v", err)
+	}
+	checkDelaunay(t, d, true)
+
+	if d.NumTriangles() != 2*19*19 {
+		t.Errorf("Grid has %d triangles, expected %d", d.NumTriangles(), 2*19*19)
+	}
+}
+
+func TestDelaunayDegenerate(t *testing.T) {
+	if _, err := NewDelaunay([]Vec2{{0, 0}, {1, 1}}); err == nil {
+		t.Errorf("Triangulating two points did not fail")
+	}
+	if _, err := NewDelaunay([]Vec2{{0, 0}, {1, 1}, {2, 2}, {3, 3}}); err == nil {
+		t.Errorf("Triangulating collinear points did not fail")
+	}
+}
+
+func TestDelaunayConstrainEdge(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := []Vec2{{0, 0}, {100, 0}, {100, 10}, {0, 10}}
+	for i := 0; i < 200; i++ {
+		points = append(points, Vec2{rand.Float32() * 100, rand.Float32() * 10})
+	}
+
+	d, err := NewDelaunay(points)
+	if err != nil {
+		t.Fatalf("Triangulating points failed: %v", err)
+	}
+
+	// The long diagonal crosses many triangles
+	if err := d.ConstrainEdge(0, 2); err != nil {
+		t.Fatalf("Constraining diagonal failed: %v", err)
+	}
+	checkDelaunay(t, d, false)
+
+	e := d.findEdge(0, 2)
+	if e == -1 {
+		t.Fatalf("Constrained edge is not in the triangulation")
+	}
+	if !d.IsConstrained(e) || !d.IsConstrained(int(d.Halfedges[e])) {
+		t.Errorf("Constrained edge is not marked as constrained")
+	}
+
+	if err := d.ConstrainEdge(1, 3); err == nil {
+		t.Errorf("Constraining an edge crossing another constrained edge did not fail")
+	}
+
+	// Through a point: the edge is split in two
+	d, _ = NewDelaunay([]Vec2{{0, 0}, {2, 0}, {4, 0}, {2, 3}, {2, -3}, {1, 1}, {3, -1}})
+	if err := d.ConstrainEdge(3, 4); err != nil {
+		t.Fatalf("Constraining edge through a point failed: %v", err)
+	}
+	checkDelaunay(t, d, false)
+	if d.findEdge(3, 1) == -1 || d.findEdge(1, 4) == -1 {
+		t.Errorf("Constrained edge through a point was not split at the point")
+	}
+}
+
+func TestVoronoi(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := make([]Vec2, 300)
+	for i := range points {
+		points[i] = Vec2{rand.Float32() * 10, rand.Float32() * 10}
+	}
+
+	d, err := NewDelaunay(points)
+	if err != nil {
+		t.Fatalf("Triangulating random points failed: %v", err)
+	}
+
+	min, max := Vec2{0, 0}, Vec2{10, 10}
+	cells := d.Voronoi(min, max)
+
+	var total float32
+	for i, cell := range cells {
+		if !PolygonIsConvex(cell) || !PolygonIsCCW(cell) {
+			t.Errorf("Cell %d is not a convex counter-clockwise polygon: %v", i, cell)
+		}
+		if !PointInPolygon(points[i], cell) {
+			t.Errorf("Cell %d does not contain its point %v", i, points[i])
+		}
+		total += PolygonArea(cell)
+	}
+
+	if !FloatEqualThreshold(total, 100, 1e-3) {
+		t.Errorf("Voronoi cells cover an area of %v, expected 100", total)
+	}
+}
+
+func TestVoronoiConstrained(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := make([]Vec2, 40)
+	for i := range points {
+		points[i] = Vec2{rand.Float32() * 3, rand.Float32() * 3}
+	}
+	// A long edge across the middle, which crosses many Delaunay edges
+	points[0], points[1] = Vec2{.01, 1.5}, Vec2{2.99, 1.51}
+
+	d, err := NewDelaunay(points)
+	if err != nil {
+		t.Fatalf("Triangulating random points failed: %v", err)
+	}
+	min, max := Vec2{0, 0}, Vec2{3, 3}
+	expected := d.Voronoi(min, max)
+
+	if err := d.ConstrainEdge(0, 1); err != nil {
+		t.Fatalf("Constraining an edge failed: %v", err)
+	}
+	cells := d.Voronoi(min, max)
+	if !reflect.DeepEqual(cells, expected) {
+		t.Errorf("Voronoi cells change when an edge is constrained")
+	}
+
+	var total float32
+	for _, cell := range cells {
+		total += PolygonArea(cell)
+	}
+	if !FloatEqualThreshold(total, 9, 1e-3) {
+		t.Errorf("Voronoi cells cover an area of %v, expected 9", total)
+	}
+}
+
+func TestDelaunayLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping large triangulation in short mode")
+	}
+
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := make([]Vec2, 100000)
+	for i := range points {
+		points[i] = Vec2{rand.Float32() * 1000, rand.Float32() * 1000}
+	}
+
+	d, err := NewDelaunay(points)
+	if err != nil {
+		t.Fatalf("Triangulating 100k points failed: %v", err)
+	}
+	checkDelaunay(t, d, false)
+}
+
+func BenchmarkDelaunay(b *testing.B) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := make([]Vec2, 100000)
+	for i := range points {
+		points[i] = Vec2{rand.Float32() * 1000, rand.Float32() * 1000}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewDelaunay(points)
+	}
+}
@@ -0,0 +1,427 @@
+// Copyright 2014 The go-gl/mathgl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file is generated by genprog; change genprog/encoding.go instead.
+
+package mgl32
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (v Vec2) MarshalText() ([]byte, error) {
+	return marshalText(v[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *Vec2) UnmarshalText(text []byte) error {
+	return unmarshalText(text, v[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Vec2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Vec2) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (v Vec2) MarshalBinary() ([]byte, error) {
+	return marshalBinary(v[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (v *Vec2) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, v[:])
+}
+
+// Format implements fmt.Formatter.
+func (v Vec2) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, v, v[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v Vec3) MarshalText() ([]byte, error) {
+	return marshalText(v[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *Vec3) UnmarshalText(text []byte) error {
+	return unmarshalText(text, v[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Vec3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Vec3) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (v Vec3) MarshalBinary() ([]byte, error) {
+	return marshalBinary(v[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (v *Vec3) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, v[:])
+}
+
+// Format implements fmt.Formatter.
+func (v Vec3) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, v, v[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v Vec4) MarshalText() ([]byte, error) {
+	return marshalText(v[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *Vec4) UnmarshalText(text []byte) error {
+	return unmarshalText(text, v[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Vec4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Vec4) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (v Vec4) MarshalBinary() ([]byte, error) {
+	return marshalBinary(v[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (v *Vec4) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, v[:])
+}
+
+// Format implements fmt.Formatter.
+func (v Vec4) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, v, v[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat2) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat2) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat2) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat2) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat2) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat2) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat2x3) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat2x3) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat2x3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat2x3) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat2x3) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat2x3) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat2x3) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat2x4) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat2x4) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat2x4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat2x4) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat2x4) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat2x4) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat2x4) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat3x2) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat3x2) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat3x2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat3x2) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat3x2) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat3x2) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat3x2) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat3) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat3) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat3) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat3) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat3) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat3) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat3x4) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat3x4) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat3x4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat3x4) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat3x4) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat3x4) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat3x4) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat4x2) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat4x2) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat4x2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat4x2) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat4x2) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat4x2) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat4x2) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat4x3) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat4x3) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat4x3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat4x3) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat4x3) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat4x3) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
+
+// Format implements fmt.Formatter.
+func (m Mat4x3) Format(f fmt.State, verb rune) {
+	formatElements(f, verb, m, m[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m Mat4) MarshalText() ([]byte, error) {
+	return marshalText(m[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mat4) UnmarshalText(text []byte) error {
+	return unmarshalText(text, m[:])
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Mat4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Mat4) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m Mat4) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m[:])
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Mat4) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m[:])
+}
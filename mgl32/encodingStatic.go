@@ -0,0 +1,232 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Every vector, matrix and quaternion type implements encoding.TextMarshaler, json.Marshaler and
+// encoding.BinaryMarshaler, their Unmarshaler counterparts, and fmt.Formatter.
+//
+// The text form is the elements separated by spaces, as in "1 2 3", with the shortest decimal that parses back to
+// the same float32 (float64 in mgl64). The JSON form is an array of the elements, as encoding/json writes an array by default, and
+// {"W":1,"V":[0,0,0]} for a Quat. The binary form is the elements as little endian IEEE 754 numbers, so a Vec3 is 12
+// bytes. In all three, matrices are column major like their memory layout, and a Quat is W followed by V.
+//
+// Formatting with a floating point verb applies its flags, width and precision to each element, so "%.2f" prints a
+// Vec3 as [1.00 2.00 3.00]. For compatibility with its String method, a Mat4 prints as a table with %v and %s, unless
+// %v has a flag, width or precision.
+//
+// genprog generates the methods of the vector and matrix types into encoding.go from genprog/encoding.go; the helpers
+// they share, and the methods of Quat and Mat4's Format, are here.
+
+// floatBits is the size of the element type, which is 64 in mgl64.
+const floatBits = int(8 * unsafe.Sizeof(float32(0)))
+
+func marshalText(elements []float32) ([]byte, error) {
+	var text []byte
+	for i, e := range elements {
+		if i > 0 {
+			text = append(text, ' ')
+		}
+		text = strconv.AppendFloat(text, float64(e), 'g', -1, floatBits)
+	}
+
+	return text, nil
+}
+
+func unmarshalText(text []byte, elements []float32) error {
+	fields := strings.Fields(string(text))
+	if len(fields) != len(elements) {
+		return fmt.Errorf("text has %d elements, expected %d", len(fields), len(elements))
+	}
+
+	// Parse everything before changing elements, so they're unchanged after an error
+	parsed := make([]float32, len(fields))
+	for i, field := range fields {
+		e, err := strconv.ParseFloat(field, floatBits)
+		if err != nil {
+			return err
+		}
+		parsed[i] = float32(e)
+	}
+	copy(elements, parsed)
+
+	return nil
+}
+
+func unmarshalJSON(data []byte, elements []float32) error {
+	if string(data) == "null" {
+		// Like encoding/json, leave the value unchanged
+		return nil
+	}
+
+	var decoded []float32
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if len(decoded) != len(elements) {
+		return fmt.Errorf("JSON array has %d elements, expected %d", len(decoded), len(elements))
+	}
+	copy(elements, decoded)
+
+	return nil
+}
+
+func marshalBinary(elements []float32) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, binary.Size(elements)))
+	err := binary.Write(buf, binary.LittleEndian, elements)
+	return buf.Bytes(), err
+}
+
+func unmarshalBinary(data []byte, elements []float32) error {
+	if size := binary.Size(elements); len(data) != size {
+		return fmt.Errorf("binary data is %d bytes, expected %d", len(data), size)
+	}
+
+	return binary.Read(bytes.NewReader(data), binary.LittleEndian, elements)
+}
+
+// elementFormat rebuilds the format of the verb being handled, to format each element with it. It is false if the
+// verb doesn't apply to floats.
+func elementFormat(f fmt.State, verb rune) (string, bool) {
+	switch verb {
+	case 's':
+		verb = 'v'
+	case 'v', 'b', 'e', 'E', 'f', 'F', 'g', 'G', 'x', 'X':
+	default:
+		return "", false
+	}
+
+	format := []byte{'%'}
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			format = append(format, byte(flag))
+		}
+	}
+	if width, ok := f.Width(); ok {
+		format = strconv.AppendInt(format, int64(width), 10)
+	}
+	if precision, ok := f.Precision(); ok {
+		format = append(format, '.')
+		format = strconv.AppendInt(format, int64(precision), 10)
+	}
+
+	return string(append(format, byte(verb))), true
+}
+
+// formatElements writes elements in the same way fmt writes an array of them, but with the verb's flags, width and
+// precision applied to each one. value is only used for its type, in Go syntax and errors.
+func formatElements(f fmt.State, verb rune, value interface{}, elements []float32) {
+	format, ok := elementFormat(f, verb)
+	if !ok {
+		fmt.Fprintf(f, "%%!%c(%T=%v)", verb, value, elements)
+		return
+	}
+
+	open, separator, close := "[", " ", "]"
+	if verb == 'v' && f.Flag('#') {
+		open, separator, close = fmt.Sprintf("%T{", value), ", ", "}"
+	}
+
+	fmt.Fprint(f, open)
+	for i, e := range elements {
+		if i > 0 {
+			fmt.Fprint(f, separator)
+		}
+		fmt.Fprintf(f, format, e)
+	}
+	fmt.Fprint(f, close)
+}
+
+// elements returns q's W followed by the elements of V.
+func (q Quat) elements() []float32 {
+	return []float32{q.W, q.V[0], q.V[1], q.V[2]}
+}
+
+// setElements sets q's W and V from elements in the order of Quat.elements.
+func (q *Quat) setElements(elements [4]float32) {
+	*q = Quat{elements[0], Vec3{elements[1], elements[2], elements[3]}}
+}
+
+// plainQuat has the fields of Quat, but none of its methods, so encoding/json encodes it as a struct.
+type plainQuat Quat
+
+// MarshalText implements encoding.TextMarshaler.
+func (q Quat) MarshalText() ([]byte, error) {
+	return marshalText(q.elements())
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (q *Quat) UnmarshalText(text []byte) error {
+	var elements [4]float32
+	if err := unmarshalText(text, elements[:]); err != nil {
+		return err
+	}
+	q.setElements(elements)
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (q Quat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(plainQuat(q))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (q *Quat) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*plainQuat)(q))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (q Quat) MarshalBinary() ([]byte, error) {
+	return marshalBinary(q.elements())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (q *Quat) UnmarshalBinary(data []byte) error {
+	var elements [4]float32
+	if err := unmarshalBinary(data, elements[:]); err != nil {
+		return err
+	}
+	q.setElements(elements)
+
+	return nil
+}
+
+// Format implements fmt.Formatter. The elements are written like the fields of a struct, as in {1 [0 0 0]}.
+func (q Quat) Format(f fmt.State, verb rune) {
+	format, ok := elementFormat(f, verb)
+	switch {
+	case !ok:
+		fmt.Fprintf(f, "%%!%c(%T=%v)", verb, q, q.elements())
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, "%T{W:%#v, V:%#v}", q, q.W, q.V)
+	default:
+		fmt.Fprintf(f, "{"+format+" ", q.W)
+		q.V.Format(f, verb)
+		fmt.Fprint(f, "}")
+	}
+}
+
+// Format implements fmt.Formatter.
+func (m Mat4) Format(f fmt.State, verb rune) {
+	_, hasWidth := f.Width()
+	_, hasPrecision := f.Precision()
+	plain := !hasWidth && !hasPrecision && !f.Flag('+') && !f.Flag('-') && !f.Flag('#') && !f.Flag(' ') && !f.Flag('0')
+	if verb == 's' || verb == 'v' && plain {
+		fmt.Fprint(f, m.String())
+		return
+	}
+
+	formatElements(f, verb, m, m[:])
+}
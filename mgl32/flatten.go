@@ -0,0 +1,102 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+)
+
+// maxFlattenDepth bounds the recursion when subdividing curves, so degenerate input (NaNs, or a tolerance of 0)
+// still terminates. 2^16 segments is far more than any reasonable tolerance needs.
+const maxFlattenDepth = 16
+
+// FlattenQuadraticBezierCurve2D approximates the quadratic Bezier curve with a polyline that never
+// strays more than tolerance from the curve. Unlike MakeBezierCurve2D, which samples a fixed number of
+// points, the curve is adaptively subdivided so flat stretches use few points and tight bends use more.
+//
+// The tolerance is in the same units as the control points; for rendering, transform the control points to
+// screen space first and use a tolerance of a fraction of a pixel. The returned polyline always starts
+// at cPoint1 and ends at cPoint3.
+func FlattenQuadraticBezierCurve2D(cPoint1, cPoint2, cPoint3 Vec2, tolerance float32) []Vec2 {
+	line := []Vec2{cPoint1}
+	line = flattenQuadratic(line, cPoint1, cPoint2, cPoint3, 0, 1, tolerance, 0)
+
+	return line
+}
+
+func flattenQuadratic(line []Vec2, cPoint1, cPoint2, cPoint3 Vec2, t0, t1, tolerance float32, depth int) []Vec2 {
+	start, end := QuadraticBezierCurve2D(t0, cPoint1, cPoint2, cPoint3), QuadraticBezierCurve2D(t1, cPoint1, cPoint2, cPoint3)
+
+	// The control point of the sub-curve over [t0,t1] is where the end tangents meet.
+	// Compared point-for-point with the chord, a quadratic is off by 2t(1-t) times the control point's
+	// offset from the chord's midpoint, which is at most half that offset.
+	h := t1 - t0
+	control := start.Add(BezierDerivative2D(t0, []Vec2{cPoint1, cPoint2, cPoint3}).Mul(h / 2))
+	if depth >= maxFlattenDepth || control.Sub(start.Add(end).Mul(.5)).Len()/2 <= tolerance {
+		return append(line, end)
+	}
+
+	mid := (t0 + t1) / 2
+	line = flattenQuadratic(line, cPoint1, cPoint2, cPoint3, t0, mid, tolerance, depth+1)
+	return flattenQuadratic(line, cPoint1, cPoint2, cPoint3, mid, t1, tolerance, depth+1)
+}
+
+// FlattenCubicBezierCurve2D approximates the cubic Bezier curve with a polyline that never strays more
+// than tolerance from the curve. See FlattenQuadraticBezierCurve2D for details.
+func FlattenCubicBezierCurve2D(cPoint1, cPoint2, cPoint3, cPoint4 Vec2, tolerance float32) []Vec2 {
+	line := []Vec2{cPoint1}
+	line = flattenCubic(line, cPoint1, cPoint2, cPoint3, cPoint4, 0, 1, tolerance, 0)
+
+	return line
+}
+
+func flattenCubic(line []Vec2, cPoint1, cPoint2, cPoint3, cPoint4 Vec2, t0, t1, tolerance float32, depth int) []Vec2 {
+	start, end := CubicBezierCurve2D(t0, cPoint1, cPoint2, cPoint3, cPoint4), CubicBezierCurve2D(t1, cPoint1, cPoint2, cPoint3, cPoint4)
+
+	// The inner control points of the sub-curve over [t0,t1] lie a third of the way along the end tangents.
+	// Compared point-for-point with the chord, a cubic is off by 3t(1-t) times a blend of the inner control points'
+	// offsets from the chord's thirds, which is at most 3/4 of the larger offset.
+	cPoints := []Vec2{cPoint1, cPoint2, cPoint3, cPoint4}
+	h := t1 - t0
+	control1 := start.Add(BezierDerivative2D(t0, cPoints).Mul(h / 3))
+	control2 := end.Sub(BezierDerivative2D(t1, cPoints).Mul(h / 3))
+
+	dist := control1.Sub(start.Mul(2.0 / 3).Add(end.Mul(1.0 / 3))).Len()
+	dist2 := control2.Sub(start.Mul(1.0 / 3).Add(end.Mul(2.0 / 3))).Len()
+	SetMax(&dist, &dist2)
+	if depth >= maxFlattenDepth || dist*3/4 <= tolerance {
+		return append(line, end)
+	}
+
+	mid := (t0 + t1) / 2
+	line = flattenCubic(line, cPoint1, cPoint2, cPoint3, cPoint4, t0, mid, tolerance, depth+1)
+	return flattenCubic(line, cPoint1, cPoint2, cPoint3, cPoint4, mid, t1, tolerance, depth+1)
+}
+
+// FlattenArc2D approximates a circular arc with a polyline that never strays more than tolerance from it.
+// The arc is centered at center, and sweeps from startAngle to endAngle (in radians, counter-clockwise
+// if endAngle > startAngle). Since the curvature of a circle is constant, this uses the fewest
+// evenly spaced segments that stay within the tolerance.
+func FlattenArc2D(center Vec2, radius, startAngle, endAngle, tolerance float32) []Vec2 {
+	sweep := float64(endAngle - startAngle)
+
+	// A chord spanning angle a deviates from the arc by r*(1-cos(a/2)). Chords spanning more than
+	// half a circle are never used, no matter how loose the tolerance.
+	numSegments := 1 << maxFlattenDepth
+	if maxAngle := 2 * math.Acos(1-math.Min(float64(tolerance/radius), 1)); maxAngle > 0 {
+		numSegments = int(math.Ceil(math.Abs(sweep) / maxAngle))
+	}
+	if numSegments < 1 {
+		numSegments = 1
+	}
+
+	line := make([]Vec2, numSegments+1)
+	for i := range line {
+		sin, cos := math.Sincos(float64(startAngle) + sweep*float64(i)/float64(numSegments))
+		line[i] = Vec2{center[0] + radius*float32(cos), center[1] + radius*float32(sin)}
+	}
+
+	return line
+}
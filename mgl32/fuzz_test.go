@@ -0,0 +1,129 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package mgl32
+
+import (
+	"math"
+	"testing"
+)
+
+// The fuzz targets run their seed corpus with go test like any other test. To search for failing inputs, run for
+// example
+//
+//	go test -fuzz=FuzzInv
+
+// finite is true if none of xs is NaN or infinite, and none is so large that products of a few of them overflow.
+func finite(xs ...float32) bool {
+	for _, x := range xs {
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) || Abs(x) > 1e6 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// maxAbs returns the largest magnitude in m.
+func maxAbs(m []float32) float32 {
+	var max float32
+	for _, x := range m {
+		x = Abs(x)
+		SetMax(&max, &x)
+	}
+
+	return max
+}
+
+func FuzzFloatEqual(f *testing.F) {
+	f.Add(float32(0), float32(0))
+	f.Add(float32(1), float32(1+1e-7))
+	f.Add(float32(-1), float32(1))
+	f.Add(float32(1e-30), float32(-1e-30))
+	f.Add(float32(math.MaxFloat32), float32(-math.MaxFloat32))
+	f.Add(float32(math.Inf(1)), float32(math.Inf(1)))
+
+	f.Fuzz(func(t *testing.T, a, b float32) {
+		if FloatEqual(a, b) != FloatEqual(b, a) {
+			t.Errorf("FloatEqual(%v, %v) is %v, but FloatEqual(%v, %v) isn't", a, b, FloatEqual(a, b), b, a)
+		}
+		if !math.IsNaN(float64(a)) && !FloatEqual(a, a) {
+			t.Errorf("%v isn't equal to itself", a)
+		}
+		// Relative to their size, numbers of opposite signs are as far apart as can be
+		if a*b < 0 && FloatEqual(a, b) {
+			t.Errorf("%v and %v have opposite signs, but are equal", a, b)
+		}
+		if FloatEqual(a, b) && !FloatEqualThreshold(a, b, Epsilon) {
+			t.Errorf("FloatEqual and FloatEqualThreshold with Epsilon disagree about %v and %v", a, b)
+		}
+	})
+}
+
+func FuzzInv(f *testing.F) {
+	f.Add(float32(1), float32(0), float32(0), float32(0), float32(1), float32(0), float32(0), float32(0), float32(1), float32(0), float32(0), float32(0))
+	f.Add(float32(2), float32(1), float32(0), float32(-1), float32(3), float32(1), float32(0), float32(1), float32(4), float32(5), float32(-2), float32(1))
+	f.Add(float32(1), float32(2), float32(3), float32(2), float32(4), float32(6), float32(1), float32(0), float32(1), float32(0), float32(0), float32(0))
+	f.Add(float32(1e-5), float32(0), float32(0), float32(0), float32(1e5), float32(0), float32(0), float32(0), float32(1), float32(1), float32(1), float32(1))
+
+	f.Fuzz(func(t *testing.T, m0, m1, m2, m3, m4, m5, m6, m7, m8, x, y, z float32) {
+		if !finite(m0, m1, m2, m3, m4, m5, m6, m7, m8, x, y, z) {
+			return
+		}
+
+		m3x3 := Mat3{m0, m1, m2, m3, m4, m5, m6, m7, m8}
+		m4x4 := Translate3D(x, y, z).Mul4(m3x3.Mat4())
+
+		ident3, ident4 := Ident3(), Ident4()
+		checks := []struct {
+			m, inv, product, ident []float32
+		}{
+			{m3x3[:], sliceOf3(m3x3.Inv()), sliceOf3(m3x3.Mul3(m3x3.Inv())), ident3[:]},
+			{m4x4[:], sliceOf4(m4x4.Inv()), sliceOf4(m4x4.Mul4(m4x4.Inv())), ident4[:]},
+		}
+		for _, c := range checks {
+			if maxAbs(c.inv) == 0 {
+				// Singular, or too close to tell
+				continue
+			}
+
+			// The error of the inverse grows with the condition number of the matrix
+			condition := maxAbs(c.m) * maxAbs(c.inv) * float32(len(c.ident))
+			if condition > 1e4 {
+				continue
+			}
+			if !elementsClose(c.product, c.ident, 1e-5*condition) {
+				t.Errorf("%v times its inverse %v is %v, expected identity", c.m, c.inv, c.product)
+			}
+		}
+	})
+}
+
+func sliceOf3(m Mat3) []float32 { return m[:] }
+func sliceOf4(m Mat4) []float32 { return m[:] }
+
+func FuzzAnglesToQuat(f *testing.F) {
+	f.Add(float32(0), float32(0), float32(0), uint8(ZYX))
+	f.Add(float32(.7854), float32(.1), float32(0), uint8(ZYX))
+	f.Add(float32(math.Pi), float32(math.Pi/2), float32(-math.Pi), uint8(XYX))
+	f.Add(float32(1), float32(2), float32(3), uint8(ZXY))
+
+	f.Fuzz(func(t *testing.T, angle1, angle2, angle3 float32, order uint8) {
+		if !finite(angle1, angle2, angle3) || Abs(angle1) > 1e3 || Abs(angle2) > 1e3 || Abs(angle3) > 1e3 {
+			return
+		}
+		angles, o := Vec3{angle1, angle2, angle3}, RotationOrder(order%12)
+
+		q := AnglesToQuat(angle1, angle2, angle3, o)
+		if !FloatEqualThreshold(q.Len(), 1, 1e-5) {
+			t.Errorf("Angles %v in order %v give %v, which isn't normalized", angles, o, q)
+		}
+		if expected := composeAngles(angles, o); !sameRotation(q, expected, 1e-5) {
+			t.Errorf("Angles %v in order %v give %v, expected %v", angles, o, q, expected)
+		}
+	})
+}
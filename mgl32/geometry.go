@@ -0,0 +1,269 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+)
+
+// AABB is an axis-aligned bounding box. A box with any component of Min greater than Max is empty;
+// EmptyAABB returns one that can be grown with Extend and Union.
+type AABB struct {
+	Min, Max Vec3
+}
+
+// EmptyAABB returns a box containing nothing, which becomes the bounds of whatever it's extended by.
+func EmptyAABB() AABB {
+	inf := float32(math.Inf(1))
+	return AABB{Vec3{inf, inf, inf}, Vec3{-inf, -inf, -inf}}
+}
+
+// AABBFromPoints returns the smallest box containing all of points. If points is empty, the box is empty.
+func AABBFromPoints(points []Vec3) AABB {
+	b := EmptyAABB()
+	for _, p := range points {
+		b = b.Extend(p)
+	}
+
+	return b
+}
+
+// IsEmpty returns whether the box contains no points at all.
+func (b AABB) IsEmpty() bool {
+	return b.Min[0] > b.Max[0] || b.Min[1] > b.Max[1] || b.Min[2] > b.Max[2]
+}
+
+// Extend returns the smallest box containing both b and p.
+func (b AABB) Extend(p Vec3) AABB {
+	for i := range p {
+		SetMin(&b.Min[i], &p[i])
+		SetMax(&b.Max[i], &p[i])
+	}
+
+	return b
+}
+
+// Union returns the smallest box containing both b and other.
+func (b AABB) Union(other AABB) AABB {
+	for i := 0; i < 3; i++ {
+		SetMin(&b.Min[i], &other.Min[i])
+		SetMax(&b.Max[i], &other.Max[i])
+	}
+
+	return b
+}
+
+// Center returns the point in the middle of the box.
+func (b AABB) Center() Vec3 {
+	return b.Min.Add(b.Max).Mul(.5)
+}
+
+// Size returns the extent of the box along each axis.
+func (b AABB) Size() Vec3 {
+	return b.Max.Sub(b.Min)
+}
+
+// SurfaceArea returns the total area of the box's six sides, or 0 if the box is empty.
+func (b AABB) SurfaceArea() float32 {
+	if b.IsEmpty() {
+		return 0
+	}
+
+	s := b.Size()
+	return 2 * (s[0]*s[1] + s[1]*s[2] + s[2]*s[0])
+}
+
+// Contains returns whether p is inside the box or on its surface.
+func (b AABB) Contains(p Vec3) bool {
+	return p[0] >= b.Min[0] && p[0] <= b.Max[0] && p[1] >= b.Min[1] && p[1] <= b.Max[1] && p[2] >= b.Min[2] && p[2] <= b.Max[2]
+}
+
+// Intersects returns whether the boxes overlap or touch.
+func (b AABB) Intersects(other AABB) bool {
+	return b.Min[0] <= other.Max[0] && b.Max[0] >= other.Min[0] &&
+		b.Min[1] <= other.Max[1] && b.Max[1] >= other.Min[1] &&
+		b.Min[2] <= other.Max[2] && b.Max[2] >= other.Min[2]
+}
+
+// ClosestPoint returns the point of the box closest to p, which is p itself if it's inside.
+func (b AABB) ClosestPoint(p Vec3) Vec3 {
+	for i := range p {
+		p[i] = Clamp(p[i], b.Min[i], b.Max[i])
+	}
+
+	return p
+}
+
+// IntersectsSphere returns whether the box overlaps or touches the sphere.
+func (b AABB) IntersectsSphere(center Vec3, radius float32) bool {
+	d := b.ClosestPoint(center).Sub(center)
+	return d.Dot(d) <= radius*radius
+}
+
+// IntersectRay returns the distance along the ray (in multiples of its direction) at which it enters the box,
+// using the slab method. A ray starting inside the box hits it at t = 0. Only hits with t in [0, maxT] are returned.
+func (b AABB) IntersectRay(r Ray, maxT float32) (t float32, ok bool) {
+	tMin, tMax := float32(0), maxT
+	for i := 0; i < 3; i++ {
+		inv := 1 / r.Direction[i]
+		t0, t1 := (b.Min[i]-r.Origin[i])*inv, (b.Max[i]-r.Origin[i])*inv
+		if inv < 0 {
+			t0, t1 = t1, t0
+		}
+
+		// NaNs (from 0 * Inf, a ray in the plane of a slab) are ignored by these comparisons
+		if t0 > tMin {
+			tMin = t0
+		}
+		if t1 < tMax {
+			tMax = t1
+		}
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+
+	return tMin, true
+}
+
+// Transform returns the bounds of the box after transforming it by the affine matrix m, which are usually larger than
+// the box itself since the transformed box is no longer axis-aligned. This is Arvo's method, which is faster than
+// transforming the eight corners.
+func (b AABB) Transform(m Mat4) AABB {
+	if b.IsEmpty() {
+		return b
+	}
+
+	translation := m.Col(3).Vec3()
+	result := AABB{translation, translation}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			e, f := m.At(i, j)*b.Min[j], m.At(i, j)*b.Max[j]
+			if e > f {
+				e, f = f, e
+			}
+			result.Min[i] += e
+			result.Max[i] += f
+		}
+	}
+
+	return result
+}
+
+// Support returns the corner of the box furthest in direction, which makes AABB a SupportMapper.
+func (b AABB) Support(direction Vec3) Vec3 {
+	return BoxShape{b.Min, b.Max}.Support(direction)
+}
+
+// Ray is a half-line starting at Origin. Direction doesn't need to be normalized; distances along the ray
+// are measured in multiples of it.
+type Ray struct {
+	Origin, Direction Vec3
+}
+
+// At returns the point at distance t along the ray.
+func (r Ray) At(t float32) Vec3 {
+	return r.Origin.Add(r.Direction.Mul(t))
+}
+
+// Triangle is a triangle in 3D space. Its front face is the one its vertices appear counter-clockwise from.
+type Triangle [3]Vec3
+
+// Normal returns the unit normal of the front face of the triangle.
+func (tri Triangle) Normal() Vec3 {
+	return tri[1].Sub(tri[0]).Cross(tri[2].Sub(tri[0])).Normalize()
+}
+
+// Area returns the area of the triangle.
+func (tri Triangle) Area() float32 {
+	return tri[1].Sub(tri[0]).Cross(tri[2].Sub(tri[0])).Len() / 2
+}
+
+// Bounds returns the smallest box containing the triangle.
+func (tri Triangle) Bounds() AABB {
+	return AABB{tri[0], tri[0]}.Extend(tri[1]).Extend(tri[2])
+}
+
+// Barycentric returns the barycentric coordinates of p (projected onto the triangle's plane) with respect to the
+// triangle, so that p = u*tri[0] + v*tri[1] + w*tri[2].
+func (tri Triangle) Barycentric(p Vec3) (u, v, w float32) {
+	return barycentric(p, tri[0], tri[1], tri[2])
+}
+
+// ClosestPoint returns the point of the triangle closest to p.
+func (tri Triangle) ClosestPoint(p Vec3) Vec3 {
+	a, b, c := simplexVertex{w: tri[0].Sub(p)}, simplexVertex{w: tri[1].Sub(p)}, simplexVertex{w: tri[2].Sub(p)}
+	closest, _, _ := closestOnTriangle(a, b, c)
+	return closest.Add(p)
+}
+
+// IntersectRay returns the distance along the ray at which it hits the triangle, from either side, and the barycentric
+// coordinates v and w of the hit point (the weights of tri[1] and tri[2]). This is the Möller-Trumbore algorithm. Only
+// hits with t in [0, maxT] are returned.
+func (tri Triangle) IntersectRay(r Ray, maxT float32) (t, v, w float32, ok bool) {
+	e1, e2 := tri[1].Sub(tri[0]), tri[2].Sub(tri[0])
+	p := r.Direction.Cross(e2)
+	det := e1.Dot(p)
+	if det == 0 {
+		return 0, 0, 0, false
+	}
+	inv := 1 / det
+
+	s := r.Origin.Sub(tri[0])
+	v = s.Dot(p) * inv
+	if v < 0 || v > 1 {
+		return 0, 0, 0, false
+	}
+
+	q := s.Cross(e1)
+	w = r.Direction.Dot(q) * inv
+	if w < 0 || v+w > 1 {
+		return 0, 0, 0, false
+	}
+
+	t = e2.Dot(q) * inv
+	if t < 0 || t > maxT {
+		return 0, 0, 0, false
+	}
+
+	return t, v, w, true
+}
+
+// IntersectsSphere returns whether the triangle overlaps or touches the sphere.
+func (tri Triangle) IntersectsSphere(center Vec3, radius float32) bool {
+	d := tri.ClosestPoint(center).Sub(center)
+	return d.Dot(d) <= radius*radius
+}
+
+// IntersectsAABB returns whether the triangle overlaps or touches the box, using the separating axis test by Akenine-Möller.
+func (tri Triangle) IntersectsAABB(b AABB) bool {
+	c, h := b.Center(), b.Size().Mul(.5)
+	v := [3]Vec3{tri[0].Sub(c), tri[1].Sub(c), tri[2].Sub(c)}
+	edges := [3]Vec3{v[1].Sub(v[0]), v[2].Sub(v[1]), v[0].Sub(v[2])}
+
+	separated := func(axis Vec3) bool {
+		p0, p1, p2 := v[0].Dot(axis), v[1].Dot(axis), v[2].Dot(axis)
+		r := h[0]*Abs(axis[0]) + h[1]*Abs(axis[1]) + h[2]*Abs(axis[2])
+		min, max := p0, p0
+		SetMin(&min, &p1)
+		SetMin(&min, &p2)
+		SetMax(&max, &p1)
+		SetMax(&max, &p2)
+		return min > r || max < -r
+	}
+
+	// The box's face normals, the triangle's normal, and the cross products of their edges
+	axes := []Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, edges[0].Cross(edges[1])}
+	for _, e := range edges {
+		axes = append(axes, Vec3{0, -e[2], e[1]}, Vec3{e[2], 0, -e[0]}, Vec3{-e[1], e[0], 0})
+	}
+	for _, axis := range axes {
+		if separated(axis) {
+			return false
+		}
+	}
+
+	return true
+}
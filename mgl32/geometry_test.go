@@ -0,0 +1,135 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestAABB(t *testing.T) {
+	b := EmptyAABB()
+	if !b.IsEmpty() || b.SurfaceArea() != 0 {
+		t.Errorf("Empty box is not empty: %v", b)
+	}
+
+	b = AABBFromPoints([]Vec3{{1, 2, 3}, {-1, 0, 5}, {0, 1, 4}})
+	if b.Min != (Vec3{-1, 0, 3}) || b.Max != (Vec3{1, 2, 5}) {
+		t.Errorf("Bounds of points are %v, expected %v to %v", b, Vec3{-1, 0, 3}, Vec3{1, 2, 5})
+	}
+	if !FloatEqual(b.SurfaceArea(), 24) {
+		t.Errorf("Surface area of 2x2x2 box is %v, expected 24", b.SurfaceArea())
+	}
+
+	if !b.Intersects(AABB{Vec3{1, 2, 5}, Vec3{3, 3, 6}}) || b.Intersects(AABB{Vec3{1.1, 0, 3}, Vec3{2, 1, 4}}) {
+		t.Errorf("Box overlap is wrong")
+	}
+	if !b.IntersectsSphere(Vec3{2, 1, 4}, 1) || b.IntersectsSphere(Vec3{2, 3, 4}, 1) {
+		t.Errorf("Box and sphere overlap is wrong")
+	}
+
+	if tHit, ok := b.IntersectRay(Ray{Vec3{-5, 1, 4}, Vec3{2, 0, 0}}, 10); !ok || !FloatEqual(tHit, 2) {
+		t.Errorf("Ray hits box at %v (%v), expected 2", tHit, ok)
+	}
+	if _, ok := b.IntersectRay(Ray{Vec3{-5, 1, 4}, Vec3{-1, 0, 0}}, 10); ok {
+		t.Errorf("Ray pointing away from box hits it")
+	}
+	if tHit, ok := b.IntersectRay(Ray{Vec3{0, 1, 4}, Vec3{0, 0, 1}}, 10); !ok || tHit != 0 {
+		t.Errorf("Ray starting inside box hits it at %v (%v), expected 0", tHit, ok)
+	}
+
+	// The transformed bounds must contain every transformed corner
+	m := Translate3D(1, 2, 3).Mul4(HomogRotate3D(1, Vec3{1, 1, 0}.Normalize())).Mul4(Scale3D(1, 2, 3))
+	transformed := b.Transform(m)
+	for i := 0; i < 8; i++ {
+		corner := b.Min
+		for j := 0; j < 3; j++ {
+			if i&(1<<uint(j)) != 0 {
+				corner[j] = b.Max[j]
+			}
+		}
+		p := m.Mul4x1(corner.Vec4(1)).Vec3()
+		if !transformed.Extend(p).Size().ApproxEqualThreshold(transformed.Size(), 1e-5) {
+			t.Errorf("Transformed box %v doesn't contain transformed corner %v", transformed, p)
+		}
+	}
+}
+
+func TestTriangle(t *testing.T) {
+	tri := Triangle{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}}
+
+	if n := tri.Normal(); n != (Vec3{0, 0, 1}) {
+		t.Errorf("Triangle normal is %v, expected %v", n, Vec3{0, 0, 1})
+	}
+	if !FloatEqual(tri.Area(), 2) {
+		t.Errorf("Triangle area is %v, expected 2", tri.Area())
+	}
+
+	closest := []struct{ P, Closest Vec3 }{
+		{Vec3{.5, .5, 3}, Vec3{.5, .5, 0}},
+		{Vec3{-1, -1, 0}, Vec3{0, 0, 0}},
+		{Vec3{2, 2, 1}, Vec3{1, 1, 0}},
+		{Vec3{1, -3, 0}, Vec3{1, 0, 0}},
+	}
+	for _, c := range closest {
+		if p := tri.ClosestPoint(c.P); !p.ApproxEqualThreshold(c.Closest, 1e-6) {
+			t.Errorf("Closest point on triangle to %v is %v, expected %v", c.P, p, c.Closest)
+		}
+	}
+
+	if tHit, v, w, ok := tri.IntersectRay(Ray{Vec3{.5, 1, 5}, Vec3{0, 0, -1}}, 10); !ok || !FloatEqual(tHit, 5) || !FloatEqual(v, .25) || !FloatEqual(w, .5) {
+		t.Errorf("Ray hits triangle at t=%v v=%v w=%v (%v), expected 5, .25, .5", tHit, v, w, ok)
+	}
+	if _, _, _, ok := tri.IntersectRay(Ray{Vec3{1.5, 1.5, 5}, Vec3{0, 0, -1}}, 10); ok {
+		t.Errorf("Ray outside the triangle hits it")
+	}
+	if _, _, _, ok := tri.IntersectRay(Ray{Vec3{.5, .5, 5}, Vec3{0, 0, -1}}, 4); ok {
+		t.Errorf("Ray hits triangle beyond its maximum distance")
+	}
+
+	if !tri.IntersectsAABB(AABB{Vec3{.5, .5, -1}, Vec3{.6, .6, 1}}) {
+		t.Errorf("Box through the triangle doesn't intersect it")
+	}
+	if tri.IntersectsAABB(AABB{Vec3{1.1, 1.1, -1}, Vec3{2, 2, 1}}) {
+		t.Errorf("Box beyond the triangle's hypotenuse intersects it")
+	}
+	if tri.IntersectsAABB(AABB{Vec3{0, 0, .1}, Vec3{1, 1, 1}}) {
+		t.Errorf("Box above the triangle intersects it")
+	}
+}
+
+func TestTriangleIntersectsAABBRandom(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	randVec := func() Vec3 { return Vec3{rand.Float32()*4 - 2, rand.Float32()*4 - 2, rand.Float32()*4 - 2} }
+
+	// Compare to GJK, which finds the same thing a completely different way
+	for i := 0; i < 500; i++ {
+		tri := Triangle{randVec(), randVec(), randVec()}
+		box := AABB{randVec(), Vec3{}}
+		box.Max = box.Min.Add(Vec3{rand.Float32(), rand.Float32(), rand.Float32()})
+
+		hull := triangleShape(tri)
+		if dist, _, _ := GJKDistance(hull, box); dist > 1e-4 && tri.IntersectsAABB(box) {
+			t.Errorf("Triangle %v intersects box %v, but they are %v apart", tri, box, dist)
+		} else if dist == 0 && !tri.IntersectsAABB(box) {
+			t.Errorf("Triangle %v doesn't intersect box %v, but GJK says it does", tri, box)
+		}
+	}
+}
+
+// triangleShape is a triangle as a SupportMapper.
+type triangleShape Triangle
+
+func (tri triangleShape) Support(direction Vec3) Vec3 {
+	best := tri[0]
+	for _, p := range tri[1:] {
+		if p.Dot(direction) > best.Dot(direction) {
+			best = p
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,401 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Interpolation is how an animation track gets from a keyframe to the next.
+type Interpolation int
+
+const (
+	// InterpolateStep holds the keyframe's value until the next keyframe.
+	InterpolateStep Interpolation = iota
+	// InterpolateLinear interpolates linearly, or with QuatSlerp for rotations.
+	InterpolateLinear
+	// InterpolateHermite interpolates with a cubic Hermite spline through the keyframe's value, leaving it with its
+	// OutTangent, and the next keyframe's value, arriving with its InTangent. Tangents are per unit of time.
+	// Rotations are interpolated component by component and normalized.
+	InterpolateHermite
+	// InterpolateBezier interpolates like InterpolateLinear, but with the fraction of the way from a keyframe to the
+	// next eased by a cubic Bezier timing curve from (0,0) to (1,1) with the keyframe's Ease control points, as CSS's
+	// cubic-bezier() timing function.
+	InterpolateBezier
+)
+
+func (i Interpolation) String() string {
+	switch i {
+	case InterpolateStep:
+		return "InterpolateStep"
+	case InterpolateLinear:
+		return "InterpolateLinear"
+	case InterpolateHermite:
+		return "InterpolateHermite"
+	case InterpolateBezier:
+		return "InterpolateBezier"
+	}
+
+	return fmt.Sprintf("Interpolation(%d)", int(i))
+}
+
+// WrapMode is what an animation track does at times outside its keyframes.
+type WrapMode int
+
+const (
+	// WrapClamp holds the first keyframe's value before it and the last's after it.
+	WrapClamp WrapMode = iota
+	// WrapLoop repeats the track, jumping from the last keyframe back to the first.
+	WrapLoop
+	// WrapPingPong repeats the track forwards then backwards.
+	WrapPingPong
+)
+
+func (w WrapMode) String() string {
+	switch w {
+	case WrapClamp:
+		return "WrapClamp"
+	case WrapLoop:
+		return "WrapLoop"
+	case WrapPingPong:
+		return "WrapPingPong"
+	}
+
+	return fmt.Sprintf("WrapMode(%d)", int(w))
+}
+
+// wrap maps t into the range from start to end. NaN, and infinities when the track repeats, which have no place in
+// the range, are mapped to the start.
+func (w WrapMode) wrap(t, start, end float32) float32 {
+	length := end - start
+	if length <= 0 || t != t || (w == WrapLoop || w == WrapPingPong) && math.IsInf(float64(t), 0) {
+		return start
+	}
+
+	switch w {
+	case WrapLoop:
+		t = mod(t-start, length)
+	case WrapPingPong:
+		t = mod(t-start, 2*length)
+		if t > length {
+			t = 2*length - t
+		}
+	default:
+		return Clamp(t, start, end)
+	}
+
+	return start + t
+}
+
+// mod returns x modulo y, which is positive, in the range [0,y).
+func mod(x, y float32) float32 {
+	m := float32(math.Mod(float64(x), float64(y)))
+	if m < 0 {
+		m += y
+	}
+	if m >= y {
+		// Adding y to a tiny negative remainder can round up to y
+		m = 0
+	}
+	return m
+}
+
+// keyCursor caches the keyframe found by the last lookup in a track, since playback usually samples the same or the
+// next keyframe.
+type keyCursor struct {
+	key int
+}
+
+// find returns the keyframe at or before t, out of n keyframes at increasing times, and how far t is from it to the
+// next, from 0 to 1. t must be in the range of the keyframes; NaN gives the last one.
+func (c *keyCursor) find(t float32, n int, time func(i int) float32) (k int, frac float32) {
+	if n == 1 || t >= time(n-1) {
+		return n - 1, 0
+	}
+
+	k = c.key
+	switch {
+	case k < n-1 && time(k) <= t && t < time(k+1):
+	case k+1 < n-1 && time(k+1) <= t && t < time(k+2):
+		k++
+	default:
+		k = sort.Search(n, func(i int) bool { return time(i) > t }) - 1
+		if k < 0 {
+			k = 0
+		}
+		if k >= n-1 {
+			// Only t being NaN, which no time is after, gets here
+			return n - 1, 0
+		}
+	}
+	c.key = k
+
+	return k, (t - time(k)) / (time(k+1) - time(k))
+}
+
+// validateKeyframes checks that n keyframes have increasing times and valid interpolations.
+func validateKeyframes(n int, key func(i int) (time float32, interpolation Interpolation, ease [2]Vec2)) error {
+	if n == 0 {
+		return errors.New("track has no keyframes")
+	}
+
+	var previous float32
+	for i := 0; i < n; i++ {
+		time, interpolation, ease := key(i)
+		switch {
+		case i > 0 && time <= previous:
+			return fmt.Errorf("keyframe %d is at time %v, not after %v", i, time, previous)
+		case interpolation < InterpolateStep || interpolation > InterpolateBezier:
+			return fmt.Errorf("keyframe %d has %v", i, interpolation)
+		case interpolation == InterpolateBezier && (ease[0][0] < 0 || ease[0][0] > 1 || ease[1][0] < 0 || ease[1][0] > 1):
+			return fmt.Errorf("keyframe %d has ease control points %v, whose times aren't between 0 and 1", i, ease)
+		}
+		previous = time
+	}
+
+	return nil
+}
+
+// easeFraction returns how far to interpolate, given how far through a segment the time is, for the segment's
+// interpolation.
+func easeFraction(frac float32, interpolation Interpolation, ease [2]Vec2) float32 {
+	if interpolation == InterpolateBezier {
+		return bezierEase(ease[0], ease[1], frac)
+	}
+	return frac
+}
+
+// bezierEase returns the y of the point of the cubic Bezier curve from (0,0) to (1,1), with control points p1 and p2,
+// whose x is x. The control points' x must be from 0 to 1, so there is only one such point.
+func bezierEase(p1, p2 Vec2, x float32) float32 {
+	// The polynomial coefficients of each coordinate
+	cx, cy := 3*p1[0], 3*p1[1]
+	bx, by := 3*(p2[0]-p1[0])-cx, 3*(p2[1]-p1[1])-cy
+	ax, ay := 1-cx-bx, 1-cy-by
+	curveX := func(s float32) float32 { return ((ax*s+bx)*s + cx) * s }
+
+	// Newton's method usually converges in a few steps; bisection is the fallback where the slope is flat
+	s := x
+	for i := 0; i < 8; i++ {
+		slope := (3*ax*s+2*bx)*s + cx
+		if Abs(slope) < 1e-6 {
+			break
+		}
+		s -= (curveX(s) - x) / slope
+	}
+	if s < 0 || s > 1 || Abs(curveX(s)-x) > 1e-6 {
+		low, high := float32(0), float32(1)
+		s = x
+		for i := 0; i < 32 && high-low > 1e-7; i++ {
+			if curveX(s) < x {
+				low = s
+			} else {
+				high = s
+			}
+			s = (low + high) / 2
+		}
+	}
+
+	return ((ay*s+by)*s + cy) * s
+}
+
+// hermite returns the point a fraction s of the way along the cubic Hermite spline from p0 to p1, with tangents m0 and
+// m1 per unit of time, over a time dt.
+func hermite(p0, m0, p1, m1, s, dt float32) float32 {
+	s2, s3 := s*s, s*s*s
+	return (2*s3-3*s2+1)*p0 + (s3-2*s2+s)*dt*m0 + (-2*s3+3*s2)*p1 + (s3-s2)*dt*m1
+}
+
+// FloatKey is a keyframe of a FloatTrack. Interpolation and Ease apply from it to the next keyframe.
+type FloatKey struct {
+	Time, Value           float32
+	InTangent, OutTangent float32
+	Interpolation         Interpolation
+	Ease                  [2]Vec2
+}
+
+// FloatTrack animates a number with keyframes at increasing times. The first and last keyframes are the start and
+// end of the track.
+//
+// A track must have at least one keyframe. Sampling remembers where it was, so sampling at increasing times is quick,
+// but a track shouldn't be sampled from several goroutines at once.
+type FloatTrack struct {
+	Keys   []FloatKey
+	Wrap   WrapMode
+	cursor keyCursor
+}
+
+// NewFloatTrack creates a track after checking its keyframes.
+func NewFloatTrack(keys []FloatKey, wrap WrapMode) (*FloatTrack, error) {
+	tr := &FloatTrack{Keys: keys, Wrap: wrap}
+	if err := tr.Validate(); err != nil {
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+// Validate checks that the track has keyframes, at increasing times, and that their interpolations are valid. It is
+// only necessary to call this after modifying the track's fields directly.
+func (tr *FloatTrack) Validate() error {
+	return validateKeyframes(len(tr.Keys), func(i int) (float32, Interpolation, [2]Vec2) {
+		return tr.Keys[i].Time, tr.Keys[i].Interpolation, tr.Keys[i].Ease
+	})
+}
+
+// Range returns the times of the first and last keyframes.
+func (tr *FloatTrack) Range() [2]float32 {
+	return [2]float32{tr.Keys[0].Time, tr.Keys[len(tr.Keys)-1].Time}
+}
+
+// Sample returns the track's value at time t.
+func (tr *FloatTrack) Sample(t float32) float32 {
+	n := len(tr.Keys)
+	t = tr.Wrap.wrap(t, tr.Keys[0].Time, tr.Keys[n-1].Time)
+	k, frac := tr.cursor.find(t, n, func(i int) float32 { return tr.Keys[i].Time })
+
+	a := &tr.Keys[k]
+	if frac == 0 || a.Interpolation == InterpolateStep {
+		return a.Value
+	}
+
+	b := &tr.Keys[k+1]
+	if a.Interpolation == InterpolateHermite {
+		return hermite(a.Value, a.OutTangent, b.Value, b.InTangent, frac, b.Time-a.Time)
+	}
+	frac = easeFraction(frac, a.Interpolation, a.Ease)
+	return a.Value + (b.Value-a.Value)*frac
+}
+
+// Vec3Key is a keyframe of a Vec3Track. Interpolation and Ease apply from it to the next keyframe.
+type Vec3Key struct {
+	Time                  float32
+	Value                 Vec3
+	InTangent, OutTangent Vec3
+	Interpolation         Interpolation
+	Ease                  [2]Vec2
+}
+
+// Vec3Track animates a vector, such as a position or a scale, like FloatTrack.
+type Vec3Track struct {
+	Keys   []Vec3Key
+	Wrap   WrapMode
+	cursor keyCursor
+}
+
+// NewVec3Track creates a track after checking its keyframes.
+func NewVec3Track(keys []Vec3Key, wrap WrapMode) (*Vec3Track, error) {
+	tr := &Vec3Track{Keys: keys, Wrap: wrap}
+	if err := tr.Validate(); err != nil {
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+// Validate checks the track like FloatTrack.Validate.
+func (tr *Vec3Track) Validate() error {
+	return validateKeyframes(len(tr.Keys), func(i int) (float32, Interpolation, [2]Vec2) {
+		return tr.Keys[i].Time, tr.Keys[i].Interpolation, tr.Keys[i].Ease
+	})
+}
+
+// Range returns the times of the first and last keyframes.
+func (tr *Vec3Track) Range() [2]float32 {
+	return [2]float32{tr.Keys[0].Time, tr.Keys[len(tr.Keys)-1].Time}
+}
+
+// Sample returns the track's value at time t.
+func (tr *Vec3Track) Sample(t float32) Vec3 {
+	n := len(tr.Keys)
+	t = tr.Wrap.wrap(t, tr.Keys[0].Time, tr.Keys[n-1].Time)
+	k, frac := tr.cursor.find(t, n, func(i int) float32 { return tr.Keys[i].Time })
+
+	a := &tr.Keys[k]
+	if frac == 0 || a.Interpolation == InterpolateStep {
+		return a.Value
+	}
+
+	b := &tr.Keys[k+1]
+	if a.Interpolation == InterpolateHermite {
+		var v Vec3
+		for i := range v {
+			v[i] = hermite(a.Value[i], a.OutTangent[i], b.Value[i], b.InTangent[i], frac, b.Time-a.Time)
+		}
+		return v
+	}
+	frac = easeFraction(frac, a.Interpolation, a.Ease)
+	return a.Value.Add(b.Value.Sub(a.Value).Mul(frac))
+}
+
+// QuatKey is a keyframe of a QuatTrack. Interpolation and Ease apply from it to the next keyframe.
+type QuatKey struct {
+	Time                  float32
+	Value                 Quat
+	InTangent, OutTangent Quat
+	Interpolation         Interpolation
+	Ease                  [2]Vec2
+}
+
+// QuatTrack animates a rotation, like FloatTrack. Linear and Bezier interpolation take the shortest path between
+// keyframes, at a constant angular speed.
+type QuatTrack struct {
+	Keys   []QuatKey
+	Wrap   WrapMode
+	cursor keyCursor
+}
+
+// NewQuatTrack creates a track after checking its keyframes.
+func NewQuatTrack(keys []QuatKey, wrap WrapMode) (*QuatTrack, error) {
+	tr := &QuatTrack{Keys: keys, Wrap: wrap}
+	if err := tr.Validate(); err != nil {
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+// Validate checks the track like FloatTrack.Validate.
+func (tr *QuatTrack) Validate() error {
+	return validateKeyframes(len(tr.Keys), func(i int) (float32, Interpolation, [2]Vec2) {
+		return tr.Keys[i].Time, tr.Keys[i].Interpolation, tr.Keys[i].Ease
+	})
+}
+
+// Range returns the times of the first and last keyframes.
+func (tr *QuatTrack) Range() [2]float32 {
+	return [2]float32{tr.Keys[0].Time, tr.Keys[len(tr.Keys)-1].Time}
+}
+
+// Sample returns the track's value at time t, normalized.
+func (tr *QuatTrack) Sample(t float32) Quat {
+	n := len(tr.Keys)
+	t = tr.Wrap.wrap(t, tr.Keys[0].Time, tr.Keys[n-1].Time)
+	k, frac := tr.cursor.find(t, n, func(i int) float32 { return tr.Keys[i].Time })
+
+	a := &tr.Keys[k]
+	if frac == 0 || a.Interpolation == InterpolateStep {
+		return a.Value.Normalize()
+	}
+
+	b := &tr.Keys[k+1]
+	if a.Interpolation == InterpolateHermite {
+		var q Quat
+		q.W = hermite(a.Value.W, a.OutTangent.W, b.Value.W, b.InTangent.W, frac, b.Time-a.Time)
+		for i := range q.V {
+			q.V[i] = hermite(a.Value.V[i], a.OutTangent.V[i], b.Value.V[i], b.InTangent.V[i], frac, b.Time-a.Time)
+		}
+		return q.Normalize()
+	}
+
+	to := b.Value
+	if a.Value.Dot(to) < 0 {
+		to = to.Scale(-1)
+	}
+	return QuatSlerp(a.Value, to, easeFraction(frac, a.Interpolation, a.Ease))
+}
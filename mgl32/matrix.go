@@ -743,7 +743,7 @@ func (m1 Mat4) Transpose() Mat4 {
 // determinant is hard coded based on pre-computed cofactor expansion, and uses
 // no loops. Of course, the addition and multiplication must still be done.
 func (m Mat2) Det() float32 {
-	return m[0]*m[2] - m[1]*m[3]
+	return m[0]*m[3] - m[1]*m[2]
 }
 
 // The determinant of a matrix is a measure of a square matrix's
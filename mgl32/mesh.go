@@ -0,0 +1,365 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+)
+
+// A Mesh is an indexed triangle mesh, stored as parallel vertex attribute arrays. Every attribute array
+// has one entry per vertex, and every three entries of Indices make up a counter-clockwise (front facing in GL's
+// default convention) triangle.
+//
+// Tangents point in the direction of increasing U. The W component is the handedness of the tangent frame,
+// so the bitangent (the direction of increasing V) is Normal.Cross(Tangent.Vec3()).Mul(Tangent[3]).
+//
+// UVs use GL's convention, with (0,0) in the bottom left corner of the texture.
+type Mesh struct {
+	Positions []Vec3
+	Normals   []Vec3
+	Tangents  []Vec4
+	UVs       []Vec2
+	Indices   []uint32
+}
+
+// NumVertices returns the number of vertices in the mesh.
+func (m *Mesh) NumVertices() int {
+	return len(m.Positions)
+}
+
+// NumTriangles returns the number of triangles in the mesh.
+func (m *Mesh) NumTriangles() int {
+	return len(m.Indices) / 3
+}
+
+// Triangles returns the triangles of the mesh, for building a BVH or other queries on its geometry.
+func (m *Mesh) Triangles() []Triangle {
+	triangles := make([]Triangle, m.NumTriangles())
+	for i := range triangles {
+		triangles[i] = Triangle{m.Positions[m.Indices[3*i]], m.Positions[m.Indices[3*i+1]], m.Positions[m.Indices[3*i+2]]}
+	}
+
+	return triangles
+}
+
+func (m *Mesh) addVertex(pos, normal, tangent Vec3, uv Vec2) uint32 {
+	m.Positions = append(m.Positions, pos)
+	m.Normals = append(m.Normals, normal)
+	m.Tangents = append(m.Tangents, tangent.Vec4(1))
+	m.UVs = append(m.UVs, uv)
+
+	return uint32(len(m.Positions) - 1)
+}
+
+// addTriangle adds a triangle, unless two of its corners are in the same place (as happens at the poles of
+// a sphere).
+func (m *Mesh) addTriangle(a, b, c uint32) {
+	if m.Positions[a] == m.Positions[b] || m.Positions[b] == m.Positions[c] || m.Positions[a] == m.Positions[c] {
+		return
+	}
+
+	m.Indices = append(m.Indices, a, b, c)
+}
+
+// addGrid adds the triangles for a grid of (cols+1)*(rows+1) vertices starting at base, laid out in rows
+// of increasing V, with U increasing along each row.
+func (m *Mesh) addGrid(base uint32, cols, rows int) {
+	stride := uint32(cols + 1)
+	for i := uint32(0); i < uint32(rows); i++ {
+		for j := uint32(0); j < uint32(cols); j++ {
+			a := base + i*stride + j
+			b, c, d := a+1, a+stride+1, a+stride
+
+			m.addTriangle(a, b, c)
+			m.addTriangle(a, c, d)
+		}
+	}
+}
+
+// A lathePoint is a point on a profile curve that is swept around the Y axis. Radius is the distance from the axis,
+// and the normal is given as a (radial, Y) pair.
+type lathePoint struct {
+	Radius, Y    float32
+	NormalRadial float32
+	NormalY      float32
+	V            float32
+}
+
+// addLathe sweeps a profile around the Y axis to make a surface of revolution. The profile must be ordered so that
+// the outward normal is on its right, i.e. going up along the outside of the shape.
+func (m *Mesh) addLathe(profile []lathePoint, slices int) {
+	base := uint32(len(m.Positions))
+	for _, p := range profile {
+		for j := 0; j <= slices; j++ {
+			u := float32(j) / float32(slices)
+			sin, cos := math.Sincos(2 * math.Pi * float64(u))
+			s, c := float32(sin), float32(cos)
+
+			m.addVertex(
+				Vec3{p.Radius * s, p.Y, p.Radius * c},
+				Vec3{p.NormalRadial * s, p.NormalY, p.NormalRadial * c}.Normalize(),
+				Vec3{c, 0, -s},
+				Vec2{u, p.V},
+			)
+		}
+	}
+
+	m.addGrid(base, slices, len(profile)-1)
+}
+
+// addDisk adds a flat disk at height y, facing up (+Y) or down (-Y). The disk is textured as if the texture
+// was projected straight onto it from outside the shape.
+func (m *Mesh) addDisk(y, radius float32, slices int, up bool) {
+	normal, tangent, vSign := Vec3{0, -1, 0}, Vec3{1, 0, 0}, float32(.5)
+	if up {
+		normal, vSign = Vec3{0, 1, 0}, -.5
+	}
+
+	center := m.addVertex(Vec3{0, y, 0}, normal, tangent, Vec2{.5, .5})
+	for j := 0; j <= slices; j++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(j) / float64(slices))
+		s, c := float32(sin), float32(cos)
+		m.addVertex(Vec3{radius * s, y, radius * c}, normal, tangent, Vec2{.5 + .5*s, .5 + vSign*c})
+	}
+
+	for j := uint32(1); j <= uint32(slices); j++ {
+		if up {
+			m.addTriangle(center, center+j, center+j+1)
+		} else {
+			m.addTriangle(center, center+j+1, center+j)
+		}
+	}
+}
+
+// CubeMesh generates an axis aligned cube centered at the origin with sides of length size.
+// Each face has its own four vertices so that the normals are flat, and each face is textured with
+// the full [0,1] UV range.
+func CubeMesh(size float32) *Mesh {
+	faces := [6]struct{ normal, u, v Vec3 }{
+		{Vec3{1, 0, 0}, Vec3{0, 0, -1}, Vec3{0, 1, 0}},
+		{Vec3{-1, 0, 0}, Vec3{0, 0, 1}, Vec3{0, 1, 0}},
+		{Vec3{0, 1, 0}, Vec3{1, 0, 0}, Vec3{0, 0, -1}},
+		{Vec3{0, -1, 0}, Vec3{1, 0, 0}, Vec3{0, 0, 1}},
+		{Vec3{0, 0, 1}, Vec3{1, 0, 0}, Vec3{0, 1, 0}},
+		{Vec3{0, 0, -1}, Vec3{-1, 0, 0}, Vec3{0, 1, 0}},
+	}
+
+	h := size / 2
+	m := &Mesh{}
+	for _, f := range faces {
+		base := uint32(len(m.Positions))
+		for _, corner := range [4]Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+			pos := f.normal.Add(f.u.Mul(2*corner[0] - 1)).Add(f.v.Mul(2*corner[1] - 1)).Mul(h)
+			m.addVertex(pos, f.normal, f.u, corner)
+		}
+		m.addTriangle(base, base+1, base+2)
+		m.addTriangle(base, base+2, base+3)
+	}
+
+	return m
+}
+
+// UVSphereMesh generates a sphere centered at the origin, divided into slices around the Y axis and stacks from
+// pole to pole. U wraps around the Y axis starting at +Z, and V goes from 0 at the bottom pole to 1 at the top.
+// Vertices along the seam and at the poles are duplicated so that the texture coordinates are continuous.
+func UVSphereMesh(radius float32, slices, stacks int) *Mesh {
+	profile := make([]lathePoint, stacks+1)
+	for i := range profile {
+		v := float32(i) / float32(stacks)
+		sin, cos := math.Sincos(math.Pi * float64(1-v))
+		s, c := float32(sin), float32(cos)
+		profile[i] = lathePoint{radius * s, radius * c, s, c, v}
+	}
+	profile[0].Radius, profile[stacks].Radius = 0, 0
+
+	m := &Mesh{}
+	m.addLathe(profile, slices)
+
+	return m
+}
+
+// IcosphereMesh generates a sphere centered at the origin by repeatedly subdividing an icosahedron. Unlike a UV sphere,
+// the triangles are all close to the same size. Each subdivision multiplies the number of triangles by 4, with 0
+// subdivisions producing a plain icosahedron.
+//
+// Texture coordinates are mapped the same way as UVSphereMesh, with vertices along the seam duplicated.
+func IcosphereMesh(radius float32, subdivisions int) *Mesh {
+	t := float32((1 + math.Sqrt(5)) / 2)
+	verts := []Vec3{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+		{0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+	}
+	for i := range verts {
+		verts[i] = verts[i].Normalize()
+	}
+
+	faces := [][3]uint32{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+
+	for s := 0; s < subdivisions; s++ {
+		midpoints := make(map[[2]uint32]uint32)
+		midpoint := func(a, b uint32) uint32 {
+			key := [2]uint32{a, b}
+			if b < a {
+				key = [2]uint32{b, a}
+			}
+			if i, ok := midpoints[key]; ok {
+				return i
+			}
+			verts = append(verts, verts[a].Add(verts[b]).Normalize())
+			midpoints[key] = uint32(len(verts) - 1)
+			return midpoints[key]
+		}
+
+		next := make([][3]uint32, 0, len(faces)*4)
+		for _, f := range faces {
+			ab, bc, ca := midpoint(f[0], f[1]), midpoint(f[1], f[2]), midpoint(f[2], f[0])
+			next = append(next, [3]uint32{f[0], ab, ca}, [3]uint32{f[1], bc, ab}, [3]uint32{f[2], ca, bc}, [3]uint32{ab, bc, ca})
+		}
+		faces = next
+	}
+
+	m := &Mesh{}
+	for _, n := range verts {
+		theta := math.Atan2(float64(n[0]), float64(n[2]))
+		u := float32(theta / (2 * math.Pi))
+		if u < 0 {
+			u++
+		}
+		v := .5 + float32(math.Asin(float64(Clamp(n[1], -1, 1)))/math.Pi)
+		sin, cos := math.Sincos(theta)
+
+		m.addVertex(n.Mul(radius), n, Vec3{float32(cos), 0, float32(-sin)}, Vec2{u, v})
+	}
+
+	// Triangles straddling the seam at U=0/U=1 would otherwise stretch across the whole texture,
+	// so their vertices on the U=0 side are duplicated with U shifted by 1.
+	wrapped := make(map[uint32]uint32)
+	for _, f := range faces {
+		minU, maxU := m.UVs[f[0]][0], m.UVs[f[0]][0]
+		for _, i := range f[1:] {
+			SetMin(&minU, &m.UVs[i][0])
+			SetMax(&maxU, &m.UVs[i][0])
+		}
+
+		if maxU-minU > .5 {
+			for k, i := range f {
+				if m.UVs[i][0] >= .5 {
+					continue
+				}
+				if w, ok := wrapped[i]; ok {
+					f[k] = w
+					continue
+				}
+				uv := m.UVs[i]
+				w := m.addVertex(m.Positions[i], m.Normals[i], m.Tangents[i].Vec3(), Vec2{uv[0] + 1, uv[1]})
+				wrapped[i], f[k] = w, w
+			}
+		}
+
+		m.addTriangle(f[0], f[1], f[2])
+	}
+
+	return m
+}
+
+// CylinderMesh generates a capped cylinder centered at the origin with its axis along Y. The side is textured
+// like UVSphereMesh, and each cap has its own vertices (so the edges are sharp) with the texture projected onto it.
+func CylinderMesh(radius, height float32, slices int) *Mesh {
+	h := height / 2
+
+	m := &Mesh{}
+	m.addLathe([]lathePoint{{radius, -h, 1, 0, 0}, {radius, h, 1, 0, 1}}, slices)
+	m.addDisk(h, radius, slices, true)
+	m.addDisk(-h, radius, slices, false)
+
+	return m
+}
+
+// ConeMesh generates a cone centered at the origin with its axis along Y, pointing up. The base is capped.
+func ConeMesh(radius, height float32, slices int) *Mesh {
+	h := height / 2
+	// The side normal is the slant turned 90 degrees outwards
+	slant := Vec2{radius, height}.Normalize()
+
+	m := &Mesh{}
+	m.addLathe([]lathePoint{{radius, -h, slant[1], slant[0], 0}, {0, h, slant[1], slant[0], 1}}, slices)
+	m.addDisk(-h, radius, slices, false)
+
+	return m
+}
+
+// TorusMesh generates a torus centered at the origin around the Y axis. The majorRadius is the distance from the origin to
+// the center of the tube, and minorRadius is the radius of the tube. U wraps around the Y axis, and V wraps around the tube
+// starting from the outer equator.
+func TorusMesh(majorRadius, minorRadius float32, majorSegments, minorSegments int) *Mesh {
+	profile := make([]lathePoint, minorSegments+1)
+	for i := range profile {
+		v := float32(i) / float32(minorSegments)
+		sin, cos := math.Sincos(2 * math.Pi * float64(v))
+		s, c := float32(sin), float32(cos)
+		profile[i] = lathePoint{majorRadius + minorRadius*c, minorRadius * s, c, s, v}
+	}
+
+	m := &Mesh{}
+	m.addLathe(profile, majorSegments)
+
+	return m
+}
+
+// PlaneMesh generates a flat grid in the XZ plane centered at the origin, facing up (+Y). The width is along X and the depth along Z,
+// each divided into the given number of segments. U increases along +X, and V along -Z, so the texture appears upright when looking down
+// at the plane with -Z forward.
+func PlaneMesh(width, depth float32, widthSegments, depthSegments int) *Mesh {
+	m := &Mesh{}
+	for i := 0; i <= depthSegments; i++ {
+		v := float32(i) / float32(depthSegments)
+		for j := 0; j <= widthSegments; j++ {
+			u := float32(j) / float32(widthSegments)
+			m.addVertex(Vec3{(u - .5) * width, 0, (.5 - v) * depth}, Vec3{0, 1, 0}, Vec3{1, 0, 0}, Vec2{u, v})
+		}
+	}
+	m.addGrid(0, widthSegments, depthSegments)
+
+	return m
+}
+
+// CapsuleMesh generates a capsule centered at the origin with its axis along Y: a cylinder of the given height capped with
+// two hemispheres, so the total height is height+2*radius. Each hemisphere is divided into the given number of stacks.
+// V is proportional to distance along the profile, so the texture is not stretched where the hemispheres meet the cylinder.
+func CapsuleMesh(radius, height float32, slices, stacks int) *Mesh {
+	h := height / 2
+	profile := make([]lathePoint, 0, 2*stacks+2)
+	for i := 0; i <= stacks; i++ {
+		sin, cos := math.Sincos(math.Pi / 2 * float64(i) / float64(stacks))
+		s, c := float32(sin), float32(cos)
+		profile = append(profile, lathePoint{radius * s, -h - radius*c, s, -c, 0})
+	}
+	for i := 0; i <= stacks; i++ {
+		sin, cos := math.Sincos(math.Pi / 2 * float64(i) / float64(stacks))
+		s, c := float32(sin), float32(cos)
+		profile = append(profile, lathePoint{radius * c, h + radius*s, c, s, 0})
+	}
+	profile[0].Radius, profile[len(profile)-1].Radius = 0, 0
+
+	// Assign V by arc length along the profile
+	total := float32(math.Pi)*radius + height
+	dist := float32(0)
+	for i := 1; i < len(profile); i++ {
+		dist += Vec2{profile[i].Radius - profile[i-1].Radius, profile[i].Y - profile[i-1].Y}.Len()
+		profile[i].V = Clamp(dist/total, 0, 1)
+	}
+	profile[len(profile)-1].V = 1
+
+	m := &Mesh{}
+	m.addLathe(profile, slices)
+
+	return m
+}
@@ -0,0 +1,424 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"errors"
+	"math"
+)
+
+// Bounds returns the bounding box of the mesh's vertices.
+func (m *Mesh) Bounds() AABB {
+	return AABBFromPoints(m.Positions)
+}
+
+// BoundingSphere returns a sphere around the mesh's vertices, as BoundingSphere does.
+func (m *Mesh) BoundingSphere() (center Vec3, radius float32) {
+	return BoundingSphere(m.Positions)
+}
+
+// BoundingSphere returns a sphere that contains all the points, with Jack Ritter's method: it starts with the sphere
+// through the pair of extreme points (along the axes and the diagonals) that are furthest apart, and grows it just
+// enough to take in each point outside it. The sphere is usually within a few percent of the smallest one.
+func BoundingSphere(points []Vec3) (center Vec3, radius float32) {
+	if len(points) == 0 {
+		return Vec3{}, 0
+	}
+
+	directions := []Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, 1, 1}, {1, 1, -1}, {1, -1, 1}, {1, -1, -1}}
+	var a, b Vec3
+	var best float32 = -1
+	for _, d := range directions {
+		min, max := points[0], points[0]
+		for _, p := range points[1:] {
+			if p.Dot(d) < min.Dot(d) {
+				min = p
+			}
+			if p.Dot(d) > max.Dot(d) {
+				max = p
+			}
+		}
+		if distance := max.Sub(min).Len(); distance > best {
+			a, b, best = min, max, distance
+		}
+	}
+
+	center, radius = a.Add(b).Mul(.5), best/2
+	for _, p := range points {
+		if distance := p.Sub(center).Len(); distance > radius {
+			// Grow the sphere to touch p and the far side of the old sphere
+			radius = (radius + distance) / 2
+			center = p.Sub(p.Sub(center).Mul(radius / distance))
+		}
+	}
+
+	// Rounding can leave points a hair outside
+	for _, p := range points {
+		if distance := p.Sub(center).Len(); distance > radius {
+			radius = distance
+		}
+	}
+
+	return center, radius
+}
+
+// cornerAngle returns the angle of triangle t at its corner k.
+func (m *Mesh) cornerAngle(t, k int) float32 {
+	p := m.Positions[m.Indices[3*t+k]]
+	edge1 := m.Positions[m.Indices[3*t+(k+1)%3]].Sub(p)
+	edge2 := m.Positions[m.Indices[3*t+(k+2)%3]].Sub(p)
+
+	return float32(math.Atan2(float64(edge1.Cross(edge2).Len()), float64(edge1.Dot(edge2))))
+}
+
+// splitVertex appends a copy of vertex v, with all its attributes, and returns the copy's index.
+func (m *Mesh) splitVertex(v uint32) uint32 {
+	m.Positions = append(m.Positions, m.Positions[v])
+	if m.Normals != nil {
+		m.Normals = append(m.Normals, m.Normals[v])
+	}
+	if m.Tangents != nil {
+		m.Tangents = append(m.Tangents, m.Tangents[v])
+	}
+	if m.UVs != nil {
+		m.UVs = append(m.UVs, m.UVs[v])
+	}
+
+	return uint32(len(m.Positions) - 1)
+}
+
+// cornerAttribute is a vertex with a value computed for one of its attributes, identifying the copy of the vertex that
+// has that value.
+type cornerAttribute struct {
+	vertex uint32
+	value  Vec4
+}
+
+// assignCornerValues sets an attribute of the vertex of each corner (each element of Indices) to values[corner],
+// copying vertices that have several different values.
+func (m *Mesh) assignCornerValues(values []Vec4, set func(v uint32, value Vec4)) {
+	first := make(map[uint32]Vec4)
+	copies := make(map[cornerAttribute]uint32)
+	for corner, value := range values {
+		v := m.Indices[corner]
+		if firstValue, ok := first[v]; !ok {
+			first[v] = value
+			set(v, value)
+			continue
+		} else if firstValue == value {
+			continue
+		}
+
+		key := cornerAttribute{v, value}
+		c, ok := copies[key]
+		if !ok {
+			c = m.splitVertex(v)
+			copies[key] = c
+			set(c, value)
+		}
+		m.Indices[corner] = c
+	}
+}
+
+// GenerateNormals replaces the normals with averages of the normals of the triangles around each vertex, weighted by
+// the triangles' angles at the vertex. Triangles meeting at a vertex are only averaged together if their normals are
+// within creaseAngle (in radians) of each other, so a crease angle of 0 gives flat shading, π gives smooth shading,
+// and in between keeps sharp edges sharp while smoothing curves.
+//
+// Triangles are taken to meet at a vertex if they have vertices in the same position, even with different UVs, so
+// texture seams don't show in the lighting. Vertices that need several normals are copied, with their other
+// attributes, and the indices are changed to use the copies.
+func (m *Mesh) GenerateNormals(creaseAngle float32) {
+	if m.Normals == nil {
+		m.Normals = make([]Vec3, len(m.Positions))
+	}
+
+	triangles := m.Triangles()
+	faceNormals := make([]Vec3, len(triangles))
+	angles := make([]float32, len(m.Indices))
+	cornersAt := make(map[Vec3][]int)
+	for t, tri := range triangles {
+		// Degenerate triangles have no normal, and add nothing
+		if n := tri[1].Sub(tri[0]).Cross(tri[2].Sub(tri[0])); n.Len() > 0 {
+			faceNormals[t] = n.Normalize()
+		}
+		for k := 0; k < 3; k++ {
+			corner := 3*t + k
+			angles[corner] = m.cornerAngle(t, k)
+			cornersAt[tri[k]] = append(cornersAt[tri[k]], corner)
+		}
+	}
+
+	// Allow for rounding, so coplanar triangles are smoothed together even with no crease angle
+	minCos := float32(math.Cos(float64(creaseAngle))) - 1e-5
+	normals := make([]Vec4, len(m.Indices))
+	for _, corners := range cornersAt {
+		for _, c := range corners {
+			var sum Vec3
+			for _, d := range corners {
+				if faceNormals[c/3].Dot(faceNormals[d/3]) >= minCos {
+					sum = sum.Add(faceNormals[d/3].Mul(angles[d]))
+				}
+			}
+			if sum.Len() > 0 {
+				sum = sum.Normalize()
+			}
+			normals[c] = sum.Vec4(0)
+		}
+	}
+
+	m.assignCornerValues(normals, func(v uint32, n Vec4) { m.Normals[v] = n.Vec3() })
+}
+
+// GenerateTangents replaces the tangents with ones computed from the normals and UVs, in the way of Morten Mikkelsen's
+// MikkTSpace, which is the standard for baking normal maps (Blender, Substance and glTF use it). For each vertex,
+// the tangents of the triangles around it, from the directions of increasing U and V, are made perpendicular to the
+// vertex normal, normalized, and averaged weighted by the triangles' angles at the vertex. The W component is the
+// handedness, so mirrored UVs work. Vertices shared by triangles with mirrored and unmirrored UVs are copied, with
+// their other attributes, and the indices are changed to use the copies.
+//
+// Unlike the reference implementation, which also merges vertices that are equal in every attribute but have different
+// indices, this only averages over triangles that share a vertex index, which gives the same tangents for meshes
+// whose equal vertices are already shared. It returns an error if the mesh has no normals or no UVs.
+func (m *Mesh) GenerateTangents() error {
+	if len(m.Normals) != len(m.Positions) || len(m.UVs) != len(m.Positions) {
+		return errors.New("generating tangents needs a normal and UV for every vertex")
+	}
+	if m.Tangents == nil {
+		m.Tangents = make([]Vec4, len(m.Positions))
+	}
+	for v, n := range m.Normals {
+		// For the vertices no triangle uses
+		m.Tangents[v] = perpendicularTangent(n)
+	}
+
+	// Sums of the tangents at each vertex, kept apart by handedness
+	type handedVertex struct {
+		vertex     uint32
+		handedness float32
+	}
+	sums := make(map[handedVertex]Vec3)
+	handedness := make([]float32, len(m.Indices))
+	for t := 0; t < m.NumTriangles(); t++ {
+		a, b, c := m.Indices[3*t], m.Indices[3*t+1], m.Indices[3*t+2]
+		e1, e2 := m.Positions[b].Sub(m.Positions[a]), m.Positions[c].Sub(m.Positions[a])
+		uv1, uv2 := m.UVs[b].Sub(m.UVs[a]), m.UVs[c].Sub(m.UVs[a])
+
+		// Solve e1 = uv1[0] T + uv1[1] B, e2 = uv2[0] T + uv2[1] B for T and B, the directions of increasing U and V,
+		// dropping the determinant's magnitude, which only scales them
+		det := uv1[0]*uv2[1] - uv2[0]*uv1[1]
+		sign := float32(1)
+		if det < 0 {
+			sign = -1
+		}
+		tangent := e1.Mul(uv2[1]).Sub(e2.Mul(uv1[1])).Mul(sign)
+		bitangent := e2.Mul(uv1[0]).Sub(e1.Mul(uv2[0])).Mul(sign)
+
+		for k := 0; k < 3; k++ {
+			corner := 3*t + k
+			v := m.Indices[corner]
+			n := m.Normals[v]
+
+			projected := tangent.Sub(n.Mul(n.Dot(tangent)))
+			if det == 0 || projected.Len() == 0 {
+				// Degenerate UVs give no direction, but still count for which way around the vertex is
+				handedness[corner] = 1
+				continue
+			}
+			projected = projected.Normalize()
+
+			handedness[corner] = 1
+			if n.Cross(projected).Dot(bitangent) < 0 {
+				handedness[corner] = -1
+			}
+			key := handedVertex{v, handedness[corner]}
+			sums[key] = sums[key].Add(projected.Mul(m.cornerAngle(t, k)))
+		}
+	}
+
+	tangents := make([]Vec4, len(m.Indices))
+	for corner, h := range handedness {
+		v := m.Indices[corner]
+		n, t := m.Normals[v], sums[handedVertex{v, h}]
+		t = t.Sub(n.Mul(n.Dot(t)))
+		if t.Len() < 1e-6 {
+			tangents[corner] = perpendicularTangent(n).Vec3().Vec4(h)
+			continue
+		}
+		tangents[corner] = t.Normalize().Vec4(h)
+	}
+
+	m.assignCornerValues(tangents, func(v uint32, t Vec4) { m.Tangents[v] = t })
+	return nil
+}
+
+// perpendicularTangent returns a right handed tangent for a vertex whose UVs give no direction.
+func perpendicularTangent(normal Vec3) Vec4 {
+	if normal.Len() == 0 {
+		return Vec4{1, 0, 0, 1}
+	}
+	return anyPerpendicular(normal.Normalize()).Vec4(1)
+}
+
+// Vertex cache optimization
+
+// forsythCacheSize is the size of the simulated cache, which is larger than real vertex caches so the order suits
+// a range of them.
+const forsythCacheSize = 32
+
+// forsythVertexScore scores a vertex by how much drawing it next would help: more if it's recently used and so likely
+// in the cache, and more if few triangles are left to use it, so it isn't left behind.
+func forsythVertexScore(cachePosition, remaining int) float32 {
+	if remaining == 0 {
+		return -1
+	}
+
+	var score float32
+	switch {
+	case cachePosition < 0:
+	case cachePosition < 3:
+		// Used by the last triangle, which is penalized a little to avoid strips
+		score = .75
+	default:
+		score = float32(math.Pow(1-float64(cachePosition-3)/(forsythCacheSize-3), 1.5))
+	}
+
+	return score + 2/float32(math.Sqrt(float64(remaining)))
+}
+
+// OptimizeVertexCache reorders the triangles of an index list so their vertices are more often in the GPU's post
+// transform cache, with Tom Forsyth's linear speed vertex cache optimization: it repeatedly draws the triangle whose
+// vertices score highest. The triangles and their windings are unchanged. The result usually has an average cache miss
+// ratio of 0.6 to 0.7 vertices per triangle, down from 1 or more.
+func OptimizeVertexCache(indices []uint32, numVertices int) []uint32 {
+	numTriangles := len(indices) / 3
+
+	// The triangles of each vertex, which are removed as they're drawn
+	remaining := make([]int, numVertices)
+	for _, v := range indices[:3*numTriangles] {
+		remaining[v]++
+	}
+	offsets := make([]int, numVertices+1)
+	for v, n := range remaining {
+		offsets[v+1] = offsets[v] + n
+	}
+	vertexTriangles := make([]int, offsets[numVertices])
+	filled := make([]int, numVertices)
+	for i, v := range indices[:3*numTriangles] {
+		vertexTriangles[offsets[v]+filled[v]] = i / 3
+		filled[v]++
+	}
+
+	cachePosition := make([]int, numVertices)
+	scores := make([]float32, numVertices)
+	for v := range scores {
+		cachePosition[v] = -1
+		scores[v] = forsythVertexScore(-1, remaining[v])
+	}
+	triangleScores := make([]float32, numTriangles)
+	for t := range triangleScores {
+		triangleScores[t] = scores[indices[3*t]] + scores[indices[3*t+1]] + scores[indices[3*t+2]]
+	}
+
+	drawn := make([]bool, numTriangles)
+	result := make([]uint32, 0, 3*numTriangles)
+	var cache []uint32
+	best, next := -1, 0
+	for len(result) < 3*numTriangles {
+		if best < 0 {
+			// Nothing in the cache is left to draw, so start anywhere new
+			for drawn[next] {
+				next++
+			}
+			best = next
+			for t := next; t < numTriangles; t++ {
+				if !drawn[t] && triangleScores[t] > triangleScores[best] {
+					best = t
+				}
+			}
+		}
+
+		t := best
+		drawn[t] = true
+		result = append(result, indices[3*t:3*t+3]...)
+
+		// Move the triangle's vertices to the front of the cache, and remove the triangle from them
+		newCache := make([]uint32, 0, forsythCacheSize+3)
+		for _, v := range indices[3*t : 3*t+3] {
+			newCache = append(newCache, v)
+			list := vertexTriangles[offsets[v] : offsets[v]+remaining[v]]
+			for i, other := range list {
+				if other == t {
+					list[i] = list[len(list)-1]
+					break
+				}
+			}
+			remaining[v]--
+		}
+		for _, v := range cache {
+			if v != indices[3*t] && v != indices[3*t+1] && v != indices[3*t+2] {
+				newCache = append(newCache, v)
+			}
+		}
+
+		// Rescore the vertices in the cache, or just pushed out of it, and their triangles
+		best = -1
+		for i, v := range newCache {
+			if i < forsythCacheSize {
+				cachePosition[v] = i
+			} else {
+				cachePosition[v] = -1
+			}
+			scores[v] = forsythVertexScore(cachePosition[v], remaining[v])
+		}
+		for _, v := range newCache {
+			for _, other := range vertexTriangles[offsets[v] : offsets[v]+remaining[v]] {
+				triangleScores[other] = scores[indices[3*other]] + scores[indices[3*other+1]] + scores[indices[3*other+2]]
+				if best < 0 || triangleScores[other] > triangleScores[best] {
+					best = other
+				}
+			}
+		}
+
+		if len(newCache) > forsythCacheSize {
+			newCache = newCache[:forsythCacheSize]
+		}
+		cache = newCache
+	}
+
+	return result
+}
+
+// OptimizeVertexCache reorders the mesh's triangles with OptimizeVertexCache.
+func (m *Mesh) OptimizeVertexCache() {
+	m.Indices = OptimizeVertexCache(m.Indices, len(m.Positions))
+}
+
+// AverageCacheMissRatio simulates drawing the triangles of an index list through a FIFO vertex cache of the given
+// size, and returns the number of vertices transformed per triangle. It's 3 with no reuse, and at best about 0.5 for a
+// large regular mesh.
+func AverageCacheMissRatio(indices []uint32, cacheSize int) float32 {
+	if len(indices) < 3 {
+		return 0
+	}
+
+	inCache := make(map[uint32]bool)
+	var fifo []uint32
+	misses := 0
+	for _, v := range indices {
+		if inCache[v] {
+			continue
+		}
+		misses++
+		inCache[v] = true
+		fifo = append(fifo, v)
+		if len(fifo) > cacheSize {
+			delete(inCache, fifo[0])
+			fifo = fifo[1:]
+		}
+	}
+
+	return float32(misses) / float32(len(indices)/3)
+}
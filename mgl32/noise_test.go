@@ -0,0 +1,134 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// noiseValue discards the gradient or second distance of a noise function.
+func noiseValue(v float32, _ interface{}) float32 {
+	return v
+}
+
+func TestNoiseGolden(t *testing.T) {
+	n := NewNoise(42)
+	f := Fractal{Octaves: 5, Lacunarity: 2, Gain: .5}
+	p2, p3, p4 := Vec2{1.3, -2.6}, Vec3{1.3, -2.6, .7}, Vec4{1.3, -2.6, .7, 3.1}
+
+	tests := []struct {
+		name            string
+		value, expected float32
+	}{
+		{"Perlin2", noiseValue(n.Perlin2(p2)), .25393432},
+		{"Perlin3", noiseValue(n.Perlin3(p3)), .26399973},
+		{"Perlin4", noiseValue(n.Perlin4(p4)), -.18063635},
+		{"Simplex2", noiseValue(n.Simplex2(p2)), -.5601914},
+		{"Simplex3", noiseValue(n.Simplex3(p3)), .18184659},
+		{"Simplex4", noiseValue(n.Simplex4(p4)), -.13316543},
+		{"Worley2", noiseValue(n.Worley2(p2)), .6092609},
+		{"Worley3", noiseValue(n.Worley3(p3)), .3811865},
+		{"Worley4", noiseValue(n.Worley4(p4)), .6166854},
+		{"FBm3", noiseValue(f.FBm3(n.Simplex3, p3)), -.13768227},
+		{"Ridged3", noiseValue(f.Ridged3(n.Simplex3, p3)), .4621182},
+		{"Turbulence3", noiseValue(f.Turbulence3(n.Perlin3, p3)), .1954644},
+	}
+	for _, c := range tests {
+		if Abs(c.value-c.expected) > 1e-5 {
+			t.Errorf("%s with seed 42 is %v, expected %v", c.name, c.value, c.expected)
+		}
+	}
+
+	if noiseValue(NewNoise(43).Simplex3(p3)) == noiseValue(n.Simplex3(p3)) {
+		t.Errorf("Noise with different seeds is the same")
+	}
+}
+
+func TestNoiseRange(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	n := NewNoise(rand.Int63())
+
+	for i := 0; i < 10000; i++ {
+		p := Vec4{rand.Float32()*200 - 100, rand.Float32()*200 - 100, rand.Float32()*200 - 100, rand.Float32()*200 - 100}
+		values := []float32{
+			noiseValue(n.Perlin2(p.Vec2())), noiseValue(n.Perlin3(p.Vec3())), noiseValue(n.Perlin4(p)),
+			noiseValue(n.Simplex2(p.Vec2())), noiseValue(n.Simplex3(p.Vec3())), noiseValue(n.Simplex4(p)),
+		}
+		for j, v := range values {
+			if v < -1.1 || v > 1.1 {
+				t.Errorf("Gradient noise %d at %v is %v, out of range", j, p, v)
+			}
+		}
+
+		cell := Vec3{float32(int(p[0])), float32(int(p[1])), float32(int(p[2]))}
+		if v, _ := n.Perlin3(cell); v != 0 {
+			t.Errorf("Perlin noise at integer point %v is %v, expected 0", cell, v)
+		}
+
+		q := p.Add(Vec4{rand.Float32() - .5, rand.Float32() - .5, rand.Float32() - .5, rand.Float32() - .5})
+		f1, f2 := n.Worley4(p)
+		g1, _ := n.Worley4(q)
+		if f1 > f2 || f1 < 0 {
+			t.Errorf("Worley distances at %v are %v and %v", p, f1, f2)
+		}
+		// F1 is a distance, so it can't change faster than the point moves
+		if Abs(f1-g1) > q.Sub(p).Len()+1e-5 {
+			t.Errorf("Worley F1 changes from %v to %v between %v and %v", f1, g1, p, q)
+		}
+	}
+}
+
+func TestNoiseGradient(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	n := NewNoise(rand.Int63())
+	f := Fractal{Octaves: 4, Lacunarity: 2, Gain: .5}
+
+	tests := []struct {
+		name string
+		f    func(Vec4) (float32, Vec4)
+	}{
+		{"Perlin2", func(p Vec4) (float32, Vec4) { v, g := n.Perlin2(p.Vec2()); return v, g.Vec4(0, 0) }},
+		{"Perlin3", func(p Vec4) (float32, Vec4) { v, g := n.Perlin3(p.Vec3()); return v, g.Vec4(0) }},
+		{"Perlin4", n.Perlin4},
+		{"Simplex2", func(p Vec4) (float32, Vec4) { v, g := n.Simplex2(p.Vec2()); return v, g.Vec4(0, 0) }},
+		{"Simplex3", func(p Vec4) (float32, Vec4) { v, g := n.Simplex3(p.Vec3()); return v, g.Vec4(0) }},
+		{"Simplex4", n.Simplex4},
+		{"FBm2", func(p Vec4) (float32, Vec4) { v, g := f.FBm2(n.Perlin2, p.Vec2()); return v, g.Vec4(0, 0) }},
+		{"FBm3", func(p Vec4) (float32, Vec4) { v, g := f.FBm3(n.Simplex3, p.Vec3()); return v, g.Vec4(0) }},
+		{"FBm4", func(p Vec4) (float32, Vec4) { return f.FBm4(n.Simplex4, p) }},
+		{"Ridged3", func(p Vec4) (float32, Vec4) { v, g := f.Ridged3(n.Perlin3, p.Vec3()); return v, g.Vec4(0) }},
+		{"Turbulence3", func(p Vec4) (float32, Vec4) { v, g := f.Turbulence3(n.Simplex3, p.Vec3()); return v, g.Vec4(0) }},
+	}
+
+	const h = 1e-3
+	for _, c := range tests {
+		wrong := 0
+		for i := 0; i < 200; i++ {
+			p := Vec4{rand.Float32()*20 - 10, rand.Float32()*20 - 10, rand.Float32()*20 - 10, rand.Float32()*20 - 10}
+			_, gradient := c.f(p)
+
+			var numeric Vec4
+			for j := range p {
+				dp := Vec4{}
+				dp[j] = h
+				plus, _ := c.f(p.Add(dp))
+				minus, _ := c.f(p.Sub(dp))
+				numeric[j] = (plus - minus) / (2 * h)
+			}
+
+			if numeric.Sub(gradient).Len() > .02*(1+gradient.Len()) {
+				wrong++
+				t.Logf("%s gradient at %v is %v, expected about %v", c.name, p, gradient, numeric)
+			}
+		}
+
+		// Ridged and turbulence have creases where the gradient jumps, which a sample may straddle
+		if wrong > 0 && (c.name != "Ridged3" && c.name != "Turbulence3" || wrong > 40) {
+			t.Errorf("%s gradient is wrong at %d of 200 points", c.name, wrong)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// The polygon functions take a polygon as a list of its vertices in order, without repeating the first
+// vertex at the end. Either winding is accepted unless otherwise noted. Counter-clockwise here means
+// counter-clockwise in a Y-up coordinate system, such as GL's; in a Y-down system like pixel coordinates it
+// appears clockwise.
+
+// cross2D returns the Z component of the cross product of (b-a) and (c-a), which is positive if a, b, c turn left.
+func cross2D(a, b, c Vec2) float32 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// PolygonSignedArea returns the area of the polygon, which is positive if the polygon is wound counter-clockwise
+// and negative if it's wound clockwise. The result is meaningless for self-intersecting polygons.
+func PolygonSignedArea(polygon []Vec2) float32 {
+	var area float32
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+
+	return area / 2
+}
+
+// PolygonArea returns the (unsigned) area of the polygon.
+func PolygonArea(polygon []Vec2) float32 {
+	return Abs(PolygonSignedArea(polygon))
+}
+
+// PolygonIsCCW returns whether the polygon is wound counter-clockwise.
+func PolygonIsCCW(polygon []Vec2) bool {
+	return PolygonSignedArea(polygon) > 0
+}
+
+// PolygonIsConvex returns whether the polygon is convex. Collinear vertices are allowed, but a
+// self-intersecting polygon (such as a star drawn with one stroke) is never convex.
+func PolygonIsConvex(polygon []Vec2) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+
+	var sign float32
+	var turning float64
+	n := len(polygon)
+	for i := range polygon {
+		a, b, c := polygon[i], polygon[(i+1)%n], polygon[(i+2)%n]
+		cross := cross2D(a, b, c)
+		if cross != 0 {
+			if sign == 0 {
+				sign = cross
+			} else if (cross > 0) != (sign > 0) {
+				return false
+			}
+		}
+
+		e1, e2 := b.Sub(a), c.Sub(b)
+		turning += math.Atan2(float64(e1[0]*e2[1]-e1[1]*e2[0]), float64(e1.Dot(e2)))
+	}
+
+	// A simple polygon turns through exactly one full circle
+	return sign != 0 && math.Abs(math.Abs(turning)-2*math.Pi) < 1e-3
+}
+
+// PointInPolygon returns whether p is inside the polygon, using the even-odd rule. Points exactly on the
+// boundary may be considered either inside or outside.
+func PointInPolygon(p Vec2, polygon []Vec2) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// ConvexHull2D returns the convex hull of a set of points, using Andrew's monotone chain algorithm. The hull
+// is wound counter-clockwise starting from the point with the lowest X (and lowest Y among ties). Points on the
+// hull's edges are not included. The input slice is not modified.
+func ConvexHull2D(points []Vec2) []Vec2 {
+	sorted := append([]Vec2(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+
+	if len(sorted) < 3 {
+		if len(sorted) == 2 && sorted[0] == sorted[1] {
+			return sorted[:1]
+		}
+		return sorted
+	}
+
+	hull := make([]Vec2, 0, 2*len(sorted))
+	// Lower hull
+	for _, p := range sorted {
+		for len(hull) >= 2 && cross2D(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	// Upper hull
+	for i, lower := len(sorted)-2, len(hull)+1; i >= 0; i-- {
+		p := sorted[i]
+		for len(hull) >= lower && cross2D(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	// The last point is the same as the first
+	return hull[:len(hull)-1]
+}
+
+// TriangulatePolygon triangulates a simple polygon, optionally with holes, by ear clipping. The result is a list of
+// counter-clockwise triangles (three indices per triangle) into the vertices of polygon followed by the vertices of each hole
+// in order, which can be used as an index buffer for GL_TRIANGLES.
+//
+// The polygon and its holes may be wound either way. Holes must be entirely inside the polygon and must not overlap each other.
+// An error is returned if the polygon has fewer than 3 vertices, or if it's self-intersecting to the point that no ear can be found.
+func TriangulatePolygon(polygon []Vec2, holes ...[]Vec2) ([]uint32, error) {
+	if len(polygon) < 3 {
+		return nil, errors.New("a polygon needs at least 3 vertices to be triangulated")
+	}
+
+	vertices := append([]Vec2(nil), polygon...)
+	ring := polygonRing(0, polygon, true)
+
+	type hole struct {
+		ring []uint32
+		maxX int
+	}
+	sortedHoles := make([]hole, 0, len(holes))
+	for _, h := range holes {
+		if len(h) < 3 {
+			continue
+		}
+
+		r := polygonRing(uint32(len(vertices)), h, false)
+		vertices = append(vertices, h...)
+
+		maxX := 0
+		for i, idx := range r {
+			if vertices[idx][0] > vertices[r[maxX]][0] {
+				maxX = i
+			}
+		}
+		sortedHoles = append(sortedHoles, hole{r, maxX})
+	}
+
+	// Holes are bridged in from the right, so the rightmost ones must be joined first to
+	// make sure the bridges don't cross any hole that hasn't been joined yet.
+	sort.SliceStable(sortedHoles, func(i, j int) bool {
+		return vertices[sortedHoles[i].ring[sortedHoles[i].maxX]][0] > vertices[sortedHoles[j].ring[sortedHoles[j].maxX]][0]
+	})
+
+	for _, h := range sortedHoles {
+		var err error
+		if ring, err = bridgeHole(vertices, ring, h.ring, h.maxX); err != nil {
+			return nil, err
+		}
+	}
+
+	return earClip(vertices, ring)
+}
+
+// polygonRing returns the indices base, base+1... of polygon, reversed if necessary so it's wound counter-clockwise
+// (or clockwise, if ccw is false).
+func polygonRing(base uint32, polygon []Vec2, ccw bool) []uint32 {
+	ring := make([]uint32, len(polygon))
+	reverse := PolygonIsCCW(polygon) != ccw
+	for i := range ring {
+		if reverse {
+			ring[i] = base + uint32(len(polygon)-1-i)
+		} else {
+			ring[i] = base + uint32(i)
+		}
+	}
+
+	return ring
+}
+
+// bridgeHole joins a clockwise hole into the counter-clockwise outer ring, by cutting a two way bridge from the hole's
+// rightmost vertex to a vertex of the ring that is visible from it. This is the method described by David Eberly in
+// "Triangulation by Ear Clipping".
+func bridgeHole(vertices []Vec2, ring, hole []uint32, maxX int) ([]uint32, error) {
+	m := vertices[hole[maxX]]
+
+	// Cast a ray from m along +X, and find the closest edge it hits
+	bestX := float32(math.Inf(1))
+	bridge := -1
+	for i := range ring {
+		a, b := vertices[ring[i]], vertices[ring[(i+1)%len(ring)]]
+		if (a[1] > m[1]) == (b[1] > m[1]) && a[1] != m[1] && b[1] != m[1] {
+			continue
+		}
+
+		var x float32
+		if a[1] == b[1] {
+			x = a[0]
+			SetMin(&x, &b[0])
+		} else {
+			x = a[0] + (m[1]-a[1])*(b[0]-a[0])/(b[1]-a[1])
+		}
+		if x < m[0] || x >= bestX {
+			continue
+		}
+
+		bestX = x
+		// The candidate is the endpoint of the edge furthest to the right
+		if a[0] > b[0] {
+			bridge = i
+		} else {
+			bridge = (i + 1) % len(ring)
+		}
+	}
+
+	if bridge < 0 {
+		return nil, errors.New("hole is not inside the polygon")
+	}
+
+	// The candidate may be hidden behind a reflex vertex inside the triangle (m, hit point, candidate).
+	// If so, the reflex vertex with the smallest angle to the ray is visible instead.
+	hit, candidate := Vec2{bestX, m[1]}, vertices[ring[bridge]]
+	if candidate != hit {
+		bestAngle, bestDist := float32(math.Inf(1)), float32(math.Inf(1))
+		for i := range ring {
+			p := vertices[ring[i]]
+			prev, next := vertices[ring[(i+len(ring)-1)%len(ring)]], vertices[ring[(i+1)%len(ring)]]
+			if i == bridge || cross2D(prev, p, next) >= 0 || !pointInTriangle(p, m, hit, candidate) {
+				continue
+			}
+
+			d := p.Sub(m)
+			angle := Abs(d[1]) / d.Len()
+			if angle < bestAngle || (angle == bestAngle && d.Len() < bestDist) {
+				bridge, bestAngle, bestDist = i, angle, d.Len()
+			}
+		}
+	}
+
+	joined := make([]uint32, 0, len(ring)+len(hole)+2)
+	joined = append(joined, ring[:bridge+1]...)
+	for i := 0; i <= len(hole); i++ {
+		joined = append(joined, hole[(maxX+i)%len(hole)])
+	}
+	joined = append(joined, ring[bridge:]...)
+
+	return joined, nil
+}
+
+// pointInTriangle returns whether p is inside or on the edge of the triangle abc, with either winding.
+func pointInTriangle(p, a, b, c Vec2) bool {
+	d1, d2, d3 := cross2D(a, b, p), cross2D(b, c, p), cross2D(c, a, p)
+	hasNeg := d1 < 0 || d2 < 0 || d3 < 0
+	hasPos := d1 > 0 || d2 > 0 || d3 > 0
+
+	return !(hasNeg && hasPos)
+}
+
+// earClip triangulates a counter-clockwise ring of vertex indices, which may visit the same vertex more than once
+// where holes have been bridged in.
+func earClip(vertices []Vec2, ring []uint32) ([]uint32, error) {
+	indices := make([]uint32, 0, 3*(len(ring)-2))
+	ring = append([]uint32(nil), ring...)
+
+	// Scanning carries on from each clipped vertex, rather than starting over, so a pass around the ring clips many
+	// ears. stalled counts the vertices tried since the ring last changed; once all of them have been, none is an ear.
+	for i, stalled := 0, 0; len(ring) > 3; {
+		if stalled >= len(ring) {
+			return nil, errors.New("could not triangulate polygon, it may be self-intersecting")
+		}
+
+		n := len(ring)
+		i %= n
+		prev, curr, next := ring[(i+n-1)%n], ring[i], ring[(i+1)%n]
+		a, b, c := vertices[prev], vertices[curr], vertices[next]
+
+		cross := cross2D(a, b, c)
+		if cross == 0 {
+			// Collinear (or duplicated) vertices can be dropped without leaving a gap
+			ring = append(ring[:i], ring[i+1:]...)
+			stalled = 0
+			continue
+		}
+
+		if cross > 0 && isEar(vertices, ring, a, b, c) {
+			indices = append(indices, prev, curr, next)
+			ring = append(ring[:i], ring[i+1:]...)
+			stalled = 0
+			continue
+		}
+
+		i++
+		stalled++
+	}
+
+	if len(ring) == 3 && cross2D(vertices[ring[0]], vertices[ring[1]], vertices[ring[2]]) != 0 {
+		indices = append(indices, ring...)
+	}
+
+	return indices, nil
+}
+
+// isEar returns whether no vertex of the ring is inside the convex corner abc.
+func isEar(vertices []Vec2, ring []uint32, a, b, c Vec2) bool {
+	for _, idx := range ring {
+		p := vertices[idx]
+		if p == a || p == b || p == c {
+			continue
+		}
+		if pointInTriangle(p, a, b, c) {
+			return false
+		}
+	}
+
+	return true
+}
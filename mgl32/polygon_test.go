@@ -0,0 +1,187 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPolygonAreaWinding(t *testing.T) {
+	square := []Vec2{{0, 0}, {2, 0}, {2, 2}, {0, 2}}
+	if a := PolygonSignedArea(square); !FloatEqual(a, 4) {
+		t.Errorf("Signed area of CCW square is %v, expected 4", a)
+	}
+	if !PolygonIsCCW(square) {
+		t.Errorf("CCW square is reported as clockwise")
+	}
+
+	reversed := []Vec2{{0, 2}, {2, 2}, {2, 0}, {0, 0}}
+	if a := PolygonSignedArea(reversed); !FloatEqual(a, -4) {
+		t.Errorf("Signed area of CW square is %v, expected -4", a)
+	}
+	if a := PolygonArea(reversed); !FloatEqual(a, 4) {
+		t.Errorf("Area of CW square is %v, expected 4", a)
+	}
+}
+
+func TestPolygonIsConvex(t *testing.T) {
+	if !PolygonIsConvex([]Vec2{{0, 0}, {2, 0}, {2, 2}, {0, 2}}) {
+		t.Errorf("Square is reported as not convex")
+	}
+	if !PolygonIsConvex([]Vec2{{0, 2}, {2, 2}, {2, 0}, {1, 0}, {0, 0}}) {
+		t.Errorf("Clockwise square with collinear vertex is reported as not convex")
+	}
+	if PolygonIsConvex([]Vec2{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}) {
+		t.Errorf("L shape is reported as convex")
+	}
+
+	star := make([]Vec2, 5)
+	for i := range star {
+		star[i] = SphericalToCartesian(1, DegToRad(90), DegToRad(float32(i*144))).Vec2()
+	}
+	if PolygonIsConvex(star) {
+		t.Errorf("Pentagram is reported as convex")
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	lShape := []Vec2{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}
+
+	inside := []Vec2{{.5, .5}, {1.5, .5}, {.5, 1.5}}
+	outside := []Vec2{{1.5, 1.5}, {-1, 1}, {3, .5}, {.5, 3}}
+
+	for _, p := range inside {
+		if !PointInPolygon(p, lShape) {
+			t.Errorf("Point %v is reported outside the polygon", p)
+		}
+	}
+	for _, p := range outside {
+		if PointInPolygon(p, lShape) {
+			t.Errorf("Point %v is reported inside the polygon", p)
+		}
+	}
+}
+
+func TestConvexHull2D(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := make([]Vec2, 200)
+	for i := range points {
+		points[i] = Vec2{rand.Float32()*2 - 1, rand.Float32()*2 - 1}
+	}
+	// Make sure the corners are on the hull
+	corners := []Vec2{{-2, -2}, {2, -2}, {2, 2}, {-2, 2}}
+	points = append(points, corners...)
+
+	hull := ConvexHull2D(points)
+	if len(hull) != 4 {
+		t.Fatalf("Hull has %d points, expected the 4 corners: %v", len(hull), hull)
+	}
+	for i, c := range corners {
+		if hull[i] != c {
+			t.Errorf("Hull point %d is %v, expected %v", i, hull[i], c)
+		}
+	}
+
+	if hull := ConvexHull2D([]Vec2{{0, 0}, {1, 1}, {2, 2}}); len(hull) != 2 {
+		t.Errorf("Hull of collinear points is %v, expected the two end points", hull)
+	}
+}
+
+func checkTriangulation(t *testing.T, name string, indices []uint32, vertices []Vec2, expectedArea float32) {
+	var area float32
+	for i := 0; i < len(indices); i += 3 {
+		a, b, c := vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]
+		triArea := cross2D(a, b, c) / 2
+		if triArea <= 0 {
+			t.Errorf("%s: triangle %d is not counter-clockwise", name, i/3)
+		}
+		area += triArea
+	}
+
+	if !FloatEqualThreshold(area, expectedArea, 1e-4) {
+		t.Errorf("%s: triangles cover an area of %v, expected %v", name, area, expectedArea)
+	}
+}
+
+func TestTriangulatePolygon(t *testing.T) {
+	// Clockwise, to make sure the winding is fixed up
+	lShape := []Vec2{{0, 2}, {1, 2}, {1, 1}, {2, 1}, {2, 0}, {0, 0}}
+	indices, err := TriangulatePolygon(lShape)
+	if err != nil {
+		t.Fatalf("Triangulating L shape failed: %v", err)
+	}
+	if len(indices) != 3*(len(lShape)-2) {
+		t.Errorf("L shape triangulated to %d triangles, expected %d", len(indices)/3, len(lShape)-2)
+	}
+	checkTriangulation(t, "L shape", indices, lShape, 3)
+
+	if _, err := TriangulatePolygon(lShape[:2]); err == nil {
+		t.Errorf("Triangulating a polygon with 2 vertices did not fail")
+	}
+}
+
+func TestTriangulatePolygonWithHoles(t *testing.T) {
+	outer := []Vec2{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	hole1 := []Vec2{{2, 2}, {4, 2}, {4, 4}, {2, 4}}
+	hole2 := []Vec2{{6, 6}, {8, 6}, {7, 8}}
+
+	indices, err := TriangulatePolygon(outer, hole1, hole2)
+	if err != nil {
+		t.Fatalf("Triangulating polygon with holes failed: %v", err)
+	}
+
+	vertices := append(append(append([]Vec2(nil), outer...), hole1...), hole2...)
+	checkTriangulation(t, "polygon with holes", indices, vertices, 100-4-2)
+
+	// n + 2h - 2 triangles
+	if expected := len(vertices) + 2*2 - 2; len(indices)/3 != expected {
+		t.Errorf("Polygon with holes triangulated to %d triangles, expected %d", len(indices)/3, expected)
+	}
+
+	// No triangle may cover the inside of a hole
+	for i := 0; i < len(indices); i += 3 {
+		centroid := vertices[indices[i]].Add(vertices[indices[i+1]]).Add(vertices[indices[i+2]]).Mul(1.0 / 3)
+		if PointInPolygon(centroid, hole1) || PointInPolygon(centroid, hole2) {
+			t.Errorf("Triangle %d is inside a hole", i/3)
+		}
+	}
+}
+
+// starPolygon returns a counter-clockwise star with n points, which has a reflex vertex between each pair of points.
+func starPolygon(n int) []Vec2 {
+	star := make([]Vec2, 2*n)
+	for i := range star {
+		radius := float32(1)
+		if i%2 == 1 {
+			radius = .5
+		}
+		angle := float64(i) * math.Pi / float64(n)
+		star[i] = Vec2{radius * float32(math.Cos(angle)), radius * float32(math.Sin(angle))}
+	}
+	return star
+}
+
+func TestTriangulateStar(t *testing.T) {
+	star := starPolygon(500)
+	indices, err := TriangulatePolygon(star)
+	if err != nil {
+		t.Fatalf("Triangulating a star failed: %v", err)
+	}
+	if len(indices) != 3*(len(star)-2) {
+		t.Errorf("Star triangulated to %d triangles, expected %d", len(indices)/3, len(star)-2)
+	}
+	checkTriangulation(t, "star", indices, star, PolygonArea(star))
+}
+
+func BenchmarkTriangulatePolygon(b *testing.B) {
+	star := starPolygon(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TriangulatePolygon(star)
+	}
+}
@@ -182,9 +182,15 @@ func (q1 Quat) Mat4() Mat4 {
 	return Mat4{1 - 2*y*y - 2*z*z, 2*x*y + 2*w*z, 2*x*z - 2*w*y, 0, 2*x*y - 2*w*z, 1 - 2*x*x - 2*z*z, 2*y*z + 2*w*x, 0, 2*x*z + 2*w*y, 2*y*z - 2*w*x, 1 - 2*x*x - 2*y*y, 0, 0, 0, 0, 1}
 }
 
+// Mat4ToQuat returns the quaternion of the rotation in the upper 3x3 of a matrix, which must be a pure rotation.
+// Scale must be divided out of the columns first.
+func Mat4ToQuat(m Mat4) Quat {
+	return basisToQuat(m.Col(0).Vec3(), m.Col(1).Vec3(), m.Col(2).Vec3())
+}
+
 // The dot product between two quaternions, equivalent to if this was a Vec4
 func (q1 Quat) Dot(q2 Quat) float32 {
-	return q1.W*q1.W + q1.V[0]*q1.V[0] + q1.V[1]*q1.V[1] + q1.V[2]*q1.V[2]
+	return q1.W*q2.W + q1.V[0]*q2.V[0] + q1.V[1]*q2.V[1] + q1.V[2]*q2.V[2]
 }
 
 // Returns whether the quaternions are approximately equal, as if
@@ -217,11 +223,17 @@ func QuatSlerp(q1, q2 Quat, amount float32) Quat {
 	// This is here for precision errors, I'm perfectly aware the *technically* the dot is bound [-1,1], but since Acos will freak out if it's not (even if it's just a liiiiitle bit over due to normal error) we need to clamp it
 	dot = Clamp(dot, -1, 1)
 
+	// The quaternions are too close together to find a direction between them; they're also close enough that
+	// Nlerp is indistinguishable
+	if dot > 1-1e-4 {
+		return QuatNlerp(q1, q2, amount)
+	}
+
 	theta := float32(math.Acos(float64(dot))) * amount
 	c, s := float32(math.Cos(float64(theta))), float32(math.Sin(float64(theta)))
 	rel := q2.Sub(q1.Scale(dot)).Normalize()
 
-	return q2.Sub(q1.Scale(c)).Add(rel.Scale(s))
+	return q1.Scale(c).Add(rel.Scale(s))
 }
 
 // *L*inear Int*erp*olation between two Quaternions, cheap and simple.
@@ -0,0 +1,204 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+// The inertia tensors here are about the center of mass of a solid body of uniform density, in the body's own
+// coordinate system. They are Mat3s acting on angular velocities in radians per second.
+
+// BoxInertia returns the inertia tensor of a solid box with the given mass and size along each axis.
+func BoxInertia(mass float32, size Vec3) Mat3 {
+	x, y, z := size[0]*size[0], size[1]*size[1], size[2]*size[2]
+	return Diag3(Vec3{y + z, x + z, x + y}).Mul(mass / 12)
+}
+
+// SphereInertia returns the inertia tensor of a solid sphere.
+func SphereInertia(mass, radius float32) Mat3 {
+	return Ident3().Mul(2 * mass * radius * radius / 5)
+}
+
+// CylinderInertia returns the inertia tensor of a solid cylinder around the Y axis, such as made by CylinderMesh.
+func CylinderInertia(mass, radius, height float32) Mat3 {
+	side := mass * (3*radius*radius + height*height) / 12
+	return Diag3(Vec3{side, mass * radius * radius / 2, side})
+}
+
+// MeshInertia returns the mass, center of mass and inertia tensor (about the center of mass) of a solid of uniform
+// density enclosed by a triangle mesh. The mesh must be closed, and its triangles wound counter-clockwise when seen from
+// outside, as with the meshes made by this package. indices contains three vertex indices per triangle.
+//
+// The solid is split into tetrahedra between each triangle and the origin, whose signed volumes and covariance matrices
+// add up to those of the whole solid, as described by Jonathan Blow and Atman Binstock in "How to find the inertia tensor
+// (or other mass properties) of a 3D solid body represented by a triangle mesh".
+func MeshInertia(positions []Vec3, indices []uint32, density float32) (mass float32, centerOfMass Vec3, inertia Mat3) {
+	// The covariance matrix of the tetrahedron with corners at the origin and the three unit vectors
+	canonical := Mat3{2, 1, 1, 1, 2, 1, 1, 1, 2}.Mul(1.0 / 120)
+
+	var covariance Mat3
+	var weightedCenter Vec3
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := positions[indices[i]], positions[indices[i+1]], positions[indices[i+2]]
+		// The tetrahedron is the canonical one transformed by this matrix
+		m := Mat3FromCols(a, b, c)
+		det := m.Det()
+
+		mass += det / 6
+		weightedCenter = weightedCenter.Add(a.Add(b).Add(c).Mul(det / 24))
+		covariance = covariance.Add(m.Mul3(canonical).Mul3(m.Transpose()).Mul(det))
+	}
+
+	if mass == 0 {
+		return 0, Vec3{}, Mat3{}
+	}
+	centerOfMass = weightedCenter.Mul(1 / mass)
+
+	// Move the covariance to the center of mass
+	covariance = covariance.Sub(centerOfMass.OuterProd3(centerOfMass).Mul(mass)).Mul(density)
+	mass *= density
+
+	return mass, centerOfMass, Ident3().Mul(covariance.Trace()).Sub(covariance)
+}
+
+// ParallelAxis returns the inertia tensor of a body about a point displaced by offset from its center of mass, given its
+// inertia tensor about the center of mass. This is how the inertia of a compound body is found: move each part's inertia
+// to the compound's center of mass, and add them up.
+func ParallelAxis(inertia Mat3, mass float32, offset Vec3) Mat3 {
+	return inertia.Add(Ident3().Mul(offset.Dot(offset)).Sub(offset.OuterProd3(offset)).Mul(mass))
+}
+
+// RotateInertia returns the inertia tensor of a body rotated by the rotation matrix r, such as the tensor in world space
+// given the tensor in body space and the body's orientation.
+func RotateInertia(inertia, r Mat3) Mat3 {
+	return r.Mul3(inertia).Mul3(r.Transpose())
+}
+
+// IntegrateOrientation returns the orientation q after rotating at angularVelocity (in radians per second, in the same
+// space as q rotates into) for dt seconds. The rotation is exact for a constant angular velocity.
+func IntegrateOrientation(q Quat, angularVelocity Vec3, dt float32) Quat {
+	speed := angularVelocity.Len()
+	if speed == 0 {
+		return q
+	}
+
+	return QuatRotate(speed*dt, angularVelocity.Mul(1/speed)).Mul(q).Normalize()
+}
+
+// RigidBody is the state of a rigid body, for stepping through time with an integrator. Position is the body's center of
+// mass, and Orientation rotates from the body's space into world space. Velocities are in world space, with the angular
+// velocity in radians per second.
+type RigidBody struct {
+	Mass float32
+	// The inertia tensor about the center of mass, in body space
+	Inertia Mat3
+
+	Position        Vec3
+	Orientation     Quat
+	LinearVelocity  Vec3
+	AngularVelocity Vec3
+}
+
+// A ForceFunc returns the total force and torque (about the center of mass) acting on the body at time t, in world space.
+// Integrators may call it several times per step, with intermediate states of the body.
+type ForceFunc func(b *RigidBody, t float32) (force, torque Vec3)
+
+// WorldInertia returns the body's inertia tensor in world space.
+func (b *RigidBody) WorldInertia() Mat3 {
+	return RotateInertia(b.Inertia, b.Orientation.Mat4().Mat3())
+}
+
+// LinearMomentum returns the body's linear momentum.
+func (b *RigidBody) LinearMomentum() Vec3 {
+	return b.LinearVelocity.Mul(b.Mass)
+}
+
+// AngularMomentum returns the body's angular momentum about its center of mass, in world space.
+func (b *RigidBody) AngularMomentum() Vec3 {
+	return b.WorldInertia().Mul3x1(b.AngularVelocity)
+}
+
+// KineticEnergy returns the body's kinetic energy, both linear and rotational.
+func (b *RigidBody) KineticEnergy() float32 {
+	return (b.Mass*b.LinearVelocity.Dot(b.LinearVelocity) + b.AngularVelocity.Dot(b.AngularMomentum())) / 2
+}
+
+// angularAcceleration returns the rate of change of the angular velocity under torque. Without torque, the angular
+// velocity of a body spinning around anything but a principal axis still changes, since its angular momentum is what's conserved.
+func (b *RigidBody) angularAcceleration(torque Vec3) Vec3 {
+	inertia := b.WorldInertia()
+	gyroscopic := b.AngularVelocity.Cross(inertia.Mul3x1(b.AngularVelocity))
+	return inertia.Inv().Mul3x1(torque.Sub(gyroscopic))
+}
+
+// StepSemiImplicitEuler advances the body by dt seconds from time t, updating the velocities first and then moving the body
+// with the new velocities. This is the cheapest integrator, and unlike explicit Euler it doesn't gain energy over time.
+func (b *RigidBody) StepSemiImplicitEuler(f ForceFunc, t, dt float32) {
+	force, torque := f(b, t)
+
+	b.LinearVelocity = b.LinearVelocity.Add(force.Mul(dt / b.Mass))
+	b.AngularVelocity = b.AngularVelocity.Add(b.angularAcceleration(torque).Mul(dt))
+
+	b.Position = b.Position.Add(b.LinearVelocity.Mul(dt))
+	b.Orientation = IntegrateOrientation(b.Orientation, b.AngularVelocity, dt)
+}
+
+// StepVerlet advances the body by dt seconds from time t with the velocity Verlet integrator, which is second order
+// accurate and conserves energy well over long simulations. Forces must not depend on the velocities for it to be accurate.
+func (b *RigidBody) StepVerlet(f ForceFunc, t, dt float32) {
+	force, torque := f(b, t)
+	acceleration, angularAcceleration := force.Mul(1/b.Mass), b.angularAcceleration(torque)
+
+	// Move with the velocity at the middle of the step...
+	b.LinearVelocity = b.LinearVelocity.Add(acceleration.Mul(dt / 2))
+	b.AngularVelocity = b.AngularVelocity.Add(angularAcceleration.Mul(dt / 2))
+	b.Position = b.Position.Add(b.LinearVelocity.Mul(dt))
+	b.Orientation = IntegrateOrientation(b.Orientation, b.AngularVelocity, dt)
+
+	// ...then finish updating the velocity with the forces at the end of the step
+	force, torque = f(b, t+dt)
+	b.LinearVelocity = b.LinearVelocity.Add(force.Mul(dt / 2 / b.Mass))
+	b.AngularVelocity = b.AngularVelocity.Add(b.angularAcceleration(torque).Mul(dt / 2))
+}
+
+// bodyDerivative is the rate of change of a RigidBody's position, orientation and momenta.
+type bodyDerivative struct {
+	velocity        Vec3
+	angularVelocity Vec3
+	force, torque   Vec3
+}
+
+// StepRK4 advances the body by dt seconds from time t with the classic fourth order Runge-Kutta integrator. It's the most
+// accurate integrator here for a given step size, but evaluates the forces four times per step. The body's momenta are
+// integrated rather than its velocities, so angular momentum is conserved in the absence of torque.
+func (b *RigidBody) StepRK4(f ForceFunc, t, dt float32) {
+	momentum, angularMomentum := b.LinearMomentum(), b.AngularMomentum()
+
+	// evaluate returns the derivative at the state reached by following d for h seconds from the start of the step
+	evaluate := func(d bodyDerivative, h float32) bodyDerivative {
+		state := *b
+		state.Position = b.Position.Add(d.velocity.Mul(h))
+		state.Orientation = IntegrateOrientation(b.Orientation, d.angularVelocity, h)
+		state.LinearVelocity = momentum.Add(d.force.Mul(h)).Mul(1 / b.Mass)
+		state.AngularVelocity = state.WorldInertia().Inv().Mul3x1(angularMomentum.Add(d.torque.Mul(h)))
+
+		force, torque := f(&state, t+h)
+		return bodyDerivative{state.LinearVelocity, state.AngularVelocity, force, torque}
+	}
+
+	k1 := evaluate(bodyDerivative{}, 0)
+	k2 := evaluate(k1, dt/2)
+	k3 := evaluate(k2, dt/2)
+	k4 := evaluate(k3, dt)
+
+	combine := func(a, b, c, d Vec3) Vec3 {
+		return a.Add(b.Mul(2)).Add(c.Mul(2)).Add(d).Mul(1.0 / 6)
+	}
+
+	b.Position = b.Position.Add(combine(k1.velocity, k2.velocity, k3.velocity, k4.velocity).Mul(dt))
+	b.Orientation = IntegrateOrientation(b.Orientation, combine(k1.angularVelocity, k2.angularVelocity, k3.angularVelocity, k4.angularVelocity), dt)
+
+	momentum = momentum.Add(combine(k1.force, k2.force, k3.force, k4.force).Mul(dt))
+	angularMomentum = angularMomentum.Add(combine(k1.torque, k2.torque, k3.torque, k4.torque).Mul(dt))
+	b.LinearVelocity = momentum.Mul(1 / b.Mass)
+	b.AngularVelocity = b.WorldInertia().Inv().Mul3x1(angularMomentum)
+}
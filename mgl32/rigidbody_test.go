@@ -0,0 +1,184 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeshInertia(t *testing.T) {
+	// A 2x4x6 box, rotated and moved away from the origin
+	r := HomogRotate3D(.7, Vec3{1, 2, 3}.Normalize()).Mat3()
+	offset := Vec3{1, -2, 3}
+	box := CubeMesh(2)
+	for i, p := range box.Positions {
+		box.Positions[i] = r.Mul3x1(Vec3{p[0], p[1] * 2, p[2] * 3}).Add(offset)
+	}
+
+	mass, center, inertia := MeshInertia(box.Positions, box.Indices, 3)
+	if !FloatEqualThreshold(mass, 3*48, 1e-5) {
+		t.Errorf("Mass of box is %v, expected %v", mass, 3*48)
+	}
+	if !center.ApproxEqualThreshold(offset, 1e-5) {
+		t.Errorf("Center of mass of box is %v, expected %v", center, offset)
+	}
+	if expected := RotateInertia(BoxInertia(3*48, Vec3{2, 4, 6}), r); !inertia.ApproxEqualThreshold(expected, 1e-3) {
+		t.Errorf("Inertia of box is %v, expected %v", inertia, expected)
+	}
+
+	cylinder := CylinderMesh(1, 3, 256)
+	mass, _, inertia = MeshInertia(cylinder.Positions, cylinder.Indices, 1)
+	if !FloatEqualThreshold(mass, 3*math.Pi, 1e-3) {
+		t.Errorf("Mass of cylinder is %v, expected %v", mass, 3*math.Pi)
+	}
+	if expected := CylinderInertia(mass, 1, 3); !inertia.ApproxEqualThreshold(expected, 1e-3) {
+		t.Errorf("Inertia of cylinder is %v, expected %v", inertia, expected)
+	}
+
+	sphere := IcosphereMesh(2, 5)
+	mass, _, inertia = MeshInertia(sphere.Positions, sphere.Indices, 1)
+	if !FloatEqualThreshold(mass, 4*math.Pi*8/3, 1e-2) {
+		t.Errorf("Mass of sphere is %v, expected %v", mass, 4*math.Pi*8/3)
+	}
+	if expected := SphereInertia(mass, 2); !inertia.ApproxEqualThreshold(expected, 1e-2) {
+		t.Errorf("Inertia of sphere is %v, expected %v", inertia, expected)
+	}
+}
+
+func TestParallelAxis(t *testing.T) {
+	// The inertia of a unit cube of unit mass about one of its corners
+	corner := ParallelAxis(BoxInertia(1, Vec3{1, 1, 1}), 1, Vec3{.5, .5, .5})
+	expected := Mat3{
+		2. / 3, -.25, -.25,
+		-.25, 2. / 3, -.25,
+		-.25, -.25, 2. / 3,
+	}
+	if !corner.ApproxEqualThreshold(expected, 1e-6) {
+		t.Errorf("Inertia of cube about its corner is %v, expected %v", corner, expected)
+	}
+
+	// Two spheres joined into a dumbbell
+	dumbbell := ParallelAxis(SphereInertia(2, 1), 2, Vec3{3, 0, 0}).Add(ParallelAxis(SphereInertia(2, 1), 2, Vec3{-3, 0, 0}))
+	if expected := Diag3(Vec3{1.6, 37.6, 37.6}); !dumbbell.ApproxEqualThreshold(expected, 1e-5) {
+		t.Errorf("Inertia of dumbbell is %v, expected %v", dumbbell, expected)
+	}
+}
+
+func TestIntegrateOrientation(t *testing.T) {
+	axis := Vec3{1, -1, 2}.Normalize()
+	q := QuatRotate(.3, Vec3{0, 1, 0})
+	start := q
+
+	for i := 0; i < 100; i++ {
+		q = IntegrateOrientation(q, axis.Mul(2), .01)
+	}
+
+	expected := QuatRotate(2, axis).Mul(start)
+	v := Vec3{1, 2, 3}
+	if !q.Rotate(v).ApproxEqualThreshold(expected.Rotate(v), 1e-4) {
+		t.Errorf("Spinning at constant speed rotates %v to %v, expected %v", v, q.Rotate(v), expected.Rotate(v))
+	}
+}
+
+// integrators names each RigidBody step method, so that they can be tested together.
+var integrators = []struct {
+	name string
+	step func(b *RigidBody, f ForceFunc, t, dt float32)
+}{
+	{"semi-implicit Euler", (*RigidBody).StepSemiImplicitEuler},
+	{"Verlet", (*RigidBody).StepVerlet},
+	{"RK4", (*RigidBody).StepRK4},
+}
+
+func TestIntegratorsProjectile(t *testing.T) {
+	gravity := Vec3{0, -9.8, 0}
+	f := func(b *RigidBody, t float32) (Vec3, Vec3) { return gravity.Mul(b.Mass), Vec3{} }
+
+	for _, integrator := range integrators {
+		b := &RigidBody{Mass: 2, Inertia: SphereInertia(2, 1), Orientation: QuatIdent(), LinearVelocity: Vec3{3, 10, 0}}
+		for i := 0; i < 100; i++ {
+			integrator.step(b, f, float32(i)*.01, .01)
+		}
+
+		expected := Vec3{3, 10, 0}.Add(gravity.Mul(.5))
+		// Semi-implicit Euler is first order, the rest integrate constant acceleration exactly
+		tolerance := float32(1e-4)
+		if integrator.name == "semi-implicit Euler" {
+			tolerance = .1
+		}
+		if b.Position.Sub(expected).Len() > tolerance {
+			t.Errorf("%s: projectile lands at %v, expected %v", integrator.name, b.Position, expected)
+		}
+		if !b.LinearVelocity.ApproxEqualThreshold(Vec3{3, 10 - 9.8, 0}, 1e-4) {
+			t.Errorf("%s: projectile velocity is %v, expected %v", integrator.name, b.LinearVelocity, Vec3{3, 10 - 9.8, 0})
+		}
+	}
+}
+
+func TestIntegratorsSpring(t *testing.T) {
+	// A unit mass on a spring with a period of 2 pi seconds
+	f := func(b *RigidBody, t float32) (Vec3, Vec3) { return b.Position.Mul(-1), Vec3{} }
+
+	for _, integrator := range integrators {
+		b := &RigidBody{Mass: 1, Inertia: Ident3(), Orientation: QuatIdent(), Position: Vec3{1, 0, 0}}
+		steps := 2000
+		for i := 0; i < steps; i++ {
+			integrator.step(b, f, float32(i)*.01, .01)
+		}
+
+		// The energy should stay close to where it started even after several periods
+		if energy := b.KineticEnergy() + b.Position.Dot(b.Position)/2; !FloatEqualThreshold(energy, .5, 2e-2) {
+			t.Errorf("%s: spring energy is %v after %d steps, expected .5", integrator.name, energy, steps)
+		}
+
+		expected := Vec3{float32(math.Cos(20)), 0, 0}
+		tolerance := float32(.05)
+		if integrator.name == "RK4" {
+			tolerance = 1e-4
+		}
+		if b.Position.Sub(expected).Len() > tolerance {
+			t.Errorf("%s: spring is at %v, expected %v", integrator.name, b.Position, expected)
+		}
+	}
+}
+
+func TestIntegratorsTumbling(t *testing.T) {
+	// A box spinning freely near its intermediate axis tumbles, but keeps its angular momentum and energy
+	noTorque := func(b *RigidBody, t float32) (Vec3, Vec3) { return Vec3{}, Vec3{} }
+
+	for _, integrator := range integrators {
+		b := &RigidBody{
+			Mass:            1,
+			Inertia:         BoxInertia(1, Vec3{1, 2, 3}),
+			Orientation:     QuatRotate(.5, Vec3{1, 1, 1}.Normalize()),
+			AngularVelocity: Vec3{.1, 5, .1},
+		}
+		momentum, energy := b.AngularMomentum(), b.KineticEnergy()
+
+		tumbled := false
+		for i := 0; i < 1000; i++ {
+			integrator.step(b, noTorque, float32(i)*.005, .005)
+			if b.Orientation.Rotate(Vec3{0, 1, 0}).Dot(Vec3{0, 1, 0}) < 0 {
+				tumbled = true
+			}
+		}
+
+		if !tumbled {
+			t.Errorf("%s: box spinning about its intermediate axis didn't tumble", integrator.name)
+		}
+
+		tolerance := float32(.05)
+		if integrator.name == "RK4" {
+			tolerance = 1e-3
+		}
+		if !b.AngularMomentum().ApproxEqualThreshold(momentum, tolerance) {
+			t.Errorf("%s: angular momentum changed from %v to %v without torque", integrator.name, momentum, b.AngularMomentum())
+		}
+		if !FloatEqualThreshold(b.KineticEnergy(), energy, tolerance) {
+			t.Errorf("%s: energy changed from %v to %v without torque", integrator.name, energy, b.KineticEnergy())
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// testArm returns a skeleton of a shoulder two units up, with an elbow and then a hand a unit apart along x.
+func testArm(t *testing.T) *Skeleton {
+	joint := func(name string, parent int, translation Vec3) Joint {
+		rest := IdentJointTransform()
+		rest.Translation = translation
+		return Joint{Name: name, Parent: parent, Rest: rest}
+	}
+	s, err := NewSkeleton([]Joint{
+		joint("shoulder", -1, Vec3{0, 2, 0}),
+		joint("elbow", 0, Vec3{1, 0, 0}),
+		joint("hand", 1, Vec3{1, 0, 0}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// bentArm returns a pose of the arm with the elbow bent 90 degrees about z.
+func bentArm(s *Skeleton) Pose {
+	p := s.RestPose()
+	p[s.Find("elbow")].Rotation = QuatRotate(math.Pi/2, Vec3{0, 0, 1})
+	return p
+}
+
+func TestJointTransformMat4(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 100; i++ {
+		axis := Vec3{rand.Float32() - .5, rand.Float32() - .5, rand.Float32() - .5}.Normalize()
+		jt := JointTransform{
+			Translation: Vec3{rand.Float32() * 10, rand.Float32() * 10, rand.Float32() * 10},
+			Rotation:    QuatRotate(rand.Float32()*2*math.Pi, axis),
+			Scale:       Vec3{rand.Float32() + .5, rand.Float32() + .5, rand.Float32() + .5},
+		}
+		tr, s := jt.Translation, jt.Scale
+		expected := Translate3D(tr[0], tr[1], tr[2]).Mul4(jt.Rotation.Mat4()).Mul4(Scale3D(s[0], s[1], s[2]))
+		if m := jt.Mat4(); !m.ApproxEqualThreshold(expected, 1e-3) {
+			t.Fatalf("Matrix of %v is %v, expected %v", jt, m, expected)
+		}
+	}
+
+	if m := IdentJointTransform().Mat4(); m != Ident4() {
+		t.Errorf("Matrix of the identity transform is %v", m)
+	}
+}
+
+func TestSkeletonTransforms(t *testing.T) {
+	s := testArm(t)
+	if s.Find("hand") != 2 || s.Find("foot") != -1 {
+		t.Errorf("Finding joints gives %d and %d, expected 2 and -1", s.Find("hand"), s.Find("foot"))
+	}
+
+	// The rest pose doesn't move anything
+	for i, m := range s.Palette(s.RestPose(), nil) {
+		if !m.ApproxEqualThreshold(Ident4(), 1e-3) {
+			t.Errorf("Skinning matrix of joint %d in the rest pose is %v", i, m)
+		}
+	}
+
+	// Bending the elbow carries the hand with it, and the palette is appended after what's already there
+	palette := s.Palette(bentArm(s), []Mat4{Ident4()})
+	transforms := s.ModelTransforms(bentArm(s), nil)
+	expected := []Vec3{{0, 2, 0}, {1, 2, 0}, {1, 3, 0}}
+	for i, m := range transforms {
+		if p := m.Col(3).Vec3(); !p.ApproxEqualThreshold(expected[i], 1e-5) {
+			t.Errorf("Joint %d is at %v, expected %v", i, p, expected[i])
+		}
+		if !palette[i+1].ApproxEqualThreshold(m.Mul4(s.Joints[i].InverseBind), 1e-3) {
+			t.Errorf("Skinning matrix of joint %d is %v", i, palette[i+1])
+		}
+	}
+	if len(palette) != 4 {
+		t.Errorf("Palette has %d matrices, expected 4", len(palette))
+	}
+}
+
+func TestSkinWeights(t *testing.T) {
+	s := testArm(t)
+	palette := s.Palette(bentArm(s), nil)
+
+	src := &Mesh{
+		Positions: []Vec3{{.5, 2, 0}, {1.5, 2, 0}, {1.5, 2, 0}, {5, 5, 5}},
+		Normals:   []Vec3{{0, 1, 0}, {1, 0, 0}, {1, 0, 0}, {0, 0, 1}},
+		Tangents:  []Vec4{{1, 0, 0, 1}, {1, 0, 0, -1}, {0, 0, 1, 1}, {1, 0, 0, 1}},
+		UVs:       make([]Vec2, 4),
+		Indices:   []uint32{0, 1, 2, 1, 2, 3},
+	}
+	weights := &SkinWeights{
+		Joints: [][4]uint16{{0, 0, 0, 0}, {1, 0, 0, 0}, {0, 1, 0, 0}, {0, 1, 2, 0}},
+		// The third vertex's weights don't add up to 1, and the fourth's are all 0
+		Weights: []Vec4{{1, 0, 0, 0}, {1, 0, 0, 0}, {1, 1, 0, 0}, {0, 0, 0, 0}},
+	}
+
+	dst := &Mesh{Positions: make([]Vec3, 10)}
+	weights.Skin(palette, src, dst)
+
+	positions := []Vec3{{.5, 2, 0}, {1, 2.5, 0}, {1.25, 2.25, 0}, {5, 5, 5}}
+	normals := []Vec3{{0, 1, 0}, {0, 1, 0}, Vec3{1, 1, 0}.Normalize(), {0, 0, 1}}
+	tangents := []Vec4{{1, 0, 0, 1}, {0, 1, 0, -1}, {0, 0, 1, 1}, {1, 0, 0, 1}}
+	if len(dst.Positions) != 4 || len(dst.Normals) != 4 || len(dst.Tangents) != 4 {
+		t.Fatalf("Skinned mesh has %d positions, %d normals and %d tangents", len(dst.Positions), len(dst.Normals), len(dst.Tangents))
+	}
+	for v := range positions {
+		if !dst.Positions[v].ApproxEqualThreshold(positions[v], 1e-5) {
+			t.Errorf("Vertex %d is at %v, expected %v", v, dst.Positions[v], positions[v])
+		}
+		if !dst.Normals[v].ApproxEqualThreshold(normals[v], 1e-3) {
+			t.Errorf("Vertex %d has normal %v, expected %v", v, dst.Normals[v], normals[v])
+		}
+		if !dst.Tangents[v].ApproxEqualThreshold(tangents[v], 1e-3) {
+			t.Errorf("Vertex %d has tangent %v, expected %v", v, dst.Tangents[v], tangents[v])
+		}
+	}
+	if &dst.Indices[0] != &src.Indices[0] || len(dst.UVs) != 4 {
+		t.Errorf("Skinned mesh doesn't share the source's indices and UVs")
+	}
+}
+
+func TestPoseBlend(t *testing.T) {
+	s := testArm(t)
+	z := Vec3{0, 0, 1}
+
+	// The bent pose's negated rotation is the same rotation, so blending still turns the short way
+	bent := bentArm(s)
+	bent[1].Rotation = bent[1].Rotation.Scale(-1)
+	bent[0].Translation = Vec3{0, 4, 0}
+
+	p := s.RestPose()
+	p.Blend(p, bent, .5)
+	if !p[0].Translation.ApproxEqual(Vec3{0, 3, 0}) {
+		t.Errorf("Blended shoulder is at %v, expected [0 3 0]", p[0].Translation)
+	}
+	if r := p[1].Rotation; !r.ApproxEqualThreshold(QuatRotate(math.Pi/4, z), 1e-4) {
+		t.Errorf("Blended elbow rotation is %v, expected 45 degrees", r)
+	}
+
+	p.BlendJoints(s.RestPose(), bent, []float32{0, 1, 0})
+	if p[0] != s.Joints[0].Rest || !p[1].Mat4().ApproxEqualThreshold(bent[1].Mat4(), 1e-3) || p[2] != s.Joints[2].Rest {
+		t.Errorf("Blending only the elbow gives %v", p)
+	}
+}
+
+func TestPoseAdditive(t *testing.T) {
+	s := testArm(t)
+	z, y := Vec3{0, 0, 1}, Vec3{0, 1, 0}
+	rest, bent := s.RestPose(), bentArm(s)
+	bent[0].Scale = Vec3{2, 2, 2}
+
+	layer := make(Pose, len(rest))
+	layer.Difference(bent, rest)
+
+	// Adding the difference to the reference gives the pose back
+	p := make(Pose, len(rest))
+	p.AddLayer(rest, layer, 1)
+	for i := range p {
+		if m, expected := p[i].Mat4(), bent[i].Mat4(); !m.ApproxEqualThreshold(expected, 1e-3) {
+			t.Errorf("Joint %d with the layer added is %v, expected %v", i, m, expected)
+		}
+	}
+
+	// On another base, the layer's rotation comes after the joint's own, and its scale multiplies
+	base := s.RestPose()
+	base[0].Scale = Vec3{3, 3, 3}
+	base[1].Rotation = QuatRotate(math.Pi/2, y)
+	p.AddLayer(base, layer, .5)
+	if r, expected := p[1].Rotation, base[1].Rotation.Mul(QuatRotate(math.Pi/4, z)); !r.ApproxEqualThreshold(expected, 1e-4) {
+		t.Errorf("Elbow rotation with half the layer is %v, expected %v", r, expected)
+	}
+	if !p[0].Scale.ApproxEqual(Vec3{4.5, 4.5, 4.5}) {
+		t.Errorf("Shoulder scale with half the layer is %v, expected [4.5 4.5 4.5]", p[0].Scale)
+	}
+
+	p.AddLayer(base, layer, 0)
+	for i := range p {
+		if m, expected := p[i].Mat4(), base[i].Mat4(); !m.ApproxEqualThreshold(expected, 1e-3) {
+			t.Errorf("Joint %d with none of the layer is %v, expected %v", i, m, expected)
+		}
+	}
+}
+
+func TestSkeletonValidate(t *testing.T) {
+	for _, parents := range [][]int{{0}, {-2}, {-1, 2, 1}, {-1, 1}} {
+		joints := make([]Joint, len(parents))
+		for i, parent := range parents {
+			joints[i] = Joint{Parent: parent, Rest: IdentJointTransform()}
+		}
+		if _, err := NewSkeleton(joints); err == nil {
+			t.Errorf("Creating a skeleton with parents %v succeeded", parents)
+		}
+	}
+
+	if _, err := NewSkeleton([]Joint{{Parent: -1, Rest: IdentJointTransform()}, {Parent: -1, Rest: IdentJointTransform()}}); err != nil {
+		t.Errorf("Creating a skeleton with two roots failed: %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Continuity describes how smoothly neighbouring segments of a spline are joined.
+type Continuity int
+
+const (
+	// C0 only requires that each segment starts where the previous one ends.
+	C0 Continuity = iota
+	// G1 additionally requires the tangents at each joint to point in the same direction,
+	// though their magnitudes may differ.
+	G1
+	// C1 requires the derivative with respect to the spline's parameter to be equal on both
+	// sides of each joint.
+	C1
+)
+
+func (c Continuity) String() string {
+	switch c {
+	case C0:
+		return "C0"
+	case G1:
+		return "G1"
+	case C1:
+		return "C1"
+	}
+
+	return fmt.Sprintf("Continuity(%d)", int(c))
+}
+
+// continuityThreshold is the tolerance, relative to the size of the control points involved, used
+// when checking that the joints of a spline are continuous.
+const continuityThreshold = 1e-4
+
+// ErrSplineOutOfRange is returned when evaluating a spline outside of the range covered by its segments.
+var ErrSplineOutOfRange = errors.New("t is out of the range of all bezier curves in this spline")
+
+// A BezierSpline2D is a piecewise curve made of several Bezier segments of arbitrary degree joined end to end.
+// Segment i covers the parameter range Ranges[i] and is controlled by CPoints[i].
+//
+// Unlike BezierSplineInterpolate2D, the segments must be sorted, contiguous and joined with the
+// requested continuity. This is checked when the spline is created, and evaluation returns an
+// error rather than panicking.
+type BezierSpline2D struct {
+	Ranges     [][2]float32
+	CPoints    [][]Vec2
+	Continuity Continuity
+}
+
+// NewBezierSpline2D creates a spline after checking that the segments form a valid network with
+// at least the given continuity at each joint.
+func NewBezierSpline2D(ranges [][2]float32, cPoints [][]Vec2, continuity Continuity) (*BezierSpline2D, error) {
+	s := &BezierSpline2D{ranges, cPoints, continuity}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Validate checks that the segments are well formed and joined with the spline's continuity.
+// It is only necessary to call this after modifying the spline's fields directly.
+func (s *BezierSpline2D) Validate() error {
+	if err := validateSplineRanges(s.Ranges, len(s.CPoints)); err != nil {
+		return err
+	}
+
+	for i, cPoints := range s.CPoints {
+		if len(cPoints) < 2 {
+			return fmt.Errorf("segment %d has %d control points, at least 2 are needed", i, len(cPoints))
+		}
+	}
+
+	for i := 1; i < len(s.CPoints); i++ {
+		prev, next := s.CPoints[i-1], s.CPoints[i]
+		scale := splineScale2D(prev, next)
+
+		end, start := prev[len(prev)-1], next[0]
+		if !withinSplineTolerance(end.Sub(start).Len(), scale) {
+			return fmt.Errorf("segments %d and %d are not C0 continuous: %v != %v", i-1, i, end, start)
+		}
+
+		if s.Continuity == C0 {
+			continue
+		}
+
+		d0 := bezierEndDerivative2D(prev).Mul(1 / (s.Ranges[i-1][1] - s.Ranges[i-1][0]))
+		d1 := bezierStartDerivative2D(next).Mul(1 / (s.Ranges[i][1] - s.Ranges[i][0]))
+
+		switch s.Continuity {
+		case C1:
+			if !withinSplineTolerance(d0.Sub(d1).Len(), d0.Len()+d1.Len()) {
+				return fmt.Errorf("segments %d and %d are not C1 continuous: derivatives %v and %v differ", i-1, i, d0, d1)
+			}
+		case G1:
+			cross := d0[0]*d1[1] - d0[1]*d1[0]
+			if d0.Dot(d1) <= 0 || !withinSplineTolerance(Abs(cross), d0.Len()*d1.Len()) {
+				return fmt.Errorf("segments %d and %d are not G1 continuous: tangents %v and %v are not parallel", i-1, i, d0, d1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Interpolate returns the point on the spline at t, like BezierSplineInterpolate2D.
+func (s *BezierSpline2D) Interpolate(t float32) (Vec2, error) {
+	i, local, err := splineSegment(s.Ranges, t)
+	if err != nil {
+		return Vec2{}, err
+	}
+
+	return BezierCurve2D(local, s.CPoints[i]), nil
+}
+
+// Derivative returns the first derivative of the spline at t with respect to t (not the segment's local parameter).
+// At a joint, the derivative of the later segment is used.
+func (s *BezierSpline2D) Derivative(t float32) (Vec2, error) {
+	i, local, err := splineSegment(s.Ranges, t)
+	if err != nil {
+		return Vec2{}, err
+	}
+
+	return BezierDerivative2D(local, s.CPoints[i]).Mul(1 / (s.Ranges[i][1] - s.Ranges[i][0])), nil
+}
+
+// Range returns the range of t covered by the spline.
+func (s *BezierSpline2D) Range() [2]float32 {
+	return [2]float32{s.Ranges[0][0], s.Ranges[len(s.Ranges)-1][1]}
+}
+
+// Same as the 2D version, except the spline is in 3D space
+type BezierSpline3D struct {
+	Ranges     [][2]float32
+	CPoints    [][]Vec3
+	Continuity Continuity
+}
+
+// Same as the 2D version, except the spline is in 3D space
+func NewBezierSpline3D(ranges [][2]float32, cPoints [][]Vec3, continuity Continuity) (*BezierSpline3D, error) {
+	s := &BezierSpline3D{ranges, cPoints, continuity}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Validate checks that the segments are well formed and joined with the spline's continuity.
+// It is only necessary to call this after modifying the spline's fields directly.
+func (s *BezierSpline3D) Validate() error {
+	if err := validateSplineRanges(s.Ranges, len(s.CPoints)); err != nil {
+		return err
+	}
+
+	for i, cPoints := range s.CPoints {
+		if len(cPoints) < 2 {
+			return fmt.Errorf("segment %d has %d control points, at least 2 are needed", i, len(cPoints))
+		}
+	}
+
+	for i := 1; i < len(s.CPoints); i++ {
+		prev, next := s.CPoints[i-1], s.CPoints[i]
+		scale := splineScale3D(prev, next)
+
+		end, start := prev[len(prev)-1], next[0]
+		if !withinSplineTolerance(end.Sub(start).Len(), scale) {
+			return fmt.Errorf("segments %d and %d are not C0 continuous: %v != %v", i-1, i, end, start)
+		}
+
+		if s.Continuity == C0 {
+			continue
+		}
+
+		d0 := bezierEndDerivative3D(prev).Mul(1 / (s.Ranges[i-1][1] - s.Ranges[i-1][0]))
+		d1 := bezierStartDerivative3D(next).Mul(1 / (s.Ranges[i][1] - s.Ranges[i][0]))
+
+		switch s.Continuity {
+		case C1:
+			if !withinSplineTolerance(d0.Sub(d1).Len(), d0.Len()+d1.Len()) {
+				return fmt.Errorf("segments %d and %d are not C1 continuous: derivatives %v and %v differ", i-1, i, d0, d1)
+			}
+		case G1:
+			if d0.Dot(d1) <= 0 || !withinSplineTolerance(d0.Cross(d1).Len(), d0.Len()*d1.Len()) {
+				return fmt.Errorf("segments %d and %d are not G1 continuous: tangents %v and %v are not parallel", i-1, i, d0, d1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Interpolate returns the point on the spline at t, like BezierSplineInterpolate3D.
+func (s *BezierSpline3D) Interpolate(t float32) (Vec3, error) {
+	i, local, err := splineSegment(s.Ranges, t)
+	if err != nil {
+		return Vec3{}, err
+	}
+
+	return BezierCurve3D(local, s.CPoints[i]), nil
+}
+
+// Derivative returns the first derivative of the spline at t with respect to t (not the segment's local parameter).
+// At a joint, the derivative of the later segment is used.
+func (s *BezierSpline3D) Derivative(t float32) (Vec3, error) {
+	i, local, err := splineSegment(s.Ranges, t)
+	if err != nil {
+		return Vec3{}, err
+	}
+
+	return BezierDerivative3D(local, s.CPoints[i]).Mul(1 / (s.Ranges[i][1] - s.Ranges[i][0])), nil
+}
+
+// Range returns the range of t covered by the spline.
+func (s *BezierSpline3D) Range() [2]float32 {
+	return [2]float32{s.Ranges[0][0], s.Ranges[len(s.Ranges)-1][1]}
+}
+
+// validateSplineRanges checks that there is one non-empty range per segment, and that the ranges are
+// sorted and contiguous.
+func validateSplineRanges(ranges [][2]float32, numSegments int) error {
+	if numSegments == 0 {
+		return errors.New("a spline needs at least one segment")
+	} else if len(ranges) != numSegments {
+		return fmt.Errorf("each bezier curve needs a range: %d ranges for %d segments", len(ranges), numSegments)
+	}
+
+	for i, r := range ranges {
+		if !(r[0] < r[1]) {
+			return fmt.Errorf("segment %d has an empty or inverted range %v", i, r)
+		}
+		if i > 0 && r[0] != ranges[i-1][1] {
+			return fmt.Errorf("segment %d starts at %v, but segment %d ends at %v", i, r[0], i-1, ranges[i-1][1])
+		}
+	}
+
+	return nil
+}
+
+// splineSegment finds the segment containing t and maps t into that segment's [0,1] parameter.
+func splineSegment(ranges [][2]float32, t float32) (segment int, local float32, err error) {
+	if len(ranges) == 0 || t < ranges[0][0] || t > ranges[len(ranges)-1][1] {
+		return 0, 0, ErrSplineOutOfRange
+	}
+
+	segment = sort.Search(len(ranges), func(i int) bool { return t < ranges[i][1] })
+	if segment == len(ranges) {
+		segment--
+	}
+
+	r := ranges[segment]
+	return segment, Clamp((t-r[0])/(r[1]-r[0]), 0, 1), nil
+}
+
+func withinSplineTolerance(err, scale float32) bool {
+	if scale < 1 {
+		scale = 1
+	}
+
+	return err <= continuityThreshold*scale
+}
+
+func splineScale2D(a, b []Vec2) (scale float32) {
+	for _, p := range append(append([]Vec2(nil), a...), b...) {
+		for _, x := range p {
+			x = Abs(x)
+			SetMax(&scale, &x)
+		}
+	}
+
+	return scale
+}
+
+func splineScale3D(a, b []Vec3) (scale float32) {
+	for _, p := range append(append([]Vec3(nil), a...), b...) {
+		for _, x := range p {
+			x = Abs(x)
+			SetMax(&scale, &x)
+		}
+	}
+
+	return scale
+}
+
+func bezierStartDerivative2D(cPoints []Vec2) Vec2 {
+	return cPoints[1].Sub(cPoints[0]).Mul(float32(len(cPoints) - 1))
+}
+
+func bezierEndDerivative2D(cPoints []Vec2) Vec2 {
+	n := len(cPoints) - 1
+	return cPoints[n].Sub(cPoints[n-1]).Mul(float32(n))
+}
+
+func bezierStartDerivative3D(cPoints []Vec3) Vec3 {
+	return cPoints[1].Sub(cPoints[0]).Mul(float32(len(cPoints) - 1))
+}
+
+func bezierEndDerivative3D(cPoints []Vec3) Vec3 {
+	n := len(cPoints) - 1
+	return cPoints[n].Sub(cPoints[n-1]).Mul(float32(n))
+}
@@ -0,0 +1,94 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl32
+
+import (
+	"testing"
+)
+
+func TestBezierSplineInterpolate(t *testing.T) {
+	ranges := [][2]float32{{0, 1}, {1, 3}}
+	cPoints := [][]Vec3{
+		{{0, 0, 0}, {1, 1, 0}, {2, 1, 0}, {3, 0, 0}},
+		{{3, 0, 0}, {6, -3, 0}, {9, 0, 0}},
+	}
+
+	spline, err := NewBezierSpline3D(ranges, cPoints, C1)
+	if err != nil {
+		t.Fatalf("Valid C1 spline rejected: %v", err)
+	}
+
+	for _, tt := range []float32{0, .5, 1, 2, 3} {
+		p, err := spline.Interpolate(tt)
+		if err != nil {
+			t.Errorf("Interpolate(%v) gave error %v", tt, err)
+		} else if answer := BezierSplineInterpolate3D(tt, ranges, cPoints); !p.ApproxEqualThreshold(answer, 1e-5) {
+			t.Errorf("Interpolate(%v) = %v, BezierSplineInterpolate3D gives %v", tt, p, answer)
+		}
+	}
+
+	if _, err := spline.Interpolate(3.5); err != ErrSplineOutOfRange {
+		t.Errorf("Interpolating out of range gave error %v, expected ErrSplineOutOfRange", err)
+	}
+}
+
+func TestBezierSplineDerivative(t *testing.T) {
+	spline, err := NewBezierSpline2D(
+		[][2]float32{{0, 2}, {2, 4}},
+		[][]Vec2{{{0, 0}, {1, 1}, {2, 0}}, {{2, 0}, {3, -1}, {4, 0}}},
+		C1)
+	if err != nil {
+		t.Fatalf("Valid C1 spline rejected: %v", err)
+	}
+
+	// Over a range of width 2, the derivative is half of the local derivative
+	d, _ := spline.Derivative(0)
+	if !d.ApproxEqualThreshold(Vec2{1, 1}, 1e-5) {
+		t.Errorf("Derivative at start is %v, expected [1 1]", d)
+	}
+
+	before, _ := spline.Derivative(1.999)
+	after, _ := spline.Derivative(2)
+	if !before.ApproxEqualThreshold(after, 1e-2) {
+		t.Errorf("Derivative is not continuous at joint: %v != %v", before, after)
+	}
+}
+
+func TestBezierSplineValidation(t *testing.T) {
+	corner := [][]Vec2{{{0, 0}, {1, 0}}, {{1, 0}, {1, 1}}}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}, {1, 2}}, corner, C0); err != nil {
+		t.Errorf("Valid C0 spline rejected: %v", err)
+	}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}, {1, 2}}, corner, G1); err == nil {
+		t.Errorf("Spline with a corner accepted as G1")
+	}
+
+	// Same direction, different speed: G1 but not C1
+	straight := [][]Vec2{{{0, 0}, {1, 0}}, {{1, 0}, {3, 0}}}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}, {1, 2}}, straight, G1); err != nil {
+		t.Errorf("Valid G1 spline rejected: %v", err)
+	}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}, {1, 2}}, straight, C1); err == nil {
+		t.Errorf("Spline with a change in speed accepted as C1")
+	}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}, {1, 3}}, straight, C1); err != nil {
+		t.Errorf("Spline with matching speed rejected as C1: %v", err)
+	}
+
+	gap := [][]Vec2{{{0, 0}, {1, 0}}, {{2, 0}, {3, 0}}}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}, {1, 2}}, gap, C0); err == nil {
+		t.Errorf("Disjoint spline accepted as C0")
+	}
+
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}, {1.5, 2}}, straight, C0); err == nil {
+		t.Errorf("Spline with non-contiguous ranges accepted")
+	}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}}, straight, C0); err == nil {
+		t.Errorf("Spline with missing range accepted")
+	}
+	if _, err := NewBezierSpline2D([][2]float32{{0, 1}}, [][]Vec2{{{0, 0}}}, C0); err == nil {
+		t.Errorf("Spline with a single control point accepted")
+	}
+}
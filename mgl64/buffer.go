@@ -0,0 +1,359 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.17
+// +build go1.17
+
+package mgl64
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// LayoutRule is a set of rules for laying out the members of a GLSL interface block in memory.
+type LayoutRule int
+
+const (
+	// Std140 is the layout of uniform blocks. Arrays and structs are aligned to 16 bytes, so every element of a
+	// float[4] takes 16 bytes.
+	Std140 LayoutRule = iota
+	// Std430 is the layout of shader storage blocks (and Vulkan push constants), which packs arrays and structs
+	// as tightly as their members allow.
+	Std430
+)
+
+func (r LayoutRule) String() string {
+	switch r {
+	case Std140:
+		return "std140"
+	case Std430:
+		return "std430"
+	}
+	return fmt.Sprintf("LayoutRule(%d)", int(r))
+}
+
+// BufferLayout is the layout of a Go struct type as a GLSL block, for packing values of the type into a buffer to
+// upload. The members of the block are the fields of the struct in order, with these types:
+//
+//	float32, int32, uint32    float, int, uint
+//	float64                   double
+//	Vec2, Vec3, Vec4          vec2, vec3, vec4
+//	Mat3, Mat2x3, ...         mat3, mat3x2, ... (MatMxN has M rows and N columns, so it's GLSL's matNxM)
+//	[n]T                      an array of n of T
+//	struct                    a struct
+//
+// A Quat is a struct {float W; vec3 V;}, so it takes 32 bytes; convert it to a Vec4 to pass it as a vec4. bool has
+// no fixed size in Go, so it isn't allowed; use uint32 for a GLSL bool.
+type BufferLayout struct {
+	Rule      LayoutRule
+	Type      reflect.Type
+	Size      int // The size of the block in bytes, padded to a multiple of its alignment
+	Alignment int
+	Fields    []BufferField
+
+	copies []bufferCopy
+}
+
+// BufferField is the position of a member of a block. The strides are those reported by OpenGL's
+// glGetProgramResourceiv.
+type BufferField struct {
+	Name         string
+	Offset, Size int
+	ArrayStride  int           // The distance between elements of an array, or 0
+	MatrixStride int           // The distance between columns of a matrix (or of each matrix in an array), or 0
+	Layout       *BufferLayout // The layout of a struct member (or of each struct in an array), or nil
+}
+
+// bufferCopy copies size bytes from offset src of a Go value to offset dst of the buffer.
+type bufferCopy struct {
+	src, dst, size int
+}
+
+// matrixShapes holds the number of columns and rows of the vector and matrix types.
+var matrixShapes = map[reflect.Type][2]int{
+	reflect.TypeOf(Vec2{}):   {1, 2},
+	reflect.TypeOf(Vec3{}):   {1, 3},
+	reflect.TypeOf(Vec4{}):   {1, 4},
+	reflect.TypeOf(Mat2{}):   {2, 2},
+	reflect.TypeOf(Mat2x3{}): {3, 2},
+	reflect.TypeOf(Mat2x4{}): {4, 2},
+	reflect.TypeOf(Mat3x2{}): {2, 3},
+	reflect.TypeOf(Mat3{}):   {3, 3},
+	reflect.TypeOf(Mat3x4{}): {4, 3},
+	reflect.TypeOf(Mat4x2{}): {2, 4},
+	reflect.TypeOf(Mat4x3{}): {3, 4},
+	reflect.TypeOf(Mat4{}):   {4, 4},
+}
+
+// NewBufferLayout computes the layout of the type of v, which must be a struct or a pointer to one, under rule. The
+// layout can be reused for every value of the type.
+func NewBufferLayout(rule LayoutRule, v interface{}) (*BufferLayout, error) {
+	if rule != Std140 && rule != Std430 {
+		return nil, fmt.Errorf("unknown layout rule %v", rule)
+	}
+
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("a buffer layout needs a struct, not %v", t)
+	}
+
+	return newStructLayout(rule, t)
+}
+
+func newStructLayout(rule LayoutRule, t reflect.Type) (*BufferLayout, error) {
+	l := &BufferLayout{Rule: rule, Type: t, Alignment: 1}
+	offset := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		m, err := newMemberLayout(rule, f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %v: %v", f.Name, t, err)
+		}
+
+		offset = roundUp(offset, m.alignment)
+		l.Fields = append(l.Fields, BufferField{
+			Name:         f.Name,
+			Offset:       offset,
+			Size:         m.size,
+			ArrayStride:  m.arrayStride,
+			MatrixStride: m.matrixStride,
+			Layout:       m.layout,
+		})
+		for _, c := range m.copies {
+			l.addCopy(bufferCopy{int(f.Offset) + c.src, offset + c.dst, c.size})
+		}
+
+		offset += m.size
+		if m.alignment > l.Alignment {
+			l.Alignment = m.alignment
+		}
+	}
+
+	if rule == Std140 {
+		l.Alignment = roundUp(l.Alignment, 16)
+	}
+	l.Size = roundUp(offset, l.Alignment)
+
+	return l, nil
+}
+
+// memberLayout is the layout of a member of any type, relative to its start.
+type memberLayout struct {
+	size, alignment           int
+	arrayStride, matrixStride int
+	layout                    *BufferLayout
+	copies                    []bufferCopy
+}
+
+func newMemberLayout(rule LayoutRule, t reflect.Type) (memberLayout, error) {
+	if shape, ok := matrixShapes[t]; ok {
+		// A vector, or a matrix laid out as an array of column vectors
+		scalar := int(t.Elem().Size())
+		column := vectorLayout(scalar, shape[1])
+		if shape[0] == 1 {
+			return column, nil
+		}
+
+		m := arrayLayout(rule, column, shape[0], shape[1]*scalar)
+		m.matrixStride, m.arrayStride = m.arrayStride, 0
+		return m, nil
+	}
+
+	if isScalar(t.Kind()) {
+		return vectorLayout(int(t.Size()), 1), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Array:
+		element, err := newMemberLayout(rule, t.Elem())
+		if err != nil {
+			return memberLayout{}, err
+		}
+		if t.Len() == 0 {
+			return memberLayout{}, fmt.Errorf("array %v is empty", t)
+		}
+
+		m := arrayLayout(rule, element, t.Len(), int(t.Elem().Size()))
+		m.matrixStride, m.layout = element.matrixStride, element.layout
+		return m, nil
+
+	case reflect.Struct:
+		l, err := newStructLayout(rule, t)
+		if err != nil {
+			return memberLayout{}, err
+		}
+		return memberLayout{size: l.Size, alignment: l.Alignment, layout: l, copies: l.copies}, nil
+	}
+
+	return memberLayout{}, fmt.Errorf("type %v has no GLSL equivalent", t)
+}
+
+// isScalar is true for the kinds of GLSL's scalar types.
+func isScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int32, reflect.Uint32, reflect.Float64:
+		return true
+	}
+	// Separate, because the case would be a duplicate in mgl64
+	return k == reflect.Float64
+}
+
+// vectorLayout is the layout of a vector of n scalars of the given size. Vectors of three are aligned like vectors of
+// four.
+func vectorLayout(scalar, n int) memberLayout {
+	m := memberLayout{size: scalar * n, alignment: scalar * n, copies: []bufferCopy{{0, 0, scalar * n}}}
+	if n == 3 {
+		m.alignment = scalar * 4
+	}
+	return m
+}
+
+// arrayLayout is the layout of an array of n elements, which are goSize bytes apart in Go.
+func arrayLayout(rule LayoutRule, element memberLayout, n, goSize int) memberLayout {
+	alignment := element.alignment
+	if rule == Std140 {
+		alignment = roundUp(alignment, 16)
+	}
+
+	m := memberLayout{alignment: alignment, arrayStride: roundUp(element.size, alignment)}
+	m.size = m.arrayStride * n
+	l := &BufferLayout{}
+	for i := 0; i < n; i++ {
+		for _, c := range element.copies {
+			l.addCopy(bufferCopy{i*goSize + c.src, i*m.arrayStride + c.dst, c.size})
+		}
+	}
+	m.copies = l.copies
+
+	return m
+}
+
+// addCopy adds c to the copies of l, merging it with the last one if they're contiguous in both Go and the buffer.
+func (l *BufferLayout) addCopy(c bufferCopy) {
+	if n := len(l.copies); n > 0 {
+		last := &l.copies[n-1]
+		if last.src+last.size == c.src && last.dst+last.size == c.dst {
+			last.size += c.size
+			return
+		}
+	}
+	l.copies = append(l.copies, c)
+}
+
+func roundUp(n, alignment int) int {
+	return (n + alignment - 1) / alignment * alignment
+}
+
+// Field returns the field with the given name.
+func (l *BufferLayout) Field(name string) (BufferField, bool) {
+	for _, f := range l.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return BufferField{}, false
+}
+
+// Put writes v, which must be of the layout's type or a pointer to it, to the start of buf, which must be at least
+// Size bytes long. The bytes are in the machine's byte order, as graphics APIs expect. Padding between members is left
+// as it was.
+func (l *BufferLayout) Put(buf []byte, v interface{}) error {
+	if len(buf) < l.Size {
+		return fmt.Errorf("buffer is %d bytes, but the layout needs %d", len(buf), l.Size)
+	}
+
+	src, err := l.bytes(v)
+	if err != nil {
+		return err
+	}
+	for _, c := range l.copies {
+		copy(buf[c.dst:c.dst+c.size], src[c.src:c.src+c.size])
+	}
+
+	return nil
+}
+
+// Append appends v, laid out as for Put and with zero padding, to buf. Appending values one after another makes an
+// array of them, as in a shader storage block with an array of structs as its last member.
+func (l *BufferLayout) Append(buf []byte, v interface{}) ([]byte, error) {
+	n := len(buf)
+	buf = append(buf, make([]byte, l.Size)...)
+	if err := l.Put(buf[n:], v); err != nil {
+		return buf[:n], err
+	}
+	return buf, nil
+}
+
+// bytes views the memory of v as bytes.
+func (l *BufferLayout) bytes(v interface{}) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	switch {
+	case value.Kind() == reflect.Ptr && value.Type().Elem() == l.Type && !value.IsNil():
+	case value.IsValid() && value.Type() == l.Type:
+		// Copy v to memory of its own to get its address
+		p := reflect.New(l.Type)
+		p.Elem().Set(value)
+		value = p
+	default:
+		return nil, fmt.Errorf("value of type %T doesn't match the layout of %v", v, l.Type)
+	}
+
+	return unsafe.Slice((*byte)(unsafe.Pointer(value.Pointer())), l.Type.Size()), nil
+}
+
+// SliceBytes views the elements of a slice, such as a []Vec3 or []Mat4, as bytes, without copying. The bytes are in
+// the machine's byte order, and changing them changes the slice. It panics if slice isn't a slice.
+func SliceBytes(slice interface{}) []byte {
+	value := reflect.ValueOf(slice)
+	if value.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("SliceBytes of %T, which isn't a slice", slice))
+	}
+
+	size := value.Len() * int(value.Type().Elem().Size())
+	if size == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(value.Pointer())), size)
+}
+
+// SliceFloats views the elements of a slice of float32 based types, such as a []Vec3 or []Mat4, as float32s, without
+// copying. Changing them changes the slice. It panics if slice isn't a slice, or its elements aren't made only of
+// float32s.
+func SliceFloats(slice interface{}) []float64 {
+	value := reflect.ValueOf(slice)
+	if value.Kind() != reflect.Slice || !onlyFloats(value.Type().Elem()) {
+		panic(fmt.Sprintf("SliceFloats of %T, which isn't a slice of float32 based elements", slice))
+	}
+
+	n := value.Len() * int(value.Type().Elem().Size()/unsafe.Sizeof(float64(0)))
+	if n == 0 {
+		return nil
+	}
+	return unsafe.Slice((*float64)(unsafe.Pointer(value.Pointer())), n)
+}
+
+// onlyFloats is true if t consists of float32s without padding, like the vector and matrix types and Quat.
+func onlyFloats(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Float64:
+		return true
+	case reflect.Array:
+		return onlyFloats(t.Elem())
+	case reflect.Struct:
+		size := uintptr(0)
+		for i := 0; i < t.NumField(); i++ {
+			if !onlyFloats(t.Field(i).Type) {
+				return false
+			}
+			size += t.Field(i).Type.Size()
+		}
+		return size == t.Size()
+	}
+	return false
+}
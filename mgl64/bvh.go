@@ -0,0 +1,360 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+)
+
+const (
+	// Nodes with this many primitives or fewer are always leaves
+	bvhMinLeafSize = 2
+	// Nodes with more primitives than this are always split, if their primitives can be told apart at all
+	bvhMaxLeafSize = 16
+	// The number of buckets the surface area heuristic sorts primitives into to find a split
+	bvhBins = 16
+)
+
+// BVH is a bounding volume hierarchy: a tree of boxes, each containing the boxes of its children, over a set of
+// primitives. It answers ray, box, sphere and nearest point queries in roughly logarithmic time instead of checking every
+// primitive. The tree is built top-down with the surface area heuristic, which minimizes the expected cost of ray queries.
+//
+// A BVH is either built over triangles, in which case queries are exact, or over boxes standing in for arbitrary
+// primitives, in which case queries test the boxes. All queries return indices into Triangles or Boxes.
+type BVH struct {
+	// The triangles of a BVH made by NewTriangleBVH, nil otherwise.
+	Triangles []Triangle
+	// The bounds of each primitive.
+	Boxes []AABB
+
+	nodes []bvhNode
+	// The primitive indices, ordered so that each leaf covers a contiguous range
+	indices []int32
+}
+
+// bvhNode is stored in depth-first order, so the left child of an interior node immediately follows it.
+type bvhNode struct {
+	bounds AABB
+	// For leaves, the first element of indices the leaf covers. For interior nodes, the index of the right child.
+	start int32
+	// The number of primitives in a leaf, or 0 for interior nodes.
+	count int32
+}
+
+// RayHit is a hit found by a ray query. Index is the primitive that was hit, T the distance along the ray. For triangles,
+// V and W are the barycentric coordinates of the hit point, the weights of the second and third vertex.
+type RayHit struct {
+	Index int
+	T     float64
+	V, W  float64
+}
+
+// NewBVH builds a BVH over primitives with the given bounds. The boxes slice is kept (not copied) as the Boxes field.
+func NewBVH(boxes []AABB) *BVH {
+	b := &BVH{Boxes: boxes}
+	b.build()
+
+	return b
+}
+
+// NewTriangleBVH builds a BVH over triangles. The triangles slice is kept (not copied) as the Triangles field, so
+// after animating the triangles in place, Refit updates the tree to match.
+func NewTriangleBVH(triangles []Triangle) *BVH {
+	b := &BVH{Triangles: triangles, Boxes: make([]AABB, len(triangles))}
+	for i, tri := range triangles {
+		b.Boxes[i] = tri.Bounds()
+	}
+	b.build()
+
+	return b
+}
+
+func (b *BVH) build() {
+	b.indices = make([]int32, len(b.Boxes))
+	centroids := make([]Vec3, len(b.Boxes))
+	for i, box := range b.Boxes {
+		b.indices[i] = int32(i)
+		centroids[i] = box.Center()
+	}
+
+	if len(b.Boxes) == 0 {
+		return
+	}
+
+	b.nodes = make([]bvhNode, 0, 2*len(b.Boxes)/bvhMinLeafSize+1)
+	b.buildNode(centroids, 0, len(b.indices))
+}
+
+// buildNode adds the node covering indices[start:end] and its subtree, and returns its index.
+func (b *BVH) buildNode(centroids []Vec3, start, end int) int32 {
+	node := int32(len(b.nodes))
+	bounds, centroidBounds := EmptyAABB(), EmptyAABB()
+	for _, i := range b.indices[start:end] {
+		bounds = bounds.Union(b.Boxes[i])
+		centroidBounds = centroidBounds.Extend(centroids[i])
+	}
+	b.nodes = append(b.nodes, bvhNode{bounds, int32(start), int32(end - start)})
+
+	count := end - start
+	if count <= bvhMinLeafSize {
+		return node
+	}
+
+	// Sort the primitives into bins along each axis, and find the split between bins with the lowest cost. The cost of
+	// a child is the number of primitives in it times its surface area, which is proportional to the chance a ray hits it.
+	bestAxis, bestBin, bestCost := -1, 0, float64(math.Inf(1))
+	for axis := 0; axis < 3; axis++ {
+		extent := centroidBounds.Max[axis] - centroidBounds.Min[axis]
+		if extent <= 0 {
+			continue
+		}
+
+		var binBounds [bvhBins]AABB
+		var binCounts [bvhBins]int
+		for i := range binBounds {
+			binBounds[i] = EmptyAABB()
+		}
+		for _, i := range b.indices[start:end] {
+			bin := b.bin(centroids[i][axis], centroidBounds.Min[axis], extent)
+			binBounds[bin] = binBounds[bin].Union(b.Boxes[i])
+			binCounts[bin]++
+		}
+
+		// Sweep from the right to find the cost of every right side, then from the left to combine it with the left side
+		var rightCosts [bvhBins]float64
+		rightBounds, rightCount := EmptyAABB(), 0
+		for i := bvhBins - 1; i > 0; i-- {
+			rightBounds = rightBounds.Union(binBounds[i])
+			rightCount += binCounts[i]
+			rightCosts[i-1] = float64(rightCount) * rightBounds.SurfaceArea()
+		}
+
+		leftBounds, leftCount := EmptyAABB(), 0
+		for i := 0; i < bvhBins-1; i++ {
+			leftBounds = leftBounds.Union(binBounds[i])
+			leftCount += binCounts[i]
+			if leftCount == 0 || leftCount == count {
+				continue
+			}
+			if cost := float64(leftCount)*leftBounds.SurfaceArea() + rightCosts[i]; cost < bestCost {
+				bestAxis, bestBin, bestCost = axis, i, cost
+			}
+		}
+	}
+
+	if bestAxis < 0 {
+		// All the centroids are in the same place, so there's nothing to split by
+		return node
+	}
+
+	// Traversing the children costs about as much as testing one primitive
+	if area := bounds.SurfaceArea(); count <= bvhMaxLeafSize && bestCost/area+1 >= float64(count) {
+		return node
+	}
+
+	extent := centroidBounds.Max[bestAxis] - centroidBounds.Min[bestAxis]
+	mid := start
+	for i := start; i < end; i++ {
+		if b.bin(centroids[b.indices[i]][bestAxis], centroidBounds.Min[bestAxis], extent) <= bestBin {
+			b.indices[i], b.indices[mid] = b.indices[mid], b.indices[i]
+			mid++
+		}
+	}
+
+	b.nodes[node].count = 0
+	b.buildNode(centroids, start, mid)
+	b.nodes[node].start = b.buildNode(centroids, mid, end)
+
+	return node
+}
+
+func (b *BVH) bin(x, min, extent float64) int {
+	bin := int(float64(bvhBins) * (x - min) / extent)
+	if bin >= bvhBins {
+		return bvhBins - 1
+	}
+
+	return bin
+}
+
+// Bounds returns the box containing every primitive.
+func (b *BVH) Bounds() AABB {
+	if len(b.nodes) == 0 {
+		return EmptyAABB()
+	}
+
+	return b.nodes[0].bounds
+}
+
+// Refit updates the tree after the primitives have moved, without changing its structure. For a triangle BVH, the
+// Triangles are read again; otherwise, the Boxes must have been updated. This is much faster than rebuilding the tree,
+// but the tree gets less efficient the further the primitives move from where they were when it was built.
+func (b *BVH) Refit() {
+	if b.Triangles != nil {
+		for i, tri := range b.Triangles {
+			b.Boxes[i] = tri.Bounds()
+		}
+	}
+
+	// Children come after their parents, so going backwards updates them first
+	for n := len(b.nodes) - 1; n >= 0; n-- {
+		node := &b.nodes[n]
+		if node.count > 0 {
+			node.bounds = EmptyAABB()
+			for _, i := range b.indices[node.start : node.start+node.count] {
+				node.bounds = node.bounds.Union(b.Boxes[i])
+			}
+		} else {
+			node.bounds = b.nodes[n+1].bounds.Union(b.nodes[node.start].bounds)
+		}
+	}
+}
+
+// Raycast returns the closest hit of the ray with t in [0, maxT]. For a box BVH, the ray hits boxes.
+func (b *BVH) Raycast(r Ray, maxT float64) (hit RayHit, ok bool) {
+	if len(b.nodes) == 0 {
+		return hit, false
+	}
+
+	hit.T = maxT
+	stack := []int32{0}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &b.nodes[n]
+		if _, hitNode := node.bounds.IntersectRay(r, hit.T); !hitNode {
+			continue
+		}
+
+		if node.count > 0 {
+			for _, i := range b.indices[node.start : node.start+node.count] {
+				if b.Triangles != nil {
+					if t, v, w, hitTri := b.Triangles[i].IntersectRay(r, hit.T); hitTri {
+						hit, ok = RayHit{int(i), t, v, w}, true
+					}
+				} else if t, hitBox := b.Boxes[i].IntersectRay(r, hit.T); hitBox {
+					hit, ok = RayHit{int(i), t, 0, 0}, true
+				}
+			}
+			continue
+		}
+
+		// Visit the nearer child first, so hits in it can cull the further one
+		left, right := n+1, node.start
+		tLeft, hitLeft := b.nodes[left].bounds.IntersectRay(r, hit.T)
+		tRight, hitRight := b.nodes[right].bounds.IntersectRay(r, hit.T)
+		if hitLeft && hitRight && tLeft < tRight {
+			stack = append(stack, right, left)
+		} else {
+			if hitLeft {
+				stack = append(stack, left)
+			}
+			if hitRight {
+				stack = append(stack, right)
+			}
+		}
+	}
+
+	return hit, ok
+}
+
+// QueryAABB appends the indices of the primitives overlapping box to dst and returns the result.
+func (b *BVH) QueryAABB(box AABB, dst []int) []int {
+	return b.query(dst, box.Intersects, func(i int32) bool {
+		if b.Triangles != nil {
+			return b.Triangles[i].IntersectsAABB(box)
+		}
+		return b.Boxes[i].Intersects(box)
+	})
+}
+
+// QuerySphere appends the indices of the primitives overlapping the sphere to dst and returns the result.
+func (b *BVH) QuerySphere(center Vec3, radius float64, dst []int) []int {
+	overlaps := func(box AABB) bool { return box.IntersectsSphere(center, radius) }
+	return b.query(dst, overlaps, func(i int32) bool {
+		if b.Triangles != nil {
+			return b.Triangles[i].IntersectsSphere(center, radius)
+		}
+		return overlaps(b.Boxes[i])
+	})
+}
+
+// query appends the primitives in nodes for which visit returns true that pass the test.
+func (b *BVH) query(dst []int, visit func(AABB) bool, test func(int32) bool) []int {
+	if len(b.nodes) == 0 {
+		return dst
+	}
+
+	stack := []int32{0}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &b.nodes[n]
+		if !visit(node.bounds) {
+			continue
+		}
+
+		if node.count > 0 {
+			for _, i := range b.indices[node.start : node.start+node.count] {
+				if test(i) {
+					dst = append(dst, int(i))
+				}
+			}
+		} else {
+			stack = append(stack, node.start, n+1)
+		}
+	}
+
+	return dst
+}
+
+// Nearest returns the primitive closest to p, and its point closest to p, considering only primitives less than maxDist
+// away. For a box BVH, the closest box is returned. If there are no primitives within maxDist, ok is false.
+func (b *BVH) Nearest(p Vec3, maxDist float64) (index int, closest Vec3, ok bool) {
+	if len(b.nodes) == 0 {
+		return -1, Vec3{}, false
+	}
+
+	boxDist2 := func(box AABB) float64 {
+		d := box.ClosestPoint(p).Sub(p)
+		return d.Dot(d)
+	}
+
+	index, best := -1, maxDist*maxDist
+	stack := []int32{0}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &b.nodes[n]
+		if boxDist2(node.bounds) > best {
+			continue
+		}
+
+		if node.count > 0 {
+			for _, i := range b.indices[node.start : node.start+node.count] {
+				var q Vec3
+				if b.Triangles != nil {
+					q = b.Triangles[i].ClosestPoint(p)
+				} else {
+					q = b.Boxes[i].ClosestPoint(p)
+				}
+				if d := q.Sub(p); d.Dot(d) <= best {
+					index, closest, best = int(i), q, d.Dot(d)
+				}
+			}
+			continue
+		}
+
+		// Visit the nearer child first, so it can cull the further one
+		left, right := n+1, node.start
+		if boxDist2(b.nodes[left].bounds) < boxDist2(b.nodes[right].bounds) {
+			stack = append(stack, right, left)
+		} else {
+			stack = append(stack, left, right)
+		}
+	}
+
+	return index, closest, index >= 0
+}
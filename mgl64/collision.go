@@ -0,0 +1,493 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+)
+
+const (
+	gjkMaxIterations = 64
+	epaMaxIterations = 128
+	// Relative tolerances for GJK and EPA to decide they can't get any closer. EPA's is looser, since it
+	// builds up rounding errors in the polytope it expands.
+	collisionTolerance = 1e-6
+	epaTolerance       = 1e-4
+)
+
+// SphereShape is a sphere, as a SupportMapper.
+type SphereShape struct {
+	Center Vec3
+	Radius float64
+}
+
+// Support implements SupportMapper.
+func (s SphereShape) Support(direction Vec3) Vec3 {
+	l := direction.Len()
+	if l == 0 {
+		return s.Center.Add(Vec3{s.Radius, 0, 0})
+	}
+
+	return s.Center.Add(direction.Mul(s.Radius / l))
+}
+
+// BoxShape is an axis-aligned box, as a SupportMapper. Oriented boxes can be made by wrapping one in
+// a RigidShape or TransformedShape.
+type BoxShape struct {
+	Min, Max Vec3
+}
+
+// Support implements SupportMapper.
+func (b BoxShape) Support(direction Vec3) Vec3 {
+	p := b.Min
+	for i := range p {
+		if direction[i] > 0 {
+			p[i] = b.Max[i]
+		}
+	}
+
+	return p
+}
+
+// CapsuleShape is the set of points within Radius of the segment from A to B, as a SupportMapper.
+type CapsuleShape struct {
+	A, B   Vec3
+	Radius float64
+}
+
+// Support implements SupportMapper.
+func (c CapsuleShape) Support(direction Vec3) Vec3 {
+	center := c.A
+	if direction.Dot(c.B) > direction.Dot(c.A) {
+		center = c.B
+	}
+
+	return SphereShape{center, c.Radius}.Support(direction)
+}
+
+// TransformedShape is a shape transformed by an affine matrix, such as a model matrix. The matrix may
+// scale and shear the shape as well as rotate and translate it, but must not be a projection.
+type TransformedShape struct {
+	Shape     SupportMapper
+	Transform Mat4
+}
+
+// Support implements SupportMapper.
+func (t TransformedShape) Support(direction Vec3) Vec3 {
+	// The furthest point of M*x along d is the furthest point of x along M^T*d
+	local := t.Shape.Support(t.Transform.Mat3().Transpose().Mul3x1(direction))
+	return t.Transform.Mul4x1(local.Vec4(1)).Vec3()
+}
+
+// RigidShape is a shape rotated by Orientation (which must be a unit quaternion) and then moved to Position.
+type RigidShape struct {
+	Shape       SupportMapper
+	Orientation Quat
+	Position    Vec3
+}
+
+// Support implements SupportMapper.
+func (r RigidShape) Support(direction Vec3) Vec3 {
+	local := r.Shape.Support(r.Orientation.Conjugate().Rotate(direction))
+	return r.Orientation.Rotate(local).Add(r.Position)
+}
+
+// simplexVertex is a point of the Minkowski difference of shapes A and B, along with the points of A and B
+// it's made from, which are needed to find the closest or contact points of the shapes themselves.
+type simplexVertex struct {
+	w, a, b Vec3
+}
+
+func minkowskiSupport(a, b SupportMapper, direction Vec3) simplexVertex {
+	pa, pb := a.Support(direction), b.Support(direction.Mul(-1))
+	return simplexVertex{pa.Sub(pb), pa, pb}
+}
+
+// GJKIntersect returns whether the convex shapes a and b overlap (or touch), using the
+// Gilbert-Johnson-Keerthi algorithm.
+func GJKIntersect(a, b SupportMapper) bool {
+	_, _, _, intersect := gjk(a, b)
+	return intersect
+}
+
+// GJKDistance returns the distance between the convex shapes a and b, and the points of each shape that
+// are closest to each other. If the shapes overlap, the distance is 0 and the points are not meaningful;
+// use EPAPenetration to find out how deep they overlap.
+func GJKDistance(a, b SupportMapper) (distance float64, closestA, closestB Vec3) {
+	simplex, lambda, v, intersect := gjk(a, b)
+	for i, s := range simplex {
+		closestA = closestA.Add(s.a.Mul(lambda[i]))
+		closestB = closestB.Add(s.b.Mul(lambda[i]))
+	}
+
+	if intersect {
+		return 0, closestA, closestB
+	}
+	return v.Len(), closestA, closestB
+}
+
+// gjk runs the GJK distance algorithm on the Minkowski difference A-B. It returns the final simplex
+// and the barycentric coordinates of v, the point of the simplex closest to the origin, which is the point
+// of A-B closest to the origin unless the shapes intersect.
+func gjk(a, b SupportMapper) (simplex []simplexVertex, lambda []float64, v Vec3, intersect bool) {
+	simplex = []simplexVertex{minkowskiSupport(a, b, Vec3{1, 0, 0})}
+	lambda = []float64{1}
+	v = simplex[0].w
+
+	for i := 0; i < gjkMaxIterations; i++ {
+		vv := v.Dot(v)
+		if vv <= collisionTolerance*collisionTolerance*simplexScale(simplex) {
+			// The origin is on the simplex: the shapes touch or overlap
+			return simplex, lambda, v, true
+		}
+
+		w := minkowskiSupport(a, b, v.Mul(-1))
+		if vv-v.Dot(w.w) <= collisionTolerance*vv {
+			// The support point is no closer to the origin than v, so v is as close as it gets
+			return simplex, lambda, v, false
+		}
+		for _, s := range simplex {
+			if s.w == w.w {
+				return simplex, lambda, v, false
+			}
+		}
+
+		simplex = append(simplex, w)
+		v, lambda, simplex = closestOnSimplex(simplex)
+		if len(simplex) == 4 {
+			return simplex, lambda, v, true
+		}
+	}
+
+	return simplex, lambda, v, false
+}
+
+// simplexScale returns the squared length of the longest vector of the simplex, which scales tolerances
+// to the size of the shapes.
+func simplexScale(simplex []simplexVertex) float64 {
+	var scale float64
+	for _, s := range simplex {
+		l := s.w.Dot(s.w)
+		SetMax(&scale, &l)
+	}
+
+	return scale
+}
+
+// closestOnSimplex returns the point of the simplex (a point, segment, triangle or tetrahedron) closest to the origin,
+// along with the smallest sub-simplex containing that point and the barycentric coordinates of the point in it.
+// If the origin is inside a tetrahedron, the whole tetrahedron is returned.
+func closestOnSimplex(s []simplexVertex) (Vec3, []float64, []simplexVertex) {
+	switch len(s) {
+	case 1:
+		return s[0].w, []float64{1}, s
+	case 2:
+		return closestOnSegment(s[0], s[1])
+	case 3:
+		return closestOnTriangle(s[0], s[1], s[2])
+	}
+
+	a, b, c, d := s[0].w, s[1].w, s[2].w, s[3].w
+	faces := [4][4]int{{0, 1, 2, 3}, {0, 2, 3, 1}, {0, 3, 1, 2}, {1, 3, 2, 0}}
+	degenerate := Abs(b.Sub(a).Dot(c.Sub(a).Cross(d.Sub(a)))) <= collisionTolerance*simplexScale(s)*float64(math.Sqrt(float64(simplexScale(s))))
+
+	best := float64(math.Inf(1))
+	var bestV Vec3
+	var bestLambda []float64
+	var bestSimplex []simplexVertex
+	outside := false
+	for _, f := range faces {
+		p0, p1, p2, opposite := s[f[0]].w, s[f[1]].w, s[f[2]].w, s[f[3]].w
+		n := p1.Sub(p0).Cross(p2.Sub(p0))
+		// The origin is outside this face if it's on the other side from the opposite vertex
+		if !degenerate && n.Dot(p0.Mul(-1))*n.Dot(opposite.Sub(p0)) >= 0 {
+			continue
+		}
+
+		outside = true
+		v, lambda, sub := closestOnTriangle(s[f[0]], s[f[1]], s[f[2]])
+		if vv := v.Dot(v); vv < best {
+			best, bestV, bestLambda, bestSimplex = vv, v, lambda, sub
+		}
+	}
+
+	if !outside {
+		return Vec3{}, []float64{.25, .25, .25, .25}, s
+	}
+	return bestV, bestLambda, bestSimplex
+}
+
+func closestOnSegment(a, b simplexVertex) (Vec3, []float64, []simplexVertex) {
+	ab := b.w.Sub(a.w)
+	t := -a.w.Dot(ab)
+	if t <= 0 {
+		return a.w, []float64{1}, []simplexVertex{a}
+	}
+
+	l := ab.Dot(ab)
+	if t >= l {
+		return b.w, []float64{1}, []simplexVertex{b}
+	}
+
+	t /= l
+	return a.w.Add(ab.Mul(t)), []float64{1 - t, t}, []simplexVertex{a, b}
+}
+
+// closestOnTriangle finds the point of triangle abc closest to the origin by checking which Voronoi region of the
+// triangle the origin is in, as in Ericson's "Real-Time Collision Detection".
+func closestOnTriangle(a, b, c simplexVertex) (Vec3, []float64, []simplexVertex) {
+	ab, ac := b.w.Sub(a.w), c.w.Sub(a.w)
+
+	ap := a.w.Mul(-1)
+	d1, d2 := ab.Dot(ap), ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a.w, []float64{1}, []simplexVertex{a}
+	}
+
+	bp := b.w.Mul(-1)
+	d3, d4 := ab.Dot(bp), ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b.w, []float64{1}, []simplexVertex{b}
+	}
+
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return closestOnSegment(a, b)
+	}
+
+	cp := c.w.Mul(-1)
+	d5, d6 := ab.Dot(cp), ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c.w, []float64{1}, []simplexVertex{c}
+	}
+
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return closestOnSegment(a, c)
+	}
+
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		return closestOnSegment(b, c)
+	}
+
+	if va+vb+vc <= 0 {
+		// Degenerate triangle, all of its points are on one line
+		best, bestLambda, bestSimplex := closestOnSegment(a, b)
+		for _, e := range [][2]simplexVertex{{a, c}, {b, c}} {
+			if v, lambda, s := closestOnSegment(e[0], e[1]); v.Dot(v) < best.Dot(best) {
+				best, bestLambda, bestSimplex = v, lambda, s
+			}
+		}
+		return best, bestLambda, bestSimplex
+	}
+
+	denom := 1 / (va + vb + vc)
+	v, w := vb*denom, vc*denom
+	return a.w.Add(ab.Mul(v)).Add(ac.Mul(w)), []float64{1 - v - w, v, w}, []simplexVertex{a, b, c}
+}
+
+// Contact describes how two overlapping shapes A and B penetrate each other. Normal is the unit direction
+// to move B (or the opposite direction to move A) by Depth to separate them. PointA is the point of A
+// furthest inside B and PointB the point of B furthest inside A, so PointA-PointB = Normal*Depth.
+type Contact struct {
+	Normal         Vec3
+	Depth          float64
+	PointA, PointB Vec3
+}
+
+// EPAPenetration returns the contact between the convex shapes a and b, computed with the Expanding Polytope
+// Algorithm. If the shapes don't overlap, it returns false. Shapes that only touch have a Depth of 0.
+//
+// Round shapes can take many iterations to converge, especially when every direction is nearly as shallow as the best
+// one (such as for nearly concentric spheres). If the iteration limit is reached, the result may be a slight overestimate,
+// but moving the shapes apart by it still separates them.
+func EPAPenetration(a, b SupportMapper) (Contact, bool) {
+	simplex, _, _, intersect := gjk(a, b)
+	if !intersect {
+		return Contact{}, false
+	}
+
+	vertices, ok := epaTetrahedron(a, b, simplex)
+	if !ok {
+		// Everything found is flat, so the shapes can only be touching
+		var contact Contact
+		_, contact.PointA, contact.PointB = GJKDistance(a, b)
+		contact.Normal = contact.PointB.Sub(contact.PointA)
+		if contact.Normal.Len() == 0 {
+			contact.Normal = Vec3{1, 0, 0}
+		} else {
+			contact.Normal = contact.Normal.Normalize()
+		}
+		return contact, true
+	}
+
+	type face struct {
+		v      [3]int
+		normal Vec3
+		dist   float64
+	}
+
+	var faces []face
+	addFace := func(i, j, k int) {
+		p0, p1, p2 := vertices[i].w, vertices[j].w, vertices[k].w
+		n := p1.Sub(p0).Cross(p2.Sub(p0))
+		if l := n.Len(); l > 0 {
+			n = n.Mul(1 / l)
+			faces = append(faces, face{[3]int{i, j, k}, n, n.Dot(p0)})
+		}
+	}
+
+	// Wind the faces of the starting tetrahedron outwards
+	if vertices[1].w.Sub(vertices[0].w).Cross(vertices[2].w.Sub(vertices[0].w)).Dot(vertices[3].w.Sub(vertices[0].w)) > 0 {
+		vertices[1], vertices[2] = vertices[2], vertices[1]
+	}
+	addFace(0, 1, 2)
+	addFace(0, 3, 1)
+	addFace(0, 2, 3)
+	addFace(1, 3, 2)
+
+	// Every support query gives an upper bound on the depth: moving B by the support distance along the query
+	// direction separates the shapes. If the polytope can't be refined to the tolerance, the best of those is used.
+	var closest face
+	var edges [][2]int
+	var upper simplexVertex
+	upperNormal, upperDepth := Vec3{}, float64(math.Inf(1))
+	converged := false
+	for i := 0; i < epaMaxIterations && len(faces) > 0; i++ {
+		closest = faces[0]
+		for _, f := range faces[1:] {
+			if f.dist < closest.dist {
+				closest = f
+			}
+		}
+
+		w := minkowskiSupport(a, b, closest.normal)
+		d := w.w.Dot(closest.normal)
+		if d-closest.dist <= epaTolerance*(1+Abs(d)) {
+			converged = true
+			break
+		}
+		if d < upperDepth {
+			upper, upperNormal, upperDepth = w, closest.normal, d
+		}
+
+		duplicate := false
+		for _, v := range vertices {
+			duplicate = duplicate || v.w == w.w
+		}
+		if duplicate {
+			// Rounding errors have made the polytope slightly concave, it can't be expanded any further
+			break
+		}
+
+		// Remove every face the new point can see, and cover the hole with faces to the new point
+		vertices = append(vertices, w)
+		edges = edges[:0]
+		kept := faces[:0]
+		for _, f := range faces {
+			if f.normal.Dot(w.w.Sub(vertices[f.v[0]].w)) <= 0 {
+				kept = append(kept, f)
+				continue
+			}
+
+			for j := 0; j < 3; j++ {
+				e := [2]int{f.v[j], f.v[(j+1)%3]}
+				shared := false
+				for k, other := range edges {
+					if other[0] == e[1] && other[1] == e[0] {
+						edges = append(edges[:k], edges[k+1:]...)
+						shared = true
+						break
+					}
+				}
+				if !shared {
+					edges = append(edges, e)
+				}
+			}
+		}
+		faces = kept
+		for _, e := range edges {
+			addFace(e[0], e[1], len(vertices)-1)
+		}
+	}
+
+	if !converged && !math.IsInf(float64(upperDepth), 1) {
+		return Contact{upperNormal, upperDepth, upper.a, upper.a.Sub(upperNormal.Mul(upperDepth))}, true
+	}
+
+	// The contact points are found from the barycentric coordinates of the closest point on the closest face
+	p := closest.normal.Mul(closest.dist)
+	v0, v1, v2 := vertices[closest.v[0]], vertices[closest.v[1]], vertices[closest.v[2]]
+	u, v, w := barycentric(p, v0.w, v1.w, v2.w)
+
+	return Contact{
+		Normal: closest.normal,
+		Depth:  closest.dist,
+		PointA: v0.a.Mul(u).Add(v1.a.Mul(v)).Add(v2.a.Mul(w)),
+		PointB: v0.b.Mul(u).Add(v1.b.Mul(v)).Add(v2.b.Mul(w)),
+	}, true
+}
+
+// epaTetrahedron grows the simplex GJK ended with into a tetrahedron that contains the origin. GJK stops early
+// when the origin is on a point, segment or triangle, so more points are found by searching directions away from it.
+// It returns false if the Minkowski difference is flat.
+func epaTetrahedron(a, b SupportMapper, simplex []simplexVertex) ([]simplexVertex, bool) {
+	vertices := append([]simplexVertex(nil), simplex...)
+	axes := []Vec3{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+
+	for len(vertices) < 4 {
+		tolerance := collisionTolerance * float64(math.Sqrt(float64(simplexScale(vertices))))
+		var directions []Vec3
+		var accept func(w Vec3) bool
+
+		switch len(vertices) {
+		case 1:
+			directions = axes
+			accept = func(w Vec3) bool { return w.Sub(vertices[0].w).Len() > tolerance }
+		case 2:
+			axis := vertices[1].w.Sub(vertices[0].w).Normalize()
+			perp := anyPerpendicular(axis)
+			for i := 0; i < 6; i++ {
+				directions = append(directions, QuatRotate(float64(i)*math.Pi/3, axis).Rotate(perp))
+			}
+			accept = func(w Vec3) bool { return axis.Cross(w.Sub(vertices[0].w)).Len() > tolerance }
+		case 3:
+			n := vertices[1].w.Sub(vertices[0].w).Cross(vertices[2].w.Sub(vertices[0].w)).Normalize()
+			directions = []Vec3{n, n.Mul(-1)}
+			accept = func(w Vec3) bool { return Abs(n.Dot(w.Sub(vertices[0].w))) > tolerance }
+		}
+
+		found := false
+		for _, d := range directions {
+			if w := minkowskiSupport(a, b, d); accept(w.w) {
+				vertices = append(vertices, w)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	return vertices, true
+}
+
+// barycentric returns the barycentric coordinates of p (projected onto the plane of the triangle) in triangle abc.
+func barycentric(p, a, b, c Vec3) (u, v, w float64) {
+	v0, v1, v2 := b.Sub(a), c.Sub(a), p.Sub(a)
+	d00, d01, d11 := v0.Dot(v0), v0.Dot(v1), v1.Dot(v1)
+	d20, d21 := v2.Dot(v0), v2.Dot(v1)
+
+	denom := d00*d11 - d01*d01
+	if denom == 0 {
+		return 1, 0, 0
+	}
+
+	v = (d11*d20 - d01*d21) / denom
+	w = (d00*d21 - d01*d20) / denom
+	return 1 - v - w, v, w
+}
@@ -0,0 +1,157 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// closeTo compares vectors by the distance between them, since relative comparisons of components near 0 are too strict.
+func closeTo(a, b Vec3, threshold float64) bool {
+	return a.Sub(b).Len() <= threshold
+}
+
+func TestGJKDistance(t *testing.T) {
+	tests := []struct {
+		Name         string
+		A, B         SupportMapper
+		Distance     float64
+		PointA       Vec3
+		PointB       Vec3
+		CheckPoints  bool
+		Intersecting bool
+	}{
+		{"Spheres", SphereShape{Vec3{0, 0, 0}, 1}, SphereShape{Vec3{5, 0, 0}, 2}, 2, Vec3{1, 0, 0}, Vec3{3, 0, 0}, true, false},
+		{"Boxes", BoxShape{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, BoxShape{Vec3{3, 2, 0}, Vec3{4, 3, 1}}, Vec3{2, 1, 0}.Len(), Vec3{}, Vec3{}, false, false},
+		{"Box and sphere", BoxShape{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}, SphereShape{Vec3{0, 4, 0}, 1}, 2, Vec3{0, 1, 0}, Vec3{0, 3, 0}, true, false},
+		{"Capsule and sphere", CapsuleShape{Vec3{0, -2, 0}, Vec3{0, 2, 0}, .5}, SphereShape{Vec3{3, 1, 0}, 1}, 1.5, Vec3{.5, 1, 0}, Vec3{2, 1, 0}, true, false},
+		{"Overlapping spheres", SphereShape{Vec3{0, 0, 0}, 1}, SphereShape{Vec3{1, 1, 0}, 1}, 0, Vec3{}, Vec3{}, false, true},
+		{"Box inside box", BoxShape{Vec3{-2, -2, -2}, Vec3{2, 2, 2}}, BoxShape{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}, 0, Vec3{}, Vec3{}, false, true},
+	}
+
+	for _, test := range tests {
+		if intersect := GJKIntersect(test.A, test.B); intersect != test.Intersecting {
+			t.Errorf("%s: GJKIntersect returned %v, expected %v", test.Name, intersect, test.Intersecting)
+		}
+
+		dist, pa, pb := GJKDistance(test.A, test.B)
+		if !FloatEqualThreshold(dist, test.Distance, 1e-3) {
+			t.Errorf("%s: distance is %v, expected %v", test.Name, dist, test.Distance)
+		}
+		if test.CheckPoints && (!closeTo(pa, test.PointA, 5e-3) || !closeTo(pb, test.PointB, 5e-3)) {
+			t.Errorf("%s: closest points are %v and %v, expected %v and %v", test.Name, pa, pb, test.PointA, test.PointB)
+		}
+	}
+}
+
+func TestEPAPenetration(t *testing.T) {
+	tests := []struct {
+		Name   string
+		A, B   SupportMapper
+		Depth  float64
+		Normal Vec3
+	}{
+		{"Spheres", SphereShape{Vec3{0, 0, 0}, 1}, SphereShape{Vec3{1.5, 0, 0}, 1}, .5, Vec3{1, 0, 0}},
+		{"Boxes", BoxShape{Vec3{0, 0, 0}, Vec3{2, 2, 2}}, BoxShape{Vec3{1.8, .5, .5}, Vec3{3, 1.5, 1.5}}, .2, Vec3{1, 0, 0}},
+		{"Box on box", BoxShape{Vec3{-5, -1, -5}, Vec3{5, 0, 5}}, BoxShape{Vec3{-1, -.1, -1}, Vec3{1, 1.9, 1}}, .1, Vec3{0, 1, 0}},
+		{"Capsule and box", BoxShape{Vec3{-2, -2, -2}, Vec3{2, 2, 2}}, CapsuleShape{Vec3{-1, 2.25, 0}, Vec3{1, 2.25, 0}, .5}, .25, Vec3{0, 1, 0}},
+	}
+
+	for _, test := range tests {
+		contact, ok := EPAPenetration(test.A, test.B)
+		if !ok {
+			t.Errorf("%s: shapes are reported as not overlapping", test.Name)
+			continue
+		}
+
+		if !FloatEqualThreshold(contact.Depth, test.Depth, 1e-3) || !closeTo(contact.Normal, test.Normal, 1e-2) {
+			t.Errorf("%s: penetration is %v along %v, expected %v along %v", test.Name, contact.Depth, contact.Normal, test.Depth, test.Normal)
+		}
+		if !closeTo(contact.PointA.Sub(contact.PointB), contact.Normal.Mul(contact.Depth), 1e-3) {
+			t.Errorf("%s: contact points %v and %v don't match the penetration", test.Name, contact.PointA, contact.PointB)
+		}
+	}
+
+	if _, ok := EPAPenetration(SphereShape{Vec3{0, 0, 0}, 1}, SphereShape{Vec3{3, 0, 0}, 1}); ok {
+		t.Errorf("Separate spheres are reported as overlapping")
+	}
+}
+
+func TestTransformedShapes(t *testing.T) {
+	cube := BoxShape{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}
+	rotation := QuatRotate(DegToRad(45), Vec3{0, 0, 1})
+
+	// Rotated 45 degrees, the cube's corner edge reaches sqrt(2) along X
+	rigid := RigidShape{cube, rotation, Vec3{10, 0, 0}}
+	if s := rigid.Support(Vec3{1, 0, 0}); !FloatEqualThreshold(s[0], 10+math.Sqrt2, 1e-5) {
+		t.Errorf("Support of rotated cube along X is %v, expected X = %v", s, 10+math.Sqrt2)
+	}
+
+	transformed := TransformedShape{cube, Translate3D(10, 0, 0).Mul4(rotation.Mat4())}
+	for i := 0; i < 10; i++ {
+		dir := Vec3{float64(i) - 5, 3, float64(i * i)}
+		if a, b := rigid.Support(dir), transformed.Support(dir); !FloatEqualThreshold(a.Dot(dir), b.Dot(dir), 1e-4) {
+			t.Errorf("Rigid and matrix transformed shapes have different support points %v and %v along %v", a, b, dir)
+		}
+	}
+
+	sphere := SphereShape{Vec3{11.9, 0, 0}, .5}
+	if contact, ok := EPAPenetration(rigid, sphere); !ok || !FloatEqualThreshold(contact.Depth, 10+math.Sqrt2+.5-11.9, 1e-3) {
+		t.Errorf("Penetration of sphere into rotated cube is %v, expected %v", contact.Depth, 10+math.Sqrt2+.5-11.9)
+	}
+
+	scaled := TransformedShape{SphereShape{Vec3{}, 1}, Scale3D(3, 1, 1)}
+	if dist, _, _ := GJKDistance(scaled, SphereShape{Vec3{5, 0, 0}, 1}); !FloatEqualThreshold(dist, 1, 1e-3) {
+		t.Errorf("Distance from ellipsoid to sphere is %v, expected 1", dist)
+	}
+}
+
+func TestGJKRandomSpheres(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	hullPoints := make([]Vec3, 100)
+	for i := range hullPoints {
+		hullPoints[i] = Vec3{float64(rand.NormFloat64()), float64(rand.NormFloat64()), float64(rand.NormFloat64())}.Normalize()
+	}
+	hull, err := NewConvexHull3D(hullPoints)
+	if err != nil {
+		t.Fatalf("Hull of sphere points failed: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		a := SphereShape{Vec3{rand.Float64()*4 - 2, rand.Float64()*4 - 2, rand.Float64()*4 - 2}, rand.Float64() + .5}
+		b := SphereShape{Vec3{rand.Float64()*4 - 2, rand.Float64()*4 - 2, rand.Float64()*4 - 2}, rand.Float64() + .5}
+		gap := b.Center.Sub(a.Center).Len() - a.Radius - b.Radius
+
+		if gap > 1e-3 {
+			if dist, _, _ := GJKDistance(a, b); !FloatEqualThreshold(dist, gap, 1e-3) {
+				t.Errorf("Distance between %v and %v is %v, expected %v", a, b, dist, gap)
+			}
+		} else if gap < -1e-3 && b.Center.Sub(a.Center).Len() > .2 {
+			// Nearly concentric spheres are the worst case for EPA, since every direction is almost as good as
+			// the best one and the polytope has to be refined everywhere, so they're skipped. Deep penetrations
+			// are only checked to 1%.
+			contact, ok := EPAPenetration(a, b)
+			if !ok || Abs(contact.Depth+gap) > 1e-3+1e-2*Abs(gap) {
+				t.Errorf("Penetration between %v and %v is %v, expected %v", a, b, contact.Depth, -gap)
+			}
+		}
+
+		// Moving the sphere out of the hull by the penetration must separate them, and moving it any less must not
+		if contact, ok := EPAPenetration(hull, a); ok && contact.Depth > 1e-2 {
+			moved := a
+			moved.Center = a.Center.Add(contact.Normal.Mul(contact.Depth + 1e-3))
+			if GJKIntersect(hull, moved) {
+				t.Errorf("Hull and %v still overlap after moving by the penetration %v along %v", a, contact.Depth, contact.Normal)
+			}
+			moved.Center = a.Center.Add(contact.Normal.Mul(contact.Depth - 1e-2))
+			if !GJKIntersect(hull, moved) {
+				t.Errorf("Hull and %v are separated by moving less than the penetration %v along %v", a, contact.Depth, contact.Normal)
+			}
+		}
+	}
+}
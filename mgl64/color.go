@@ -0,0 +1,299 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+)
+
+// Colors are stored in Vec3s (RGB) and Vec4s (RGBA, with alpha last). Unless a function says otherwise, RGB colors are
+// linear, with components nominally in [0,1] and the sRGB primaries and white point. Lighting, blending and filtering
+// should be done on linear colors; sRGB encoded colors are what images and color pickers hold.
+
+// SRGBComponentToLinear converts an sRGB encoded color component to linear, using the exact sRGB transfer function rather
+// than a gamma of 2.2.
+func SRGBComponentToLinear(c float64) float64 {
+	if c <= .04045 {
+		return c / 12.92
+	}
+	return float64(math.Pow((float64(c)+.055)/1.055, 2.4))
+}
+
+// LinearComponentToSRGB converts a linear color component to sRGB encoding. It is the inverse of SRGBComponentToLinear.
+func LinearComponentToSRGB(c float64) float64 {
+	if c <= .0031308 {
+		return c * 12.92
+	}
+	return float64(1.055*math.Pow(float64(c), 1/2.4) - .055)
+}
+
+// SRGBToLinear converts an sRGB encoded color to linear. Use Vec4.Vec3 and Vec3.Vec4 to keep the alpha of RGBA colors,
+// which is always linear.
+func SRGBToLinear(c Vec3) Vec3 {
+	return Vec3{SRGBComponentToLinear(c[0]), SRGBComponentToLinear(c[1]), SRGBComponentToLinear(c[2])}
+}
+
+// LinearToSRGB converts a linear color to sRGB encoding.
+func LinearToSRGB(c Vec3) Vec3 {
+	return Vec3{LinearComponentToSRGB(c[0]), LinearComponentToSRGB(c[1]), LinearComponentToSRGB(c[2])}
+}
+
+// RGBToHSV converts an RGB color to hue, saturation and value. The hue is in [0,1), wrapping from red through green and
+// blue back to red, and is 0 for grays. HSV and HSL are defined on whichever RGB encoding they're given, normally sRGB.
+func RGBToHSV(c Vec3) Vec3 {
+	max, min := componentRange(c)
+	chroma := max - min
+
+	var s float64
+	if max > 0 {
+		s = chroma / max
+	}
+
+	return Vec3{hue(c, max, chroma), s, max}
+}
+
+// HSVToRGB converts hue, saturation and value to an RGB color. It is the inverse of RGBToHSV, and any hue is allowed.
+func HSVToRGB(c Vec3) Vec3 {
+	chroma := c[1] * c[2]
+	return hueToRGB(c[0], chroma).Add(Vec3{1, 1, 1}.Mul(c[2] - chroma))
+}
+
+// RGBToHSL converts an RGB color to hue, saturation and lightness, with the hue as in RGBToHSV.
+func RGBToHSL(c Vec3) Vec3 {
+	max, min := componentRange(c)
+	chroma := max - min
+	l := (max + min) / 2
+
+	var s float64
+	if l > 0 && l < 1 {
+		s = chroma / (1 - Abs(2*l-1))
+	}
+
+	return Vec3{hue(c, max, chroma), s, l}
+}
+
+// HSLToRGB converts hue, saturation and lightness to an RGB color. It is the inverse of RGBToHSL.
+func HSLToRGB(c Vec3) Vec3 {
+	chroma := (1 - Abs(2*c[2]-1)) * c[1]
+	return hueToRGB(c[0], chroma).Add(Vec3{1, 1, 1}.Mul(c[2] - chroma/2))
+}
+
+// componentRange returns the largest and smallest components of c.
+func componentRange(c Vec3) (max, min float64) {
+	max, min = c[0], c[0]
+	for _, x := range c[1:] {
+		SetMax(&max, &x)
+		SetMin(&min, &x)
+	}
+
+	return max, min
+}
+
+// hue returns the hue of c in [0,1), given its largest component and its chroma.
+func hue(c Vec3, max, chroma float64) float64 {
+	if chroma == 0 {
+		return 0
+	}
+
+	var h float64
+	switch max {
+	case c[0]:
+		h = (c[1] - c[2]) / chroma
+		if h < 0 {
+			h += 6
+		}
+	case c[1]:
+		h = (c[2]-c[0])/chroma + 2
+	default:
+		h = (c[0]-c[1])/chroma + 4
+	}
+
+	return h / 6
+}
+
+// hueToRGB returns the color with the given hue and chroma whose smallest component is 0.
+func hueToRGB(h, chroma float64) Vec3 {
+	h = (h - float64(math.Floor(float64(h)))) * 6
+	x := chroma * (1 - Abs(float64(math.Mod(float64(h), 2))-1))
+
+	switch {
+	case h < 1:
+		return Vec3{chroma, x, 0}
+	case h < 2:
+		return Vec3{x, chroma, 0}
+	case h < 3:
+		return Vec3{0, chroma, x}
+	case h < 4:
+		return Vec3{0, x, chroma}
+	case h < 5:
+		return Vec3{x, 0, chroma}
+	default:
+		return Vec3{chroma, 0, x}
+	}
+}
+
+var (
+	// linearToXYZ converts linear sRGB to CIE XYZ with the D65 white point
+	linearToXYZ = Mat3FromRows(
+		Vec3{.4124564, .3575761, .1804375},
+		Vec3{.2126729, .7151522, .0721750},
+		Vec3{.0193339, .1191920, .9503041},
+	)
+	xyzToLinear = Mat3FromRows(
+		Vec3{3.2404542, -1.5371385, -.4985314},
+		Vec3{-.9692660, 1.8760108, .0415560},
+		Vec3{.0556434, -.2040259, 1.0572252},
+	)
+
+	// The D65 white point in XYZ, where Y is 1
+	whiteD65 = Vec3{.95047, 1, 1.08883}
+)
+
+// LinearToXYZ converts a linear color to CIE 1931 XYZ, relative to the D65 white point. Y is the relative luminance.
+func LinearToXYZ(c Vec3) Vec3 {
+	return linearToXYZ.Mul3x1(c)
+}
+
+// XYZToLinear converts a CIE 1931 XYZ color to linear RGB. Colors outside the sRGB gamut have components outside [0,1].
+func XYZToLinear(c Vec3) Vec3 {
+	return xyzToLinear.Mul3x1(c)
+}
+
+// Luminance returns the relative luminance of a linear color, the Y of its XYZ.
+func Luminance(c Vec3) float64 {
+	return linearToXYZ.Row(1).Dot(c)
+}
+
+// XYZToLab converts a CIE XYZ color to CIE L*a*b*, relative to the D65 white point. L* is in [0,100].
+func XYZToLab(c Vec3) Vec3 {
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return float64(math.Cbrt(float64(t)))
+		}
+		return t*24389/3132 + 4.0/29
+	}
+
+	x, y, z := f(c[0]/whiteD65[0]), f(c[1]), f(c[2]/whiteD65[2])
+	return Vec3{116*y - 16, 500 * (x - y), 200 * (y - z)}
+}
+
+// LabToXYZ converts a CIE L*a*b* color to XYZ. It is the inverse of XYZToLab.
+func LabToXYZ(c Vec3) Vec3 {
+	fInv := func(t float64) float64 {
+		if t > 6.0/29 {
+			return t * t * t
+		}
+		return (t - 4.0/29) * 3132 / 24389
+	}
+
+	y := (c[0] + 16) / 116
+	return Vec3{fInv(y+c[1]/500) * whiteD65[0], fInv(y), fInv(y-c[2]/200) * whiteD65[2]}
+}
+
+var (
+	// From Björn Ottosson's definition of OKLab
+	linearToLMS = Mat3FromRows(
+		Vec3{.4122214708, .5363325363, .0514459929},
+		Vec3{.2119034982, .6806995451, .1073969566},
+		Vec3{.0883024619, .2817188376, .6299787005},
+	)
+	lmsToOKLab = Mat3FromRows(
+		Vec3{.2104542553, .7936177850, -.0040720468},
+		Vec3{1.9779984951, -2.4285922050, .4505937099},
+		Vec3{.0259040371, .7827717662, -.8086757660},
+	)
+	okLabToLMS = Mat3FromRows(
+		Vec3{1, .3963377774, .2158037573},
+		Vec3{1, -.1055613458, -.0638541728},
+		Vec3{1, -.0894841775, -1.2914855480},
+	)
+	lmsToLinear = Mat3FromRows(
+		Vec3{4.0767416621, -3.3077115913, .2309699292},
+		Vec3{-1.2684380046, 2.6097574011, -.3413193965},
+		Vec3{-.0041960863, -.7034186147, 1.7076147010},
+	)
+)
+
+// LinearToOKLab converts a linear color to OKLab, where L is the perceived lightness in [0,1] and a and b are the
+// green-red and blue-yellow axes. Euclidean distances in OKLab match perceived color differences better than in L*a*b*,
+// especially for blues.
+func LinearToOKLab(c Vec3) Vec3 {
+	lms := linearToLMS.Mul3x1(c)
+	for i := range lms {
+		lms[i] = float64(math.Cbrt(float64(lms[i])))
+	}
+
+	return lmsToOKLab.Mul3x1(lms)
+}
+
+// OKLabToLinear converts an OKLab color to linear RGB. It is the inverse of LinearToOKLab.
+func OKLabToLinear(c Vec3) Vec3 {
+	lms := okLabToLMS.Mul3x1(c)
+	for i := range lms {
+		lms[i] = lms[i] * lms[i] * lms[i]
+	}
+
+	return lmsToLinear.Mul3x1(lms)
+}
+
+// OKLabLerp interpolates between two linear colors through OKLab, so that the colors in between change evenly in
+// perceived lightness and hue, and don't darken and desaturate the way they do when interpolating RGB.
+func OKLabLerp(c1, c2 Vec3, amount float64) Vec3 {
+	a, b := LinearToOKLab(c1), LinearToOKLab(c2)
+	return OKLabToLinear(a.Add(b.Sub(a).Mul(amount)))
+}
+
+// Premultiply multiplies the color of a linear RGBA color by its alpha. Premultiplied colors blend and filter correctly,
+// and are what the common "one, one minus source alpha" blend function expects.
+func Premultiply(c Vec4) Vec4 {
+	return Vec4{c[0] * c[3], c[1] * c[3], c[2] * c[3], c[3]}
+}
+
+// Unpremultiply divides the color of a premultiplied RGBA color by its alpha. It is the inverse of Premultiply, except
+// that fully transparent colors become transparent black.
+func Unpremultiply(c Vec4) Vec4 {
+	if c[3] == 0 {
+		return Vec4{}
+	}
+	return Vec4{c[0] / c[3], c[1] / c[3], c[2] / c[3], c[3]}
+}
+
+// unorm converts c, clamped to [0,1], to an unsigned integer with the given maximum, rounding to the nearest.
+func unorm(c float64, max uint32) uint32 {
+	return uint32(Clamp(c, 0, 1)*float64(max) + .5)
+}
+
+// PackRGBA8 packs an RGBA color into 8 bits per component, red in the lowest byte. In little endian memory that's the
+// byte order R, G, B, A, which is GL_RGBA with GL_UNSIGNED_BYTE. Components are clamped to [0,1] and rounded; the
+// color isn't converted to sRGB, so do that first for an sRGB texture or framebuffer.
+func PackRGBA8(c Vec4) uint32 {
+	return unorm(c[0], 0xff) | unorm(c[1], 0xff)<<8 | unorm(c[2], 0xff)<<16 | unorm(c[3], 0xff)<<24
+}
+
+// UnpackRGBA8 unpacks a color packed by PackRGBA8.
+func UnpackRGBA8(p uint32) Vec4 {
+	return Vec4{
+		float64(p&0xff) / 0xff,
+		float64(p>>8&0xff) / 0xff,
+		float64(p>>16&0xff) / 0xff,
+		float64(p>>24) / 0xff,
+	}
+}
+
+// PackRGB10A2 packs an RGBA color into 10 bits for each of red, green and blue and 2 bits for alpha, red in the lowest
+// bits, which is GL_RGB10_A2 with GL_UNSIGNED_INT_2_10_10_10_REV. Components are clamped to [0,1] and rounded.
+func PackRGB10A2(c Vec4) uint32 {
+	return unorm(c[0], 0x3ff) | unorm(c[1], 0x3ff)<<10 | unorm(c[2], 0x3ff)<<20 | unorm(c[3], 3)<<30
+}
+
+// UnpackRGB10A2 unpacks a color packed by PackRGB10A2.
+func UnpackRGB10A2(p uint32) Vec4 {
+	return Vec4{
+		float64(p&0x3ff) / 0x3ff,
+		float64(p>>10&0x3ff) / 0x3ff,
+		float64(p>>20&0x3ff) / 0x3ff,
+		float64(p>>30) / 3,
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSRGB(t *testing.T) {
+	tests := []struct{ SRGB, Linear float64 }{
+		{0, 0},
+		{.02, .02 / 12.92},
+		{.5, .21404114},
+		{1, 1},
+	}
+	for _, c := range tests {
+		if linear := SRGBComponentToLinear(c.SRGB); !FloatEqualThreshold(linear, c.Linear, 1e-6) {
+			t.Errorf("sRGB %v is linear %v, expected %v", c.SRGB, linear, c.Linear)
+		}
+		if srgb := LinearComponentToSRGB(c.Linear); !FloatEqualThreshold(srgb, c.SRGB, 1e-6) {
+			t.Errorf("Linear %v is sRGB %v, expected %v", c.Linear, srgb, c.SRGB)
+		}
+	}
+}
+
+func TestColorKnownValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		f         func(Vec3) Vec3
+		in, out   Vec3
+		threshold float64
+	}{
+		{"RGBToHSV", RGBToHSV, Vec3{1, 0, 0}, Vec3{0, 1, 1}, 1e-6},
+		{"RGBToHSV", RGBToHSV, Vec3{0, .5, 1}, Vec3{7. / 12, 1, 1}, 1e-6},
+		{"RGBToHSV", RGBToHSV, Vec3{.5, .5, .5}, Vec3{0, 0, .5}, 1e-6},
+		{"RGBToHSV", RGBToHSV, Vec3{.8, .2, .4}, Vec3{1 - 1./18, .75, .8}, 1e-6},
+		{"RGBToHSL", RGBToHSL, Vec3{0, .5, 1}, Vec3{7. / 12, 1, .5}, 1e-6},
+		{"RGBToHSL", RGBToHSL, Vec3{.25, .75, .25}, Vec3{1. / 3, .5, .5}, 1e-6},
+		{"LinearToXYZ", LinearToXYZ, Vec3{1, 1, 1}, whiteD65, 1e-4},
+		{"XYZToLab", XYZToLab, whiteD65, Vec3{100, 0, 0}, 1e-3},
+		{"XYZToLab", XYZToLab, LinearToXYZ(Vec3{1, 0, 0}), Vec3{53.24, 80.09, 67.20}, 1e-2},
+		{"LinearToOKLab", LinearToOKLab, Vec3{1, 1, 1}, Vec3{1, 0, 0}, 1e-4},
+		{"LinearToOKLab", LinearToOKLab, Vec3{1, 0, 0}, Vec3{.6279554, .22486306, .1258463}, 1e-4},
+		{"LinearToOKLab", LinearToOKLab, Vec3{0, 0, 1}, Vec3{.4520137, -.032456984, -.31152815}, 1e-4},
+	}
+	for _, c := range tests {
+		if out := c.f(c.in); out.Sub(c.out).Len() > c.threshold*(1+c.out.Len()) {
+			t.Errorf("%s(%v) is %v, expected %v", c.name, c.in, out, c.out)
+		}
+	}
+}
+
+func TestColorRoundTrip(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	conversions := []struct {
+		name     string
+		to, from func(Vec3) Vec3
+	}{
+		{"sRGB", LinearToSRGB, SRGBToLinear},
+		{"HSV", RGBToHSV, HSVToRGB},
+		{"HSL", RGBToHSL, HSLToRGB},
+		{"XYZ", LinearToXYZ, XYZToLinear},
+		{"Lab", func(c Vec3) Vec3 { return XYZToLab(LinearToXYZ(c)) }, func(c Vec3) Vec3 { return XYZToLinear(LabToXYZ(c)) }},
+		{"OKLab", LinearToOKLab, OKLabToLinear},
+	}
+
+	for i := 0; i < 1000; i++ {
+		c := Vec3{rand.Float64(), rand.Float64(), rand.Float64()}
+		if i < 8 {
+			// The corners of the RGB cube
+			c = Vec3{float64(i & 1), float64(i >> 1 & 1), float64(i >> 2 & 1)}
+		}
+
+		for _, conv := range conversions {
+			if back := conv.from(conv.to(c)); back.Sub(c).Len() > 1e-4 {
+				t.Errorf("%v converted to %s and back is %v", c, conv.name, back)
+			}
+		}
+	}
+}
+
+func TestOKLabLerp(t *testing.T) {
+	black, white := Vec3{}, Vec3{1, 1, 1}
+	if c := OKLabLerp(black, white, 0); c.Sub(black).Len() > 1e-5 {
+		t.Errorf("Start of interpolation is %v, expected %v", c, black)
+	}
+	if c := OKLabLerp(black, white, 1); c.Sub(white).Len() > 1e-5 {
+		t.Errorf("End of interpolation is %v, expected %v", c, white)
+	}
+
+	// Half way is perceptually middle gray, which is much darker than half the linear intensity
+	mid := OKLabLerp(black, white, .5)
+	if !FloatEqualThreshold(LinearToOKLab(mid)[0], .5, 1e-4) || mid[0] > .2 {
+		t.Errorf("Middle of black to white is %v, expected perceptual middle gray", mid)
+	}
+
+	// Unlike in RGB, blue to yellow doesn't pass through gray
+	blueYellow := OKLabLerp(Vec3{0, 0, 1}, Vec3{1, 1, 0}, .5)
+	if hsv := RGBToHSV(LinearToSRGB(blueYellow)); hsv[1] < .1 {
+		t.Errorf("Middle of blue to yellow is gray: %v", blueYellow)
+	}
+}
+
+func TestPremultiply(t *testing.T) {
+	c := Vec4{.8, .4, .2, .5}
+	if p := Premultiply(c); p != (Vec4{.4, .2, .1, .5}) {
+		t.Errorf("Premultiplied %v is %v, expected %v", c, p, Vec4{.4, .2, .1, .5})
+	}
+	if u := Unpremultiply(Premultiply(c)); !u.ApproxEqual(c) {
+		t.Errorf("Unpremultiplied %v is %v", Premultiply(c), u)
+	}
+	if u := Unpremultiply(Vec4{.1, .2, .3, 0}); u != (Vec4{}) {
+		t.Errorf("Unpremultiplied transparent color is %v, expected transparent black", u)
+	}
+}
+
+func TestColorPacking(t *testing.T) {
+	if p := PackRGBA8(Vec4{1, 0, .5, 2}); p != 0xff8000ff {
+		t.Errorf("Packed RGBA8 is %#x, expected %#x", p, 0xff8000ff)
+	}
+	if p := PackRGB10A2(Vec4{1, 0, -1, 1. / 3}); p != 0x400003ff {
+		t.Errorf("Packed RGB10A2 is %#x, expected %#x", p, 0x400003ff)
+	}
+
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 1000; i++ {
+		c := Vec4{rand.Float64(), rand.Float64(), rand.Float64(), rand.Float64()}
+
+		u8, u10 := UnpackRGBA8(PackRGBA8(c)), UnpackRGB10A2(PackRGB10A2(c))
+		for j := range c {
+			if Abs(u8[j]-c[j]) > .5/255+1e-6 {
+				t.Errorf("%v packed to RGBA8 and back is %v", c, u8)
+			}
+			if max := []float64{1023, 1023, 1023, 3}[j]; Abs(u10[j]-c[j]) > .5/max+1e-6 {
+				t.Errorf("%v packed to RGB10A2 and back is %v", c, u10)
+			}
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// A SupportMapper is a convex shape described by its support function: Support returns the point
+// of the shape that is furthest in the given direction (which need not be normalized). If several points
+// are equally far, any of them may be returned.
+//
+// Support functions are all that the GJK and EPA collision algorithms need to know about a shape.
+type SupportMapper interface {
+	Support(direction Vec3) Vec3
+}
+
+// ConvexHull3D is the convex hull of a set of points. Faces are triangles wound counter-clockwise when
+// seen from outside the hull, so their normals point outwards. All indices refer to Points.
+type ConvexHull3D struct {
+	Points []Vec3
+	// Vertices contains the indices of the points that are corners of the hull, in increasing order.
+	Vertices []uint32
+	Faces    [][3]uint32
+	// Edges contains every edge of Faces once, with the lower index first.
+	Edges [][2]uint32
+}
+
+// hullFace is a face of the hull under construction. The plane is stored in 64-bit precision,
+// since the distance tests decide the hull's topology.
+type hullFace struct {
+	v       [3]int
+	normal  [3]float64
+	offset  float64
+	outside []int
+	dead    bool
+}
+
+// NewConvexHull3D computes the convex hull of points using the Quickhull algorithm. Points inside the hull, or
+// on its surface within numerical tolerance, are not part of it. Coplanar faces are not merged, so flat sides of the
+// hull may be made of several triangles.
+//
+// An error is returned if there are fewer than 4 points, or if all of them are coplanar. The points slice is kept
+// (not copied) as the Points field.
+func NewConvexHull3D(points []Vec3) (*ConvexHull3D, error) {
+	if len(points) < 4 {
+		return nil, errors.New("at least four points are needed for a convex hull")
+	}
+
+	coords := make([][3]float64, len(points))
+	var extent float64
+	for i, p := range points {
+		coords[i] = [3]float64{float64(p[0]), float64(p[1]), float64(p[2])}
+		for j := 0; j < 3; j++ {
+			extent = math.Max(extent, math.Abs(coords[i][j]))
+		}
+	}
+	// The planes are exact but for float64 rounding, so a face is visible from any point in front of it by more
+	// than eps, which keeps the hull convex.
+	eps := 3 * extent * 1e-12
+	if eps == 0 {
+		return nil, errors.New("points are coplanar, they have no convex hull")
+	}
+	// A point must be further out than coplanar, which is well above the rounding error of the points' coordinates,
+	// to be outside the hull, so points that are on its surface but for rounding don't become corners of sliver faces.
+	coplanar := eps
+	if floatBits == 32 {
+		coplanar = 3 * extent * 1e-6
+	}
+
+	tetra, err := hullSimplex(coords, coplanar)
+	if err != nil {
+		return nil, err
+	}
+
+	faces := make([]*hullFace, 0, 4)
+	edges := make(map[[2]int]int)
+	addFace := func(a, b, c int) int {
+		f := &hullFace{v: [3]int{a, b, c}}
+		n := hullCross(hullSub(coords[b], coords[a]), hullSub(coords[c], coords[a]))
+		l := math.Sqrt(hullDot(n, n))
+		f.normal = [3]float64{n[0] / l, n[1] / l, n[2] / l}
+		f.offset = hullDot(f.normal, coords[a])
+
+		faces = append(faces, f)
+		edges[[2]int{a, b}], edges[[2]int{b, c}], edges[[2]int{c, a}] = len(faces)-1, len(faces)-1, len(faces)-1
+		return len(faces) - 1
+	}
+	distance := func(f *hullFace, p int) float64 {
+		return hullDot(f.normal, coords[p]) - f.offset
+	}
+
+	a, b, c, d := tetra[0], tetra[1], tetra[2], tetra[3]
+	addFace(a, b, c)
+	addFace(a, d, b)
+	addFace(b, d, c)
+	addFace(c, d, a)
+
+	// Each point outside the hull is assigned to one face it can see
+	for p := range coords {
+		if p == a || p == b || p == c || p == d {
+			continue
+		}
+		for _, f := range faces {
+			if distance(f, p) > coplanar {
+				f.outside = append(f.outside, p)
+				break
+			}
+		}
+	}
+
+	pending := []int{0, 1, 2, 3}
+	var visible, newFaces []int
+	var horizon [][2]int
+	var orphans []int
+	for len(pending) > 0 {
+		fi := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		f := faces[fi]
+		if f.dead || len(f.outside) == 0 {
+			continue
+		}
+
+		// The point furthest from the face is certainly on the hull
+		eye, _ := hullCorner(coords, f.outside, coplanar, hullCentroid(coords[f.v[0]], coords[f.v[1]], coords[f.v[2]]),
+			func(p [3]float64) float64 { return hullDot(f.normal, p) - f.offset })
+
+		// Find every face the point can see, and the horizon edges between them and the rest
+		visible, horizon = append(visible[:0], fi), horizon[:0]
+		f.dead = true
+		for i := 0; i < len(visible); i++ {
+			vf := faces[visible[i]]
+			for j := 0; j < 3; j++ {
+				u, v := vf.v[j], vf.v[(j+1)%3]
+				ni := edges[[2]int{v, u}]
+				nf := faces[ni]
+				if nf.dead {
+					continue
+				}
+				if distance(nf, eye) > eps {
+					nf.dead = true
+					visible = append(visible, ni)
+				} else {
+					horizon = append(horizon, [2]int{u, v})
+				}
+			}
+		}
+
+		orphans = orphans[:0]
+		for _, vi := range visible {
+			vf := faces[vi]
+			for j := 0; j < 3; j++ {
+				delete(edges, [2]int{vf.v[j], vf.v[(j+1)%3]})
+			}
+			orphans = append(orphans, vf.outside...)
+			vf.outside = nil
+		}
+
+		// Cone the horizon to the new point, which keeps the hull closed since the horizon is a single loop
+		newFaces = newFaces[:0]
+		for _, e := range horizon {
+			newFaces = append(newFaces, addFace(e[0], e[1], eye))
+		}
+
+		for _, p := range orphans {
+			if p == eye {
+				continue
+			}
+			for _, ni := range newFaces {
+				if distance(faces[ni], p) > coplanar {
+					faces[ni].outside = append(faces[ni].outside, p)
+					break
+				}
+			}
+		}
+		pending = append(pending, newFaces...)
+	}
+
+	h := &ConvexHull3D{Points: points}
+	used := make(map[uint32]bool)
+	for _, f := range faces {
+		if f.dead {
+			continue
+		}
+
+		face := [3]uint32{uint32(f.v[0]), uint32(f.v[1]), uint32(f.v[2])}
+		h.Faces = append(h.Faces, face)
+		for j := 0; j < 3; j++ {
+			u, v := face[j], face[(j+1)%3]
+			if !used[u] {
+				used[u] = true
+				h.Vertices = append(h.Vertices, u)
+			}
+			if u < v {
+				h.Edges = append(h.Edges, [2]uint32{u, v})
+			}
+		}
+	}
+	sort.Slice(h.Vertices, func(i, j int) bool { return h.Vertices[i] < h.Vertices[j] })
+
+	return h, nil
+}
+
+// hullSimplex finds four points spanning a tetrahedron as large as can cheaply be found, ordered
+// so that the triangle of the first three is counter-clockwise when seen from outside.
+func hullSimplex(coords [][3]float64, eps float64) ([4]int, error) {
+	all := make([]int, len(coords))
+	var mean [3]float64
+	for i, p := range coords {
+		all[i] = i
+		for j := 0; j < 3; j++ {
+			mean[j] += p[j] / float64(len(coords))
+		}
+	}
+
+	// The two most distant of the extreme points along each axis
+	var extremes [6]int
+	for j := 0; j < 3; j++ {
+		extremes[2*j], _ = hullCorner(coords, all, eps, mean, func(p [3]float64) float64 { return -p[j] })
+		extremes[2*j+1], _ = hullCorner(coords, all, eps, mean, func(p [3]float64) float64 { return p[j] })
+	}
+
+	var simplex [4]int
+	best := 0.0
+	for i := range extremes {
+		for j := i + 1; j < len(extremes); j++ {
+			d := hullSub(coords[extremes[i]], coords[extremes[j]])
+			if l := hullDot(d, d); l > best {
+				simplex[0], simplex[1], best = extremes[i], extremes[j], l
+			}
+		}
+	}
+	if best <= eps*eps {
+		return simplex, errors.New("points are all the same, they have no convex hull")
+	}
+
+	// The point furthest from their line
+	a, b := coords[simplex[0]], coords[simplex[1]]
+	line := hullSub(b, a)
+	length := math.Sqrt(hullDot(line, line))
+	midpoint := [3]float64{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2, (a[2] + b[2]) / 2}
+	simplex[2], best = hullCorner(coords, all, eps, midpoint, func(p [3]float64) float64 {
+		c := hullCross(line, hullSub(p, a))
+		return math.Sqrt(hullDot(c, c)) / length
+	})
+	if best <= eps {
+		return simplex, errors.New("points are collinear, they have no convex hull")
+	}
+
+	// And the point furthest from their plane
+	normal := hullCross(line, hullSub(coords[simplex[2]], a))
+	area := math.Sqrt(hullDot(normal, normal))
+	simplex[3], best = hullCorner(coords, all, eps, hullCentroid(a, b, coords[simplex[2]]), func(p [3]float64) float64 {
+		return math.Abs(hullDot(normal, hullSub(p, a))) / area
+	})
+	if best <= eps {
+		return simplex, errors.New("points are coplanar, they have no convex hull")
+	}
+
+	// The fourth point must be behind the first face
+	if hullDot(normal, hullSub(coords[simplex[3]], a)) > 0 {
+		simplex[1], simplex[2] = simplex[2], simplex[1]
+	}
+
+	return simplex, nil
+}
+
+// hullCorner returns the point of candidates with the highest distance, and that distance. Points on an edge or side
+// of the hull that's parallel to what the distance is measured from are equally far but for rounding, and making one
+// that isn't a corner into a vertex would leave degenerate faces, so of the points within eps of the furthest, the
+// one furthest from center is taken, which is a corner of them.
+func hullCorner(coords [][3]float64, candidates []int, eps float64, center [3]float64, distance func(p [3]float64) float64) (int, float64) {
+	best := math.Inf(-1)
+	for _, p := range candidates {
+		best = math.Max(best, distance(coords[p]))
+	}
+
+	corner, spread := -1, -1.0
+	for _, p := range candidates {
+		if distance(coords[p]) < best-eps {
+			continue
+		}
+		if d := hullSub(coords[p], center); hullDot(d, d) > spread {
+			corner, spread = p, hullDot(d, d)
+		}
+	}
+	return corner, best
+}
+
+func hullCentroid(a, b, c [3]float64) [3]float64 {
+	return [3]float64{(a[0] + b[0] + c[0]) / 3, (a[1] + b[1] + c[1]) / 3, (a[2] + b[2] + c[2]) / 3}
+}
+
+func hullSub(a, b [3]float64) [3]float64 {
+	return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func hullDot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func hullCross(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+// Support returns the vertex of the hull furthest in the given direction, which makes ConvexHull3D a SupportMapper.
+// This checks every vertex, so it's linear in the size of the hull.
+func (h *ConvexHull3D) Support(direction Vec3) Vec3 {
+	best := h.Points[h.Vertices[0]]
+	bestDot := best.Dot(direction)
+	for _, v := range h.Vertices[1:] {
+		if dot := h.Points[v].Dot(direction); dot > bestDot {
+			best, bestDot = h.Points[v], dot
+		}
+	}
+
+	return best
+}
+
+// FaceNormal returns the outward facing unit normal of face i.
+func (h *ConvexHull3D) FaceNormal(i int) Vec3 {
+	normal, _ := h.facePlane(i)
+	return Vec3{float64(normal[0]), float64(normal[1]), float64(normal[2])}
+}
+
+// facePlane returns the unit normal and offset of the plane of face i, in 64-bit precision like the planes that
+// built the hull, since a face may be a thin sliver whose normal float32 can't compute accurately.
+func (h *ConvexHull3D) facePlane(i int) (normal [3]float64, offset float64) {
+	var coords [3][3]float64
+	for j, v := range h.Faces[i] {
+		p := h.Points[v]
+		coords[j] = [3]float64{float64(p[0]), float64(p[1]), float64(p[2])}
+	}
+
+	n := hullCross(hullSub(coords[1], coords[0]), hullSub(coords[2], coords[0]))
+	l := math.Sqrt(hullDot(n, n))
+	normal = [3]float64{n[0] / l, n[1] / l, n[2] / l}
+	return normal, hullDot(normal, coords[0])
+}
+
+// Contains returns whether p is inside the hull, or less than threshold outside of it.
+func (h *ConvexHull3D) Contains(p Vec3, threshold float64) bool {
+	coords := [3]float64{float64(p[0]), float64(p[1]), float64(p[2])}
+	for i := range h.Faces {
+		if normal, offset := h.facePlane(i); hullDot(normal, coords)-offset > float64(threshold) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Volume returns the volume enclosed by the hull.
+func (h *ConvexHull3D) Volume() float64 {
+	// The sum of the signed volumes of the tetrahedra between each face and the origin
+	var volume float64
+	for _, f := range h.Faces {
+		a, b, c := h.Points[f[0]], h.Points[f[1]], h.Points[f[2]]
+		volume += a.Dot(b.Cross(c))
+	}
+
+	return volume / 6
+}
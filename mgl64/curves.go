@@ -0,0 +1,527 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"sort"
+)
+
+// BezierArcLength2D is a lookup table mapping distance along a Bezier curve
+// back to the curve's t parameter. Bezier curves are not parameterized by arc length, so
+// stepping t uniformly (as MakeBezierCurve2D does) bunches points up wherever the curve
+// moves slowly. The table allows sampling the curve at constant speed instead.
+//
+// The table is built once by sampling the curve, after which lookups are a binary search.
+type BezierArcLength2D struct {
+	CPoints []Vec2
+
+	params  []float64
+	lengths []float64
+}
+
+// NewBezierArcLength2D builds an arc length table for the curve with control points cPoints.
+// The curve is approximated by numSamples straight segments, more samples means a more accurate table.
+// If numSamples is less than 1, a single segment is used.
+func NewBezierArcLength2D(cPoints []Vec2, numSamples int) *BezierArcLength2D {
+	if numSamples < 1 {
+		numSamples = 1
+	}
+
+	a := &BezierArcLength2D{
+		CPoints: cPoints,
+		params:  make([]float64, numSamples+1),
+		lengths: make([]float64, numSamples+1),
+	}
+
+	prev := cPoints[0]
+	for i := 1; i <= numSamples; i++ {
+		t := Clamp(float64(i)/float64(numSamples), 0, 1)
+		curr := BezierCurve2D(t, cPoints)
+
+		a.params[i] = t
+		a.lengths[i] = a.lengths[i-1] + curr.Sub(prev).Len()
+		prev = curr
+	}
+
+	return a
+}
+
+// Len returns the total (approximate) length of the curve.
+func (a *BezierArcLength2D) Len() float64 {
+	return a.lengths[len(a.lengths)-1]
+}
+
+// T returns the curve parameter at distance s along the curve. Values of s outside [0,Len()]
+// are clamped.
+func (a *BezierArcLength2D) T(s float64) float64 {
+	return arcLengthToT(s, a.params, a.lengths)
+}
+
+// PointAt returns the point at distance s along the curve.
+func (a *BezierArcLength2D) PointAt(s float64) Vec2 {
+	return BezierCurve2D(a.T(s), a.CPoints)
+}
+
+// MakeCurve is the constant speed equivalent of MakeBezierCurve2D. The numPoints returned points
+// are spaced evenly along the curve, rather than evenly in t.
+func (a *BezierArcLength2D) MakeCurve(numPoints int) (line []Vec2) {
+	line = make([]Vec2, numPoints)
+	if numPoints == 0 {
+		return
+	} else if numPoints == 1 {
+		line[0] = a.CPoints[0]
+		return
+	}
+
+	step := a.Len() / float64(numPoints-1)
+	line[0] = a.CPoints[0]
+	for i := 1; i < numPoints-1; i++ {
+		line[i] = a.PointAt(float64(i) * step)
+	}
+	line[numPoints-1] = a.CPoints[len(a.CPoints)-1]
+
+	return
+}
+
+// Same as the 2D version, except the curve is in 3D space
+type BezierArcLength3D struct {
+	CPoints []Vec3
+
+	params  []float64
+	lengths []float64
+}
+
+// Same as the 2D version, except the curve is in 3D space
+func NewBezierArcLength3D(cPoints []Vec3, numSamples int) *BezierArcLength3D {
+	if numSamples < 1 {
+		numSamples = 1
+	}
+
+	a := &BezierArcLength3D{
+		CPoints: cPoints,
+		params:  make([]float64, numSamples+1),
+		lengths: make([]float64, numSamples+1),
+	}
+
+	prev := cPoints[0]
+	for i := 1; i <= numSamples; i++ {
+		t := Clamp(float64(i)/float64(numSamples), 0, 1)
+		curr := BezierCurve3D(t, cPoints)
+
+		a.params[i] = t
+		a.lengths[i] = a.lengths[i-1] + curr.Sub(prev).Len()
+		prev = curr
+	}
+
+	return a
+}
+
+// Len returns the total (approximate) length of the curve.
+func (a *BezierArcLength3D) Len() float64 {
+	return a.lengths[len(a.lengths)-1]
+}
+
+// T returns the curve parameter at distance s along the curve. Values of s outside [0,Len()]
+// are clamped.
+func (a *BezierArcLength3D) T(s float64) float64 {
+	return arcLengthToT(s, a.params, a.lengths)
+}
+
+// PointAt returns the point at distance s along the curve.
+func (a *BezierArcLength3D) PointAt(s float64) Vec3 {
+	return BezierCurve3D(a.T(s), a.CPoints)
+}
+
+// MakeCurve is the constant speed equivalent of MakeBezierCurve3D. The numPoints returned points
+// are spaced evenly along the curve, rather than evenly in t.
+func (a *BezierArcLength3D) MakeCurve(numPoints int) (line []Vec3) {
+	line = make([]Vec3, numPoints)
+	if numPoints == 0 {
+		return
+	} else if numPoints == 1 {
+		line[0] = a.CPoints[0]
+		return
+	}
+
+	step := a.Len() / float64(numPoints-1)
+	line[0] = a.CPoints[0]
+	for i := 1; i < numPoints-1; i++ {
+		line[i] = a.PointAt(float64(i) * step)
+	}
+	line[numPoints-1] = a.CPoints[len(a.CPoints)-1]
+
+	return
+}
+
+// arcLengthToT inverts a cumulative length table by binary search, linearly interpolating
+// the parameter between the two bracketing samples.
+func arcLengthToT(s float64, params, lengths []float64) float64 {
+	total := lengths[len(lengths)-1]
+	if s <= 0 || total == 0 {
+		return 0
+	} else if s >= total {
+		return 1
+	}
+
+	i := sort.Search(len(lengths), func(i int) bool { return lengths[i] >= s })
+	segLen := lengths[i] - lengths[i-1]
+	if segLen == 0 {
+		return params[i]
+	}
+
+	return Clamp(params[i-1]+(params[i]-params[i-1])*(s-lengths[i-1])/segLen, 0, 1)
+}
+
+// BezierCurveLength2D approximates the length of the Bezier curve by summing numSamples
+// straight segments along it.
+func BezierCurveLength2D(numSamples int, cPoints []Vec2) float64 {
+	return NewBezierArcLength2D(cPoints, numSamples).Len()
+}
+
+// Same as the 2D version, except the curve is in 3D space
+func BezierCurveLength3D(numSamples int, cPoints []Vec3) float64 {
+	return NewBezierArcLength3D(cPoints, numSamples).Len()
+}
+
+// BezierDerivative2D returns the first derivative (the tangent, not normalized) of an n-control point
+// Bezier curve at t. Like BezierCurve2D, t must be in the range [0.0,1.0] or this will panic.
+func BezierDerivative2D(t float64, cPoints []Vec2) Vec2 {
+	if len(cPoints) < 2 {
+		return Vec2{}
+	}
+
+	return BezierCurve2D(t, bezierHodograph2D(cPoints))
+}
+
+// Same as the 2D version, except the curve is in 3D space
+func BezierDerivative3D(t float64, cPoints []Vec3) Vec3 {
+	if len(cPoints) < 2 {
+		return Vec3{}
+	}
+
+	return BezierCurve3D(t, bezierHodograph3D(cPoints))
+}
+
+// bezierHodograph2D returns the control points of the derivative of a Bezier curve,
+// which is itself a Bezier curve of one lower degree.
+func bezierHodograph2D(cPoints []Vec2) []Vec2 {
+	n := len(cPoints) - 1
+	hodograph := make([]Vec2, n)
+	for i := range hodograph {
+		hodograph[i] = cPoints[i+1].Sub(cPoints[i]).Mul(float64(n))
+	}
+
+	return hodograph
+}
+
+func bezierHodograph3D(cPoints []Vec3) []Vec3 {
+	n := len(cPoints) - 1
+	hodograph := make([]Vec3, n)
+	for i := range hodograph {
+		hodograph[i] = cPoints[i+1].Sub(cPoints[i]).Mul(float64(n))
+	}
+
+	return hodograph
+}
+
+// BezierSplit2D splits a Bezier curve at t using de Casteljau's algorithm. The two returned
+// curves have the same degree as the input, the first covers [0,t] of the original curve and the
+// second covers [t,1]. Both are reparameterized to [0,1].
+func BezierSplit2D(t float64, cPoints []Vec2) (left, right []Vec2) {
+	if t < 0.0 || t > 1.0 {
+		panic("Can't split bezier curve with t out of range [0.0,1.0]")
+	}
+
+	n := len(cPoints)
+	left, right = make([]Vec2, n), make([]Vec2, n)
+	work := append([]Vec2(nil), cPoints...)
+
+	for level := 0; level < n; level++ {
+		left[level] = work[0]
+		right[n-1-level] = work[n-1-level]
+		for i := 0; i < n-1-level; i++ {
+			work[i] = work[i].Add(work[i+1].Sub(work[i]).Mul(t))
+		}
+	}
+
+	return left, right
+}
+
+// Same as the 2D version, except the curve is in 3D space
+func BezierSplit3D(t float64, cPoints []Vec3) (left, right []Vec3) {
+	if t < 0.0 || t > 1.0 {
+		panic("Can't split bezier curve with t out of range [0.0,1.0]")
+	}
+
+	n := len(cPoints)
+	left, right = make([]Vec3, n), make([]Vec3, n)
+	work := append([]Vec3(nil), cPoints...)
+
+	for level := 0; level < n; level++ {
+		left[level] = work[0]
+		right[n-1-level] = work[n-1-level]
+		for i := 0; i < n-1-level; i++ {
+			work[i] = work[i].Add(work[i+1].Sub(work[i]).Mul(t))
+		}
+	}
+
+	return left, right
+}
+
+// BezierBounds2D returns the tight axis aligned bounding box of the curve. Unlike the bounds of the
+// control points, this only includes the curve itself. The extrema are found where the derivative of
+// each component is zero.
+func BezierBounds2D(cPoints []Vec2) (min, max Vec2) {
+	min, max = cPoints[0], cPoints[0]
+	extend := func(p Vec2) {
+		for i := range p {
+			SetMin(&min[i], &p[i])
+			SetMax(&max[i], &p[i])
+		}
+	}
+	extend(cPoints[len(cPoints)-1])
+
+	if len(cPoints) < 3 {
+		return min, max
+	}
+
+	hodograph := bezierHodograph2D(cPoints)
+	for axis := range min {
+		for _, t := range bezierComponentRoots(len(cPoints), func(t float64) float64 { return BezierCurve2D(t, hodograph)[axis] }) {
+			extend(BezierCurve2D(t, cPoints))
+		}
+	}
+
+	return min, max
+}
+
+// Same as the 2D version, except the curve is in 3D space
+func BezierBounds3D(cPoints []Vec3) (min, max Vec3) {
+	min, max = cPoints[0], cPoints[0]
+	extend := func(p Vec3) {
+		for i := range p {
+			SetMin(&min[i], &p[i])
+			SetMax(&max[i], &p[i])
+		}
+	}
+	extend(cPoints[len(cPoints)-1])
+
+	if len(cPoints) < 3 {
+		return min, max
+	}
+
+	hodograph := bezierHodograph3D(cPoints)
+	for axis := range min {
+		for _, t := range bezierComponentRoots(len(cPoints), func(t float64) float64 { return BezierCurve3D(t, hodograph)[axis] }) {
+			extend(BezierCurve3D(t, cPoints))
+		}
+	}
+
+	return min, max
+}
+
+// bezierComponentRoots finds the roots of f in [0,1], where f is one component of the derivative
+// of a curve with numCPoints control points. A polynomial of degree d changes sign at most d times,
+// so the interval is scanned finely enough to bracket each root, which is then refined by bisection.
+func bezierComponentRoots(numCPoints int, f func(float64) float64) (roots []float64) {
+	steps := 16 * numCPoints
+	prevT, prev := float64(0), f(0)
+	for i := 1; i <= steps; i++ {
+		t := Clamp(float64(i)/float64(steps), 0, 1)
+		curr := f(t)
+		if curr == 0 {
+			roots = append(roots, t)
+		} else if prev != 0 && (prev < 0) != (curr < 0) {
+			lo, hi, fLo := prevT, t, prev
+			for iter := 0; iter < 32; iter++ {
+				mid := (lo + hi) / 2
+				if fMid := f(mid); (fMid < 0) == (fLo < 0) {
+					lo, fLo = mid, fMid
+				} else {
+					hi = mid
+				}
+			}
+			roots = append(roots, (lo+hi)/2)
+		}
+		prevT, prev = t, curr
+	}
+
+	return roots
+}
+
+// BezierClosestPoint2D finds the point on the curve closest to p, returning both the curve parameter t
+// and the point itself. The curve is sampled coarsely to find a starting guess, which is then refined
+// with Newton's method on the squared distance.
+func BezierClosestPoint2D(p Vec2, cPoints []Vec2) (t float64, closest Vec2) {
+	hodograph := bezierHodograph2D(cPoints)
+	var second []Vec2
+	if len(hodograph) > 1 {
+		second = bezierHodograph2D(hodograph)
+	}
+
+	steps := 8 * len(cPoints)
+	bestDist := float64(math.Inf(1))
+	for i := 0; i <= steps; i++ {
+		ti := Clamp(float64(i)/float64(steps), 0, 1)
+		if d := BezierCurve2D(ti, cPoints).Sub(p); d.Dot(d) < bestDist {
+			t, bestDist = ti, d.Dot(d)
+		}
+	}
+
+	for iter := 0; iter < 8 && len(hodograph) > 0; iter++ {
+		diff := BezierCurve2D(t, cPoints).Sub(p)
+		d1 := BezierCurve2D(t, hodograph)
+		var d2 Vec2
+		if second != nil {
+			d2 = BezierCurve2D(t, second)
+		}
+
+		denom := d1.Dot(d1) + diff.Dot(d2)
+		if denom == 0 {
+			break
+		}
+		t = Clamp(t-diff.Dot(d1)/denom, 0, 1)
+	}
+
+	return t, BezierCurve2D(t, cPoints)
+}
+
+// Same as the 2D version, except the curve is in 3D space
+func BezierClosestPoint3D(p Vec3, cPoints []Vec3) (t float64, closest Vec3) {
+	hodograph := bezierHodograph3D(cPoints)
+	var second []Vec3
+	if len(hodograph) > 1 {
+		second = bezierHodograph3D(hodograph)
+	}
+
+	steps := 8 * len(cPoints)
+	bestDist := float64(math.Inf(1))
+	for i := 0; i <= steps; i++ {
+		ti := Clamp(float64(i)/float64(steps), 0, 1)
+		if d := BezierCurve3D(ti, cPoints).Sub(p); d.Dot(d) < bestDist {
+			t, bestDist = ti, d.Dot(d)
+		}
+	}
+
+	for iter := 0; iter < 8 && len(hodograph) > 0; iter++ {
+		diff := BezierCurve3D(t, cPoints).Sub(p)
+		d1 := BezierCurve3D(t, hodograph)
+		var d2 Vec3
+		if second != nil {
+			d2 = BezierCurve3D(t, second)
+		}
+
+		denom := d1.Dot(d1) + diff.Dot(d2)
+		if denom == 0 {
+			break
+		}
+		t = Clamp(t-diff.Dot(d1)/denom, 0, 1)
+	}
+
+	return t, BezierCurve3D(t, cPoints)
+}
+
+// A CurveFrame is an orthonormal coordinate frame attached to a point on a curve,
+// used to orient geometry that is extruded or moved along the curve.
+type CurveFrame struct {
+	Position Vec3
+	Tangent  Vec3
+	Normal   Vec3
+	Binormal Vec3
+}
+
+// Mat4 returns the homogeneous transform from the frame's local space to the curve's space.
+// The local X axis maps to the Normal, Y to the Binormal, and Z to the Tangent, so a 2D profile drawn
+// in the XY plane is swept along the curve.
+func (f CurveFrame) Mat4() Mat4 {
+	return Mat4FromCols(f.Normal.Vec4(0), f.Binormal.Vec4(0), f.Tangent.Vec4(0), f.Position.Vec4(1))
+}
+
+// BezierFrenetFrame3D returns the Frenet-Serret frame of the curve at t. The normal points towards
+// the center of curvature. The Frenet frame is undefined where the curve is straight, and flips
+// at inflection points; prefer MakeBezierRotationMinimizingFrames3D for extrusion.
+func BezierFrenetFrame3D(t float64, cPoints []Vec3) CurveFrame {
+	hodograph := bezierHodograph3D(cPoints)
+	d1 := BezierCurve3D(t, hodograph)
+	var d2 Vec3
+	if len(hodograph) > 1 {
+		d2 = BezierCurve3D(t, bezierHodograph3D(hodograph))
+	}
+
+	tangent := d1.Normalize()
+	binormal := d1.Cross(d2).Normalize()
+
+	return CurveFrame{
+		Position: BezierCurve3D(t, cPoints),
+		Tangent:  tangent,
+		Normal:   binormal.Cross(tangent),
+		Binormal: binormal,
+	}
+}
+
+// MakeBezierRotationMinimizingFrames3D samples numPoints frames evenly in t along the curve, like MakeBezierCurve3D,
+// using the double reflection method (Wang et al. 2008). Rotation minimizing frames twist as little as possible
+// around the tangent, which avoids the sudden flips of Frenet frames.
+//
+// The first frame's normal is initialNormal made perpendicular to the starting tangent. If initialNormal
+// is parallel to the tangent an arbitrary perpendicular normal is chosen.
+func MakeBezierRotationMinimizingFrames3D(numPoints int, cPoints []Vec3, initialNormal Vec3) []CurveFrame {
+	frames := make([]CurveFrame, numPoints)
+	if numPoints == 0 {
+		return frames
+	}
+
+	hodograph := bezierHodograph3D(cPoints)
+	tangentAt := func(t float64) Vec3 {
+		return BezierCurve3D(t, hodograph).Normalize()
+	}
+
+	tangent := tangentAt(0)
+	normal := initialNormal.Sub(tangent.Mul(initialNormal.Dot(tangent)))
+	if normal.Len() < 1e-6 {
+		normal = anyPerpendicular(tangent)
+	}
+	normal = normal.Normalize()
+	frames[0] = CurveFrame{cPoints[0], tangent, normal, tangent.Cross(normal)}
+
+	for i := 1; i < numPoints; i++ {
+		t := Clamp(float64(i)/float64(numPoints-1), 0, 1)
+		prev := frames[i-1]
+		pos := BezierCurve3D(t, cPoints)
+		tangent := tangentAt(t)
+
+		// Reflect the previous frame across the bisector plane of the two points...
+		v1 := pos.Sub(prev.Position)
+		c1 := v1.Dot(v1)
+		if c1 == 0 {
+			frames[i] = CurveFrame{pos, tangent, prev.Normal, prev.Binormal}
+			continue
+		}
+		rNormal := prev.Normal.Sub(v1.Mul(2 / c1 * v1.Dot(prev.Normal)))
+		rTangent := prev.Tangent.Sub(v1.Mul(2 / c1 * v1.Dot(prev.Tangent)))
+
+		// ...then reflect again to line the reflected tangent up with the real one
+		v2 := tangent.Sub(rTangent)
+		normal := rNormal
+		if c2 := v2.Dot(v2); c2 != 0 {
+			normal = rNormal.Sub(v2.Mul(2 / c2 * v2.Dot(rNormal)))
+		}
+		normal = normal.Normalize()
+
+		frames[i] = CurveFrame{pos, tangent, normal, tangent.Cross(normal)}
+	}
+
+	return frames
+}
+
+// anyPerpendicular returns some unit vector perpendicular to the unit vector v.
+func anyPerpendicular(v Vec3) Vec3 {
+	if Abs(v[0]) < 0.9 {
+		return Vec3{1, 0, 0}.Cross(v).Normalize()
+	}
+
+	return Vec3{0, 1, 0}.Cross(v).Normalize()
+}
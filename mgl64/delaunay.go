@@ -0,0 +1,693 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// NewDelaunay and the sweep-hull code it uses (the hullPrev, hullNext, hullTri and hullHash arrays, pseudoAngle, link
+// and legalize) are ported from Delaunator, https://github.com/mapbox/delaunator, which carries this notice:
+//
+// ISC License
+//
+// Copyright (c) 2021, Mapbox
+//
+// Permission to use, copy, modify, and/or distribute this software for any purpose
+// with or without fee is hereby granted, provided that the above copyright notice
+// and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND ISC DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+// FITNESS. IN NO EVENT SHALL ISC BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+// OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+// TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+// THIS SOFTWARE.
+
+package mgl64
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// A Delaunay triangulation of a set of points. No point lies inside the circumcircle of any triangle
+// (except where constrained edges force it), which maximizes the minimum angle of the triangles and
+// makes it a good mesh for terrain and interpolation.
+//
+// The triangulation is stored as a half-edge structure. Triangle t is made of the three half-edges 3t, 3t+1 and 3t+2;
+// half-edge e starts at Points[Triangles[e]] and ends at the start of the next half-edge of the same triangle. Triangles are
+// wound counter-clockwise. Halfedges[e] is the opposite half-edge in the neighbouring triangle, or -1 if e is on the convex hull.
+//
+// Duplicate points are not part of any triangle.
+type Delaunay struct {
+	Points    []Vec2
+	Triangles []uint32
+	Halfedges []int32
+	// Hull contains the indices of the points on the convex hull, in counter-clockwise order.
+	Hull []uint32
+
+	coords      []float64
+	inedges     []int32
+	constrained []bool
+
+	// Only used while building the triangulation
+	hullPrev, hullNext, hullTri, hullHash []int32
+	hullStart                             int32
+	stack                                 []int32
+}
+
+// NextHalfedge returns the half-edge following e in the same triangle.
+func NextHalfedge(e int) int {
+	if e%3 == 2 {
+		return e - 2
+	}
+	return e + 1
+}
+
+// PrevHalfedge returns the half-edge preceding e in the same triangle.
+func PrevHalfedge(e int) int {
+	if e%3 == 0 {
+		return e + 2
+	}
+	return e - 1
+}
+
+// NewDelaunay computes the Delaunay triangulation of points using Delaunator's sweep-hull algorithm, which runs in
+// O(n log n) time. The calculations are done in 64-bit precision. An error is returned if there are fewer
+// than three unique points, or if all the points are collinear.
+//
+// The points slice is kept (not copied) as the Points field.
+func NewDelaunay(points []Vec2) (*Delaunay, error) {
+	n := len(points)
+	if n < 3 {
+		return nil, errors.New("at least three points are needed for a triangulation")
+	}
+
+	d := &Delaunay{Points: points, coords: make([]float64, 2*n)}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i, p := range points {
+		x, y := float64(p[0]), float64(p[1])
+		d.coords[2*i], d.coords[2*i+1] = x, y
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	cx, cy := (minX+maxX)/2, (minY+maxY)/2
+
+	// Seed the hull with the triangle closest to the center that has the smallest circumcircle
+	i0, i1, i2 := -1, -1, -1
+	minDist := math.Inf(1)
+	for i := 0; i < n; i++ {
+		if dist := d.dist(i, cx, cy); dist < minDist {
+			i0, minDist = i, dist
+		}
+	}
+
+	minDist = math.Inf(1)
+	for i := 0; i < n; i++ {
+		if dist := d.dist(i, d.coords[2*i0], d.coords[2*i0+1]); i != i0 && dist > 0 && dist < minDist {
+			i1, minDist = i, dist
+		}
+	}
+
+	minRadius := math.Inf(1)
+	for i := 0; i < n; i++ {
+		if i == i0 || i == i1 {
+			continue
+		}
+		if r := d.circumradius(i0, i1, i); r < minRadius {
+			i2, minRadius = i, r
+		}
+	}
+
+	if i1 < 0 || i2 < 0 || math.IsInf(minRadius, 1) {
+		return nil, errors.New("points are collinear or duplicates, they can't be triangulated")
+	}
+
+	if d.orient(i0, i1, i2) < 0 {
+		i1, i2 = i2, i1
+	}
+	cx, cy = d.circumcenter(i0, i1, i2)
+
+	// Sweep outwards from the seed triangle, adding points in order of distance from its circumcenter
+	ids := make([]int, n)
+	dists := make([]float64, n)
+	for i := range ids {
+		ids[i], dists[i] = i, d.dist(i, cx, cy)
+	}
+	sort.Slice(ids, func(a, b int) bool { return dists[ids[a]] < dists[ids[b]] })
+
+	hashSize := int(math.Ceil(math.Sqrt(float64(n))))
+	d.hullPrev, d.hullNext, d.hullTri = make([]int32, n), make([]int32, n), make([]int32, n)
+	d.hullHash = make([]int32, hashSize)
+	for i := range d.hullHash {
+		d.hullHash[i] = -1
+	}
+	hashKey := func(x, y float64) int {
+		return int(math.Floor(pseudoAngle(x-cx, y-cy)*float64(hashSize))) % hashSize
+	}
+
+	maxTriangles := 2*n - 5
+	d.Triangles = make([]uint32, 0, maxTriangles*3)
+	d.Halfedges = make([]int32, 0, maxTriangles*3)
+
+	d.hullStart = int32(i0)
+	d.hullNext[i0], d.hullPrev[i2] = int32(i1), int32(i1)
+	d.hullNext[i1], d.hullPrev[i0] = int32(i2), int32(i2)
+	d.hullNext[i2], d.hullPrev[i1] = int32(i0), int32(i0)
+	d.hullTri[i0], d.hullTri[i1], d.hullTri[i2] = 0, 1, 2
+	d.hullHash[hashKey(d.coords[2*i0], d.coords[2*i0+1])] = int32(i0)
+	d.hullHash[hashKey(d.coords[2*i1], d.coords[2*i1+1])] = int32(i1)
+	d.hullHash[hashKey(d.coords[2*i2], d.coords[2*i2+1])] = int32(i2)
+
+	d.addTriangle(i0, i1, i2, -1, -1, -1)
+
+	xp, yp := math.NaN(), math.NaN()
+	for k, i := range ids {
+		x, y := d.coords[2*i], d.coords[2*i+1]
+
+		// Skip duplicates
+		if k > 0 && x == xp && y == yp {
+			continue
+		}
+		xp, yp = x, y
+
+		if i == i0 || i == i1 || i == i2 {
+			continue
+		}
+
+		// Find a hull edge that is visible from the point, starting from a hull point close to it in angle
+		start := int32(-1)
+		key := hashKey(x, y)
+		for j := 0; j < hashSize; j++ {
+			start = d.hullHash[(key+j)%hashSize]
+			if start != -1 && start != d.hullNext[start] {
+				break
+			}
+		}
+
+		start = d.hullPrev[start]
+		e := start
+		for !d.visible(i, int(e), int(d.hullNext[e])) {
+			e = d.hullNext[e]
+			if e == start {
+				e = -1
+				break
+			}
+		}
+		if e == -1 {
+			// The point is on the hull, or is a duplicate that wasn't adjacent to its twin in the sort order
+			continue
+		}
+
+		t := d.addTriangle(int(e), i, int(d.hullNext[e]), -1, -1, int(d.hullTri[e]))
+		d.hullTri[i] = d.legalize(t + 2)
+		d.hullTri[e] = int32(t)
+
+		// Walk forward along the hull, adding triangles for every edge the point can see
+		next := d.hullNext[e]
+		for q := d.hullNext[next]; d.visible(i, int(next), int(q)); q = d.hullNext[next] {
+			t = d.addTriangle(int(next), i, int(q), int(d.hullTri[i]), -1, int(d.hullTri[next]))
+			d.hullTri[i] = d.legalize(t + 2)
+			d.hullNext[next] = next // Mark as removed
+			next = q
+		}
+
+		// And backwards, if the starting edge might not have been the first visible one
+		if e == start {
+			for q := d.hullPrev[e]; d.visible(i, int(q), int(e)); q = d.hullPrev[e] {
+				t = d.addTriangle(int(q), i, int(e), -1, int(d.hullTri[e]), int(d.hullTri[q]))
+				d.legalize(t + 2)
+				d.hullTri[q] = int32(t)
+				d.hullNext[e] = e
+				e = q
+			}
+		}
+
+		d.hullStart = e
+		d.hullPrev[i], d.hullNext[e] = e, int32(i)
+		d.hullPrev[next], d.hullNext[i] = int32(i), next
+
+		d.hullHash[hashKey(x, y)] = int32(i)
+		d.hullHash[hashKey(d.coords[2*e], d.coords[2*e+1])] = e
+	}
+
+	for e := d.hullStart; ; {
+		d.Hull = append(d.Hull, uint32(e))
+		if e = d.hullNext[e]; e == d.hullStart {
+			break
+		}
+	}
+
+	// Index an incoming half-edge for each point, preferring the hull edge for points on the hull so
+	// that walking around a point starts at one side of the hull and ends at the other.
+	d.inedges = make([]int32, n)
+	for i := range d.inedges {
+		d.inedges[i] = -1
+	}
+	for e := range d.Triangles {
+		p := d.Triangles[NextHalfedge(e)]
+		if d.Halfedges[e] == -1 || d.inedges[p] == -1 {
+			d.inedges[p] = int32(e)
+		}
+	}
+	d.constrained = make([]bool, len(d.Triangles))
+
+	d.hullPrev, d.hullNext, d.hullTri, d.hullHash, d.stack = nil, nil, nil, nil, nil
+
+	return d, nil
+}
+
+// pseudoAngle is a cheap monotonic substitute for the angle of (dx, dy), in the range [0,1)
+func pseudoAngle(dx, dy float64) float64 {
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+
+	p := dx / (math.Abs(dx) + math.Abs(dy))
+	if dy >= 0 {
+		return (1 - p) / 4
+	}
+	return (3 + p) / 4
+}
+
+func (d *Delaunay) dist(i int, x, y float64) float64 {
+	dx, dy := d.coords[2*i]-x, d.coords[2*i+1]-y
+	return dx*dx + dy*dy
+}
+
+// orient is positive if the points i, j, k turn counter-clockwise
+func (d *Delaunay) orient(i, j, k int) float64 {
+	c := d.coords
+	return (c[2*j]-c[2*i])*(c[2*k+1]-c[2*i+1]) - (c[2*j+1]-c[2*i+1])*(c[2*k]-c[2*i])
+}
+
+// visible returns whether point p is strictly on the outside (right) of the counter-clockwise hull edge a->b
+func (d *Delaunay) visible(p, a, b int) bool {
+	return d.orient(a, b, p) < 0
+}
+
+// inCircle returns whether p is strictly inside the circumcircle of the counter-clockwise triangle ijk
+func (d *Delaunay) inCircle(i, j, k, p int) bool {
+	c := d.coords
+	px, py := c[2*p], c[2*p+1]
+	dx, dy := c[2*i]-px, c[2*i+1]-py
+	ex, ey := c[2*j]-px, c[2*j+1]-py
+	fx, fy := c[2*k]-px, c[2*k+1]-py
+
+	ap, bp, cp := dx*dx+dy*dy, ex*ex+ey*ey, fx*fx+fy*fy
+
+	return dx*(ey*cp-bp*fy)-dy*(ex*cp-bp*fx)+ap*(ex*fy-ey*fx) > 0
+}
+
+func (d *Delaunay) circumcircle(i, j, k int) (x, y, r2 float64) {
+	c := d.coords
+	dx, dy := c[2*j]-c[2*i], c[2*j+1]-c[2*i+1]
+	ex, ey := c[2*k]-c[2*i], c[2*k+1]-c[2*i+1]
+
+	bl, cl := dx*dx+dy*dy, ex*ex+ey*ey
+	den := .5 / (dx*ey - dy*ex)
+
+	ox, oy := (ey*bl-dy*cl)*den, (dx*cl-ex*bl)*den
+	return c[2*i] + ox, c[2*i+1] + oy, ox*ox + oy*oy
+}
+
+func (d *Delaunay) circumradius(i, j, k int) float64 {
+	_, _, r2 := d.circumcircle(i, j, k)
+	if math.IsNaN(r2) {
+		return math.Inf(1)
+	}
+	return r2
+}
+
+func (d *Delaunay) circumcenter(i, j, k int) (x, y float64) {
+	x, y, _ = d.circumcircle(i, j, k)
+	return x, y
+}
+
+func (d *Delaunay) link(a, b int) {
+	d.Halfedges[a] = int32(b)
+	if b != -1 {
+		d.Halfedges[b] = int32(a)
+	}
+}
+
+func (d *Delaunay) addTriangle(i0, i1, i2, a, b, c int) int {
+	t := len(d.Triangles)
+	d.Triangles = append(d.Triangles, uint32(i0), uint32(i1), uint32(i2))
+	d.Halfedges = append(d.Halfedges, -1, -1, -1)
+	d.link(t, a)
+	d.link(t+1, b)
+	d.link(t+2, c)
+
+	return t
+}
+
+// flip replaces the edge a (and its opposite half-edge) by the other diagonal of the quad formed by
+// its two triangles. Afterwards, PrevHalfedge(a) is the new diagonal, and the half-edges of the quad's
+// sides are moved around so that the triangles stay counter-clockwise.
+func (d *Delaunay) flip(a int) {
+	b := int(d.Halfedges[a])
+	al, ar := NextHalfedge(a), PrevHalfedge(a)
+	bl := PrevHalfedge(b)
+
+	p0, p1 := d.Triangles[ar], d.Triangles[bl]
+	pr, pl := d.Triangles[a], d.Triangles[al]
+
+	d.Triangles[a], d.Triangles[b] = p1, p0
+
+	hbl := int(d.Halfedges[bl])
+	if hbl == -1 && d.hullTri != nil {
+		// The edge on the other side of the hull moved, so its reference has to be fixed
+		e := d.hullStart
+		for {
+			if d.hullTri[e] == int32(bl) {
+				d.hullTri[e] = int32(a)
+				break
+			}
+			if e = d.hullPrev[e]; e == d.hullStart {
+				break
+			}
+		}
+	}
+
+	d.link(a, hbl)
+	d.link(b, int(d.Halfedges[ar]))
+	d.link(ar, bl)
+
+	if d.inedges != nil {
+		if d.inedges[pr] == int32(ar) {
+			d.inedges[pr] = int32(b)
+		}
+		if d.inedges[pl] == int32(bl) {
+			d.inedges[pl] = int32(a)
+		}
+		d.constrained[a], d.constrained[b] = d.constrained[bl], d.constrained[ar]
+		d.constrained[ar], d.constrained[bl] = false, false
+	}
+}
+
+// legalize restores the Delaunay condition around a newly added triangle by recursively flipping edges,
+// returning the half-edge that ends up in the position of the edge after a.
+func (d *Delaunay) legalize(a int) int32 {
+	d.stack = d.stack[:0]
+	var ar int
+	for {
+		b := int(d.Halfedges[a])
+		a0 := a - a%3
+		ar = a0 + (a+2)%3
+
+		if b == -1 {
+			if len(d.stack) == 0 {
+				break
+			}
+			a = int(d.stack[len(d.stack)-1])
+			d.stack = d.stack[:len(d.stack)-1]
+			continue
+		}
+
+		b0 := b - b%3
+		al := a0 + (a+1)%3
+		bl := b0 + (b+2)%3
+
+		if d.inCircle(int(d.Triangles[ar]), int(d.Triangles[a]), int(d.Triangles[al]), int(d.Triangles[bl])) {
+			d.flip(a)
+			d.stack = append(d.stack, int32(b0+(b+1)%3))
+		} else {
+			if len(d.stack) == 0 {
+				break
+			}
+			a = int(d.stack[len(d.stack)-1])
+			d.stack = d.stack[:len(d.stack)-1]
+		}
+	}
+
+	return int32(ar)
+}
+
+// NumTriangles returns the number of triangles in the triangulation.
+func (d *Delaunay) NumTriangles() int {
+	return len(d.Triangles) / 3
+}
+
+// Neighbors returns the indices of the points connected to point i by an edge, in clockwise order.
+// Duplicate points have no neighbors.
+func (d *Delaunay) Neighbors(i int) []uint32 {
+	var neighbors []uint32
+	d.aroundPoint(i, func(e int) {
+		neighbors = append(neighbors, d.Triangles[e])
+	})
+
+	// The last edge of a hull point is the outgoing hull edge, which isn't incoming to the point
+	if e0 := d.inedges[i]; e0 != -1 && d.Halfedges[e0] == -1 {
+		e := int(e0)
+		for {
+			out := NextHalfedge(e)
+			if d.Halfedges[out] == -1 {
+				neighbors = append(neighbors, d.Triangles[NextHalfedge(out)])
+				break
+			}
+			e = int(d.Halfedges[out])
+		}
+	}
+
+	return neighbors
+}
+
+// aroundPoint calls f with each half-edge ending at point i, going clockwise
+func (d *Delaunay) aroundPoint(i int, f func(e int)) {
+	e0 := int(d.inedges[i])
+	if e0 == -1 {
+		return
+	}
+
+	e := e0
+	for {
+		f(e)
+		out := NextHalfedge(e)
+		if d.Halfedges[out] == -1 {
+			return
+		}
+		if e = int(d.Halfedges[out]); e == e0 {
+			return
+		}
+	}
+}
+
+// findEdge returns the half-edge going from point a to point b, or -1 if there is no such edge.
+func (d *Delaunay) findEdge(a, b int) int {
+	found := -1
+	d.aroundPoint(a, func(e int) {
+		if out := NextHalfedge(e); found == -1 && int(d.Triangles[NextHalfedge(out)]) == b {
+			found = out
+		}
+	})
+
+	return found
+}
+
+// IsConstrained returns whether the half-edge e was fixed in place by ConstrainEdge.
+func (d *Delaunay) IsConstrained(e int) bool {
+	return d.constrained[e]
+}
+
+// ConstrainEdge forces the triangulation to contain an edge between points a and b, as in a
+// constrained Delaunay triangulation. This uses the edge flipping method of Sloan (1993), so triangles
+// not crossed by the edge are unaffected. Triangles next to constrained edges may no longer satisfy the Delaunay condition.
+//
+// If the edge passes exactly through other points, it is split into several constrained edges. An error is returned if
+// either point is not part of the triangulation (for instance because it's a duplicate), or the edge crosses another
+// constrained edge.
+func (d *Delaunay) ConstrainEdge(a, b int) error {
+	if a < 0 || b < 0 || a >= len(d.Points) || b >= len(d.Points) {
+		return fmt.Errorf("constrained edge (%d, %d) is out of range", a, b)
+	} else if a == b {
+		return fmt.Errorf("constrained edge (%d, %d) is degenerate", a, b)
+	} else if d.inedges[a] == -1 || d.inedges[b] == -1 {
+		return fmt.Errorf("constrained edge (%d, %d) uses a point that is not in the triangulation", a, b)
+	}
+
+	if e := d.findEdge(a, b); e != -1 {
+		d.markConstrained(e)
+		return nil
+	}
+
+	crossing, through, err := d.crossingEdges(a, b)
+	if err != nil {
+		return err
+	} else if through != -1 {
+		if err := d.ConstrainEdge(a, through); err != nil {
+			return err
+		}
+		return d.ConstrainEdge(through, b)
+	}
+
+	// Flip crossing edges until none are left. Edges whose quad is not convex can't be flipped yet,
+	// but become flippable once their neighbours have been flipped.
+	var created [][2]int
+	for iter := 0; len(crossing) > 0; iter++ {
+		if iter > 16*len(d.Triangles) {
+			return fmt.Errorf("could not insert constrained edge (%d, %d)", a, b)
+		}
+
+		u, v := crossing[0][0], crossing[0][1]
+		crossing = crossing[1:]
+
+		e := d.findEdge(u, v)
+		w, x := int(d.Triangles[PrevHalfedge(e)]), int(d.Triangles[PrevHalfedge(int(d.Halfedges[e]))])
+		if d.orient(w, u, x) <= 0 || d.orient(x, v, w) <= 0 {
+			crossing = append(crossing, [2]int{u, v})
+			continue
+		}
+
+		d.flip(e)
+		if w != a && w != b && x != a && x != b && (d.orient(a, b, w) > 0) != (d.orient(a, b, x) > 0) {
+			crossing = append(crossing, [2]int{w, x})
+		} else {
+			created = append(created, [2]int{w, x})
+		}
+	}
+
+	d.markConstrained(d.findEdge(a, b))
+
+	// Restore the Delaunay condition for the new edges, other than the constraint
+	for swapped := true; swapped; {
+		swapped = false
+		for i, edge := range created {
+			u, v := edge[0], edge[1]
+			e := d.findEdge(u, v)
+			if e == -1 || d.constrained[e] || d.Halfedges[e] == -1 {
+				continue
+			}
+
+			w, x := int(d.Triangles[PrevHalfedge(e)]), int(d.Triangles[PrevHalfedge(int(d.Halfedges[e]))])
+			if d.inCircle(u, v, w, x) {
+				d.flip(e)
+				created[i] = [2]int{w, x}
+				swapped = true
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *Delaunay) markConstrained(e int) {
+	d.constrained[e] = true
+	if opp := d.Halfedges[e]; opp != -1 {
+		d.constrained[opp] = true
+	}
+}
+
+// crossingEdges walks from point a towards point b, returning every edge that crosses the line between them.
+// If the line passes through another point, that point is returned instead.
+func (d *Delaunay) crossingEdges(a, b int) (crossing [][2]int, through int, err error) {
+	// Find the triangle around a that the line leaves through
+	edge := -1
+	d.aroundPoint(a, func(e int) {
+		if edge != -1 {
+			return
+		}
+		s, t := int(d.Triangles[e]), int(d.Triangles[NextHalfedge(NextHalfedge(e))])
+		if d.orient(a, t, b) == 0 && (d.coords[2*t]-d.coords[2*a])*(d.coords[2*b]-d.coords[2*a])+(d.coords[2*t+1]-d.coords[2*a+1])*(d.coords[2*b+1]-d.coords[2*a+1]) > 0 {
+			through = t
+			edge = -2
+		} else if d.orient(a, t, b) > 0 && d.orient(a, b, s) > 0 {
+			edge = PrevHalfedge(e)
+		}
+	})
+
+	if edge == -2 {
+		return nil, through, nil
+	} else if edge == -1 {
+		return nil, -1, fmt.Errorf("constrained edge (%d, %d) leaves the triangulation", a, b)
+	}
+
+	for {
+		if d.constrained[edge] {
+			return nil, -1, fmt.Errorf("constrained edge (%d, %d) crosses another constrained edge", a, b)
+		}
+		crossing = append(crossing, [2]int{int(d.Triangles[edge]), int(d.Triangles[NextHalfedge(edge)])})
+
+		opp := int(d.Halfedges[edge])
+		if opp == -1 {
+			return nil, -1, fmt.Errorf("constrained edge (%d, %d) leaves the triangulation", a, b)
+		}
+
+		y := int(d.Triangles[PrevHalfedge(opp)])
+		if y == b {
+			return crossing, -1, nil
+		}
+
+		side := d.orient(a, b, y)
+		if side == 0 {
+			return nil, y, nil
+		}
+
+		// The line leaves through whichever of the two other edges has its ends on opposite sides
+		if (d.orient(a, b, int(d.Triangles[opp])) > 0) != (side > 0) {
+			edge = PrevHalfedge(opp)
+		} else {
+			edge = NextHalfedge(opp)
+		}
+	}
+}
+
+// Voronoi computes the Voronoi diagram dual to the triangulation, with each cell clipped to the rectangle
+// between min and max. Cell i is the region closer to point i than to any other point, as a counter-clockwise
+// polygon. Duplicate points, and points whose cells lie entirely outside the rectangle, have empty cells.
+//
+// Constrained edges are ignored; the diagram is that of the unconstrained points. Constraining edges flips away edges
+// between Voronoi neighbours, so the unconstrained triangulation is recomputed for the diagram if there are any.
+func (d *Delaunay) Voronoi(min, max Vec2) [][]Vec2 {
+	cells := make([][]Vec2, len(d.Points))
+	bounds := []Vec2{min, {max[0], min[1]}, max, {min[0], max[1]}}
+
+	for _, c := range d.constrained {
+		if c {
+			// The points were triangulated once already, so they can't fail to be again
+			unconstrained, _ := NewDelaunay(d.Points)
+			d = unconstrained
+			break
+		}
+	}
+
+	for i := range cells {
+		if d.inedges[i] == -1 {
+			continue
+		}
+
+		cell := bounds
+		p := d.Points[i]
+		for _, j := range d.Neighbors(i) {
+			// Keep the half of the plane closer to p than to its neighbour q
+			q := d.Points[j]
+			normal, mid := q.Sub(p), p.Add(q).Mul(.5)
+			cell = clipPolygon(cell, normal, normal.Dot(mid))
+			if len(cell) == 0 {
+				break
+			}
+		}
+		cells[i] = cell
+	}
+
+	return cells
+}
+
+// clipPolygon clips a convex polygon to the half-plane normal.Dot(x) <= offset (Sutherland-Hodgman)
+func clipPolygon(polygon []Vec2, normal Vec2, offset float64) []Vec2 {
+	clipped := make([]Vec2, 0, len(polygon)+1)
+	for k := range polygon {
+		a, b := polygon[k], polygon[(k+1)%len(polygon)]
+		da, db := normal.Dot(a)-offset, normal.Dot(b)-offset
+
+		if da <= 0 {
+			clipped = append(clipped, a)
+		}
+		if (da < 0 && db > 0) || (da > 0 && db < 0) {
+			clipped = append(clipped, a.Add(b.Sub(a).Mul(da/(da-db))))
+		}
+	}
+
+	return clipped
+}
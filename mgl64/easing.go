@@ -0,0 +1,180 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+)
+
+// An EaseFunc maps the fraction of an animation's time that has passed, from 0 to 1, to the fraction of the way its
+// value should have gone, which is 0 at the start and 1 at the end but may overshoot in between.
+//
+// The Ease functions are Robert Penner's easing equations: the In versions start slowly, the Out versions end slowly,
+// and the InOut versions do both, as the In version scaled into the first half and the Out version into the second.
+type EaseFunc func(t float64) float64
+
+// easeOut returns the Out version of an In easing at t, which runs it backwards.
+func easeOut(in EaseFunc, t float64) float64 {
+	return 1 - in(1-t)
+}
+
+// easeInOut returns the InOut version of an In easing at t.
+func easeInOut(in EaseFunc, t float64) float64 {
+	if t < .5 {
+		return in(2*t) / 2
+	}
+	return 1 - in(2-2*t)/2
+}
+
+// EaseLinear doesn't ease, returning t.
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+// EaseInQuad eases with the square of t.
+func EaseInQuad(t float64) float64    { return t * t }
+func EaseOutQuad(t float64) float64   { return easeOut(EaseInQuad, t) }
+func EaseInOutQuad(t float64) float64 { return easeInOut(EaseInQuad, t) }
+
+// EaseInCubic eases with the cube of t.
+func EaseInCubic(t float64) float64    { return t * t * t }
+func EaseOutCubic(t float64) float64   { return easeOut(EaseInCubic, t) }
+func EaseInOutCubic(t float64) float64 { return easeInOut(EaseInCubic, t) }
+
+// EaseInQuart eases with t to the fourth power.
+func EaseInQuart(t float64) float64    { return t * t * t * t }
+func EaseOutQuart(t float64) float64   { return easeOut(EaseInQuart, t) }
+func EaseInOutQuart(t float64) float64 { return easeInOut(EaseInQuart, t) }
+
+// EaseInQuint eases with t to the fifth power.
+func EaseInQuint(t float64) float64    { return t * t * t * t * t }
+func EaseOutQuint(t float64) float64   { return easeOut(EaseInQuint, t) }
+func EaseInOutQuint(t float64) float64 { return easeInOut(EaseInQuint, t) }
+
+// EaseInSine follows a quarter of a cosine wave.
+func EaseInSine(t float64) float64 {
+	return 1 - float64(math.Cos(float64(t)*math.Pi/2))
+}
+func EaseOutSine(t float64) float64   { return easeOut(EaseInSine, t) }
+func EaseInOutSine(t float64) float64 { return easeInOut(EaseInSine, t) }
+
+// EaseInExpo doubles with each tenth of the time, from 2^-10 (snapped to 0 at the start) to 1.
+func EaseInExpo(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return float64(math.Exp2(10*float64(t) - 10))
+}
+func EaseOutExpo(t float64) float64   { return easeOut(EaseInExpo, t) }
+func EaseInOutExpo(t float64) float64 { return easeInOut(EaseInExpo, t) }
+
+// EaseInCirc follows a quarter of a circle.
+func EaseInCirc(t float64) float64 {
+	return 1 - float64(math.Sqrt(math.Max(0, 1-float64(t*t))))
+}
+func EaseOutCirc(t float64) float64   { return easeOut(EaseInCirc, t) }
+func EaseInOutCirc(t float64) float64 { return easeInOut(EaseInCirc, t) }
+
+// backOvershoot is Penner's constant for Back easing, which backs up by 10% before going forward.
+const backOvershoot = 1.70158
+
+// EaseInBack backs up by 10% before going forward.
+func EaseInBack(t float64) float64 {
+	return t * t * ((backOvershoot+1)*t - backOvershoot)
+}
+func EaseOutBack(t float64) float64 { return easeOut(EaseInBack, t) }
+
+// EaseInOutBack backs up and overshoots by 10%, with a larger constant than the others, as Penner's version does.
+func EaseInOutBack(t float64) float64 {
+	const s = backOvershoot * 1.525
+	in := func(t float64) float64 { return t * t * ((s+1)*t - s) }
+	return easeInOut(in, t)
+}
+
+// EaseInElastic oscillates with growing amplitude before snapping to the end, like a released spring.
+func EaseInElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return Clamp(t, 0, 1)
+	}
+	return -float64(math.Exp2(10*float64(t)-10) * math.Sin((10*float64(t)-10.75)*2*math.Pi/3))
+}
+func EaseOutElastic(t float64) float64 { return easeOut(EaseInElastic, t) }
+
+// EaseInOutElastic oscillates at both ends, with Penner's longer period, so each half has the same number of swings.
+func EaseInOutElastic(t float64) float64 {
+	in := func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return Clamp(t, 0, 1)
+		}
+		return -float64(math.Exp2(10*float64(t)-10) * math.Sin((10*float64(t)-11.125)*2*math.Pi/4.5))
+	}
+	return easeInOut(in, t)
+}
+
+// EaseOutBounce falls to the end and bounces three times, each a quarter as high as the last.
+func EaseOutBounce(t float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + .75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + .9375
+	}
+	t -= 2.625 / d
+	return n*t*t + .984375
+}
+func EaseInBounce(t float64) float64    { return easeOut(EaseOutBounce, t) }
+func EaseInOutBounce(t float64) float64 { return easeInOut(EaseInBounce, t) }
+
+// CubicBezierEase returns the easing of a cubic Bezier timing curve from (0,0) to (1,1) with control points (x1,y1)
+// and (x2,y2), like CSS's cubic-bezier() timing function. For instance, CSS's ease is CubicBezierEase(.25, .1, .25, 1).
+// The x coordinates, which are times, are clamped between 0 and 1.
+func CubicBezierEase(x1, y1, x2, y2 float64) EaseFunc {
+	p1, p2 := Vec2{Clamp(x1, 0, 1), y1}, Vec2{Clamp(x2, 0, 1), y2}
+	return func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return Clamp(t, 0, 1)
+		}
+		return bezierEase(p1, p2, t)
+	}
+}
+
+// easeAmount returns amount eased by ease, or amount if ease is nil.
+func easeAmount(amount float64, ease EaseFunc) float64 {
+	if ease == nil {
+		return amount
+	}
+	return ease(amount)
+}
+
+// Lerp interpolates from a to b, by amount eased with ease, which may be nil for linear interpolation.
+func Lerp(a, b, amount float64, ease EaseFunc) float64 {
+	return a + (b-a)*easeAmount(amount, ease)
+}
+
+// Vec2Lerp interpolates from v1 to v2 like Lerp.
+func Vec2Lerp(v1, v2 Vec2, amount float64, ease EaseFunc) Vec2 {
+	return v1.Add(v2.Sub(v1).Mul(easeAmount(amount, ease)))
+}
+
+// Vec3Lerp interpolates from v1 to v2 like Lerp.
+func Vec3Lerp(v1, v2 Vec3, amount float64, ease EaseFunc) Vec3 {
+	return v1.Add(v2.Sub(v1).Mul(easeAmount(amount, ease)))
+}
+
+// Vec4Lerp interpolates from v1 to v2 like Lerp.
+func Vec4Lerp(v1, v2 Vec4, amount float64, ease EaseFunc) Vec4 {
+	return v1.Add(v2.Sub(v1).Mul(easeAmount(amount, ease)))
+}
+
+// Mat4Lerp interpolates from m1 to m2 like Lerp, element by element. The result of interpolating between rotations
+// isn't a rotation; use QuatSlerp on their quaternions for that.
+func Mat4Lerp(m1, m2 Mat4, amount float64, ease EaseFunc) Mat4 {
+	return m1.Add(m2.Sub(m1).Mul(easeAmount(amount, ease)))
+}
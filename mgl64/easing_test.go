@@ -0,0 +1,120 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"testing"
+)
+
+var easings = map[string][3]EaseFunc{
+	"Quad":    {EaseInQuad, EaseOutQuad, EaseInOutQuad},
+	"Cubic":   {EaseInCubic, EaseOutCubic, EaseInOutCubic},
+	"Quart":   {EaseInQuart, EaseOutQuart, EaseInOutQuart},
+	"Quint":   {EaseInQuint, EaseOutQuint, EaseInOutQuint},
+	"Sine":    {EaseInSine, EaseOutSine, EaseInOutSine},
+	"Expo":    {EaseInExpo, EaseOutExpo, EaseInOutExpo},
+	"Circ":    {EaseInCirc, EaseOutCirc, EaseInOutCirc},
+	"Back":    {EaseInBack, EaseOutBack, EaseInOutBack},
+	"Elastic": {EaseInElastic, EaseOutElastic, EaseInOutElastic},
+	"Bounce":  {EaseInBounce, EaseOutBounce, EaseInOutBounce},
+}
+
+func TestEaseEndpoints(t *testing.T) {
+	for name, funcs := range easings {
+		for i, f := range funcs {
+			if v0, v1 := f(0), f(1); !FloatEqualThreshold(v0, 0, 1e-3) || !FloatEqualThreshold(v1, 1, 1e-5) {
+				t.Errorf("%s easing %d goes from %v to %v, expected 0 to 1", name, i, v0, v1)
+			}
+		}
+	}
+}
+
+func TestEaseSymmetry(t *testing.T) {
+	for name, funcs := range easings {
+		in, out, inOut := funcs[0], funcs[1], funcs[2]
+		for x := float64(0); x <= 1; x += 1. / 64 {
+			// Out runs In backwards, and InOut is symmetric about its middle
+			if a, b := out(x), 1-in(1-x); !FloatEqualThreshold(a, b, 1e-5) {
+				t.Errorf("EaseOut%s(%v) is %v, expected %v", name, x, a, b)
+			}
+			if a, b := inOut(x), 1-inOut(1-x); Abs(a-b) > 1e-5 {
+				t.Errorf("EaseInOut%s(%v) is %v, but %v from the end", name, x, a, b)
+			}
+		}
+		if v := inOut(.5); !FloatEqualThreshold(v, .5, 1e-5) {
+			t.Errorf("EaseInOut%s(.5) is %v, expected .5", name, v)
+		}
+	}
+}
+
+func TestEaseValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        EaseFunc
+		t        float64
+		expected float64
+	}{
+		{"EaseLinear", EaseLinear, .3, .3},
+		{"EaseInQuad", EaseInQuad, .5, .25},
+		{"EaseOutCubic", EaseOutCubic, .5, .875},
+		{"EaseInOutQuart", EaseInOutQuart, .25, .03125},
+		{"EaseInQuint", EaseInQuint, .5, .03125},
+		{"EaseInSine", EaseInSine, .5, .29289322},
+		{"EaseInExpo", EaseInExpo, .5, .03125},
+		{"EaseOutCirc", EaseOutCirc, .5, .8660254},
+		{"EaseInBack", EaseInBack, .5, -.0876975},
+		{"EaseInOutBack", EaseInOutBack, .25, -.0996818},
+		{"EaseOutElastic", EaseOutElastic, .1, 1.25},
+		{"EaseInOutElastic", EaseInOutElastic, .4, -.1174616},
+		{"EaseOutBounce", EaseOutBounce, .5, .765625},
+		{"EaseOutBounce", EaseOutBounce, .8, .94},
+	}
+
+	for _, c := range tests {
+		if v := c.f(c.t); !FloatEqualThreshold(v, c.expected, 1e-4) {
+			t.Errorf("%s(%v) is %v, expected %v", c.name, c.t, v, c.expected)
+		}
+	}
+}
+
+func TestCubicBezierEase(t *testing.T) {
+	ease := CubicBezierEase(.25, .1, .25, 1)
+	for x, expected := range map[float64]float64{-1: 0, 0: 0, .5: .8024034, 1: 1, 2: 1} {
+		if v := ease(x); !FloatEqualThreshold(v, expected, 1e-5) {
+			t.Errorf("CSS ease at %v is %v, expected %v", x, v, expected)
+		}
+	}
+
+	linear := CubicBezierEase(1./3, 1./3, 2./3, 2./3)
+	for x := float64(0); x <= 1; x += 1. / 16 {
+		if v := linear(x); !FloatEqualThreshold(v, x, 1e-4) {
+			t.Errorf("Straight Bezier easing at %v is %v", x, v)
+		}
+	}
+}
+
+func TestLerp(t *testing.T) {
+	if v := Lerp(2, 6, .25, nil); v != 3 {
+		t.Errorf("Linear Lerp is %v, expected 3", v)
+	}
+	if v := Lerp(2, 6, .5, EaseInQuad); v != 3 {
+		t.Errorf("Quadratic Lerp is %v, expected 3", v)
+	}
+
+	if v := Vec2Lerp(Vec2{0, 2}, Vec2{4, -2}, .5, EaseInQuad); !v.ApproxEqual(Vec2{1, 1}) {
+		t.Errorf("Vec2Lerp is %v", v)
+	}
+	if v := Vec3Lerp(Vec3{0, 0, 0}, Vec3{8, 4, 0}, .5, EaseOutCubic); !v.ApproxEqual(Vec3{7, 3.5, 0}) {
+		t.Errorf("Vec3Lerp is %v", v)
+	}
+	if v := Vec4Lerp(Vec4{1, 1, 1, 1}, Vec4{3, 5, 1, -1}, 1, EaseInOutElastic); !v.ApproxEqual(Vec4{3, 5, 1, -1}) {
+		t.Errorf("Vec4Lerp is %v", v)
+	}
+
+	m := Mat4Lerp(Ident4(), Scale3D(3, 3, 3), .5, nil)
+	if expected := Scale3D(2, 2, 2); !m.ApproxEqual(expected) {
+		t.Errorf("Mat4Lerp is %v, expected %v", m, expected)
+	}
+}
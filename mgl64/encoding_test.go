@@ -0,0 +1,172 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type encodable interface {
+	encoding.TextMarshaler
+	encoding.BinaryMarshaler
+	json.Marshaler
+	fmt.Formatter
+}
+
+// encodables returns a value of every vector, matrix and quaternion type, with elements that don't have short decimal
+// forms.
+func encodables() []encodable {
+	next := float64(0)
+	fill := func(elements []float64) {
+		for i := range elements {
+			next++
+			elements[i] = next/3 - 1e-5
+		}
+	}
+
+	var v2, v3, v4 = &Vec2{}, &Vec3{}, &Vec4{}
+	var m2, m2x3, m2x4, m3x2, m3 = &Mat2{}, &Mat2x3{}, &Mat2x4{}, &Mat3x2{}, &Mat3{}
+	var m3x4, m4x2, m4x3, m4 = &Mat3x4{}, &Mat4x2{}, &Mat4x3{}, &Mat4{}
+	for _, elements := range [][]float64{v2[:], v3[:], v4[:], m2[:], m2x3[:], m2x4[:], m3x2[:], m3[:], m3x4[:], m4x2[:], m4x3[:], m4[:]} {
+		fill(elements)
+	}
+	q := QuatRotate(1, Vec3{1, 2, 3}.Normalize())
+
+	return []encodable{*v2, *v3, *v4, *m2, *m2x3, *m2x4, *m3x2, *m3, *m3x4, *m4x2, *m4x3, *m4, q}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	encodings := []struct {
+		name      string
+		marshal   func(encodable) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"Text", func(v encodable) ([]byte, error) { return v.MarshalText() },
+			func(data []byte, p interface{}) error { return p.(encoding.TextUnmarshaler).UnmarshalText(data) }},
+		{"Binary", func(v encodable) ([]byte, error) { return v.MarshalBinary() },
+			func(data []byte, p interface{}) error { return p.(encoding.BinaryUnmarshaler).UnmarshalBinary(data) }},
+		{"JSON", func(v encodable) ([]byte, error) { return json.Marshal(v) }, json.Unmarshal},
+	}
+
+	for _, value := range encodables() {
+		for _, e := range encodings {
+			data, err := e.marshal(value)
+			if err != nil {
+				t.Errorf("%s marshalling %T failed: %v", e.name, value, err)
+				continue
+			}
+
+			p := reflect.New(reflect.TypeOf(value))
+			if err := e.unmarshal(data, p.Interface()); err != nil {
+				t.Errorf("%s unmarshalling %T from %q failed: %v", e.name, value, data, err)
+			} else if decoded := p.Elem().Interface(); decoded != value {
+				t.Errorf("%s round trip of %T changed %v to %v", e.name, value, value, decoded)
+			}
+
+			// Every element is 4 bytes, and W is one of a Quat's
+			if size := reflect.TypeOf(value).Size(); e.name == "Binary" && uintptr(len(data)) != size {
+				t.Errorf("Binary form of %T is %d bytes, expected %d", value, len(data), size)
+			}
+
+			if err := e.unmarshal(data[:len(data)-1], p.Interface()); err == nil && e.name == "Binary" {
+				t.Errorf("Binary unmarshalling %T from %d bytes succeeded", value, len(data)-1)
+			}
+		}
+	}
+}
+
+func TestEncodingFormats(t *testing.T) {
+	v := Vec3{1, -2.5, float64(math.Inf(1))}
+	q := Quat{1, Vec3{0, .5, 0}}
+	m := Mat2{1, 2, 3, 4}
+
+	// Negative zero has only the sign bit set, which is in the last byte when little endian
+	negativeZero := Vec2{0, float64(math.Copysign(0, -1))}
+	zeroBytes := strings.Repeat("\x00", floatBits/8)
+
+	tests := []struct {
+		value    interface{}
+		encoding func() ([]byte, error)
+		expected string
+	}{
+		{v, v.MarshalText, "1 -2.5 +Inf"},
+		{q, q.MarshalText, "1 0 0.5 0"},
+		{q, q.MarshalJSON, `{"W":1,"V":[0,0.5,0]}`},
+		{m, m.MarshalJSON, "[1,2,3,4]"},
+		{Vec2{.1, 1e20}, Vec2{.1, 1e20}.MarshalText, "0.1 1e+20"},
+		{negativeZero, negativeZero.MarshalBinary, zeroBytes + zeroBytes[1:] + "\x80"},
+	}
+	for _, c := range tests {
+		if data, err := c.encoding(); err != nil || string(data) != c.expected {
+			t.Errorf("%v encodes as %q (error %v), expected %q", c.value, data, err, c.expected)
+		}
+	}
+
+	// Values inside other types use the same encodings
+	var scene struct {
+		Position Vec3
+		Rotation Quat
+	}
+	if err := json.Unmarshal([]byte(`{"Position":[1,2,3],"Rotation":{"W":0,"V":[1,0,0]}}`), &scene); err != nil {
+		t.Errorf("Unmarshalling a struct failed: %v", err)
+	} else if scene.Position != (Vec3{1, 2, 3}) || scene.Rotation != (Quat{0, Vec3{1, 0, 0}}) {
+		t.Errorf("Unmarshalled struct is %v", scene)
+	}
+
+	for _, bad := range []string{"", "1 2", "1 2 3 4", "1 x 3", "1,2,3", "1 2 1e400"} {
+		if err := v.UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("Unmarshalling Vec3 from %q succeeded", bad)
+		}
+	}
+	for _, bad := range []string{"[1,2]", "[1,2,3,4]", `"1 2 3"`, "{}"} {
+		if err := json.Unmarshal([]byte(bad), &v); err == nil {
+			t.Errorf("Unmarshalling Vec3 from JSON %s succeeded", bad)
+		}
+	}
+	if err := json.Unmarshal([]byte("null"), &v); err != nil || v != (Vec3{1, -2.5, float64(math.Inf(1))}) {
+		t.Errorf("Unmarshalling Vec3 from JSON null changed it to %v, or failed: %v", v, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	m4 := Ident4()
+	tests := []struct {
+		format   string
+		value    interface{}
+		expected string
+	}{
+		{"%v", Vec3{1, 2.5, -3}, "[1 2.5 -3]"},
+		{"%.2f", Vec3{1, 2.5, -3}, "[1.00 2.50 -3.00]"},
+		{"%+6.1f", Vec2{1, -2}, "[  +1.0   -2.0]"},
+		{"%e", Vec2{1, 1000}, "[1.000000e+00 1.000000e+03]"},
+		{"%.3g", Mat2{1. / 3, 2, 3, 4}, "[0.333 2 3 4]"},
+		{"%s", Vec2{1, 2}, "[1 2]"},
+		{"%#v", Vec2{1, 2.5}, fmt.Sprintf("%T{1, 2.5}", Vec2{})},
+		{"%d", Vec2{1, 2}, fmt.Sprintf("%%!d(%T=[1 2])", Vec2{})},
+		{"%v", Quat{1, Vec3{0, .5, 0}}, "{1 [0 0.5 0]}"},
+		{"%.1f", Quat{1, Vec3{0, .5, 0}}, "{1.0 [0.0 0.5 0.0]}"},
+		{"%#v", Quat{1, Vec3{0, .5, 0}}, fmt.Sprintf("%T{W:1, V:%T{0, 0.5, 0}}", Quat{}, Vec3{})},
+		{"%v", m4, m4.String()},
+		{"%.0f", m4, "[1 0 0 0 0 1 0 0 0 0 1 0 0 0 0 1]"},
+	}
+
+	for _, c := range tests {
+		if s := fmt.Sprintf(c.format, c.value); s != c.expected {
+			t.Errorf("Formatting %T with %q gives %q, expected %q", c.value, c.format, s, c.expected)
+		}
+	}
+
+	// Without a verb's extras, the output is the same as without a Formatter
+	type plain [3]float64
+	if s, expected := fmt.Sprint(Vec3{1. / 3, 2, -1e-9}), fmt.Sprint(plain{1. / 3, 2, -1e-9}); s != expected {
+		t.Errorf("Printing a Vec3 gives %q, expected %q", s, expected)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"testing"
+)
+
+// polylineDistance returns the distance from p to the closest segment of line
+func polylineDistance(p Vec2, line []Vec2) float64 {
+	best := float64(math.Inf(1))
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		ab := b.Sub(a)
+		t := float64(0)
+		if ab.Dot(ab) != 0 {
+			t = Clamp(p.Sub(a).Dot(ab)/ab.Dot(ab), 0, 1)
+		}
+		if d := a.Add(ab.Mul(t)).Sub(p).Len(); d < best {
+			best = d
+		}
+	}
+
+	return best
+}
+
+func TestFlattenCubicBezierTolerance(t *testing.T) {
+	c1, c2, c3, c4 := Vec2{0, 0}, Vec2{0, 100}, Vec2{100, -100}, Vec2{100, 0}
+
+	for _, tolerance := range []float64{1, .25, .01} {
+		line := FlattenCubicBezierCurve2D(c1, c2, c3, c4, tolerance)
+
+		if line[0] != c1 || line[len(line)-1] != c4 {
+			t.Errorf("Flattened curve does not start and end at the end points: %v %v", line[0], line[len(line)-1])
+		}
+
+		for i := 0; i <= 1000; i++ {
+			p := CubicBezierCurve2D(float64(i)/1000, c1, c2, c3, c4)
+			if d := polylineDistance(p, line); d > tolerance*1.01 {
+				t.Errorf("Curve point %v is %v from the polyline, tolerance is %v", p, d, tolerance)
+				break
+			}
+		}
+	}
+
+	coarse := FlattenCubicBezierCurve2D(c1, c2, c3, c4, 1)
+	fine := FlattenCubicBezierCurve2D(c1, c2, c3, c4, .01)
+	if len(fine) <= len(coarse) {
+		t.Errorf("Tighter tolerance produced %d points, looser tolerance produced %d", len(fine), len(coarse))
+	}
+}
+
+func TestFlattenStraightBezier(t *testing.T) {
+	line := FlattenQuadraticBezierCurve2D(Vec2{0, 0}, Vec2{5, 5}, Vec2{10, 10}, .01)
+	if len(line) != 2 {
+		t.Errorf("Straight quadratic curve flattened to %d points, expected 2", len(line))
+	}
+
+	line = FlattenCubicBezierCurve2D(Vec2{0, 0}, Vec2{3, 0}, Vec2{6, 0}, Vec2{9, 0}, .01)
+	if len(line) != 2 {
+		t.Errorf("Straight cubic curve flattened to %d points, expected 2", len(line))
+	}
+}
+
+func TestFlattenQuadraticBezierTolerance(t *testing.T) {
+	c1, c2, c3 := Vec2{0, 0}, Vec2{50, 200}, Vec2{100, 0}
+	tolerance := float64(.1)
+	line := FlattenQuadraticBezierCurve2D(c1, c2, c3, tolerance)
+
+	for i := 0; i <= 1000; i++ {
+		p := QuadraticBezierCurve2D(float64(i)/1000, c1, c2, c3)
+		if d := polylineDistance(p, line); d > tolerance*1.01 {
+			t.Errorf("Curve point %v is %v from the polyline, tolerance is %v", p, d, tolerance)
+			break
+		}
+	}
+}
+
+func TestFlattenArc(t *testing.T) {
+	center, radius, tolerance := Vec2{1, 2}, float64(10), float64(.05)
+	line := FlattenArc2D(center, radius, 0, math.Pi, tolerance)
+
+	if !line[0].ApproxEqualThreshold(Vec2{11, 2}, 1e-5) || !line[len(line)-1].ApproxEqualThreshold(Vec2{-9, 2}, 1e-5) {
+		t.Errorf("Arc does not start and end at the right points: %v %v", line[0], line[len(line)-1])
+	}
+
+	// Each chord's midpoint is the point furthest from the arc
+	for i := 1; i < len(line); i++ {
+		mid := line[i-1].Add(line[i]).Mul(.5)
+		if d := radius - mid.Sub(center).Len(); d > tolerance*1.001 {
+			t.Errorf("Chord %d strays %v from the arc, tolerance is %v", i, d, tolerance)
+		}
+	}
+
+	// One fewer segment should be out of tolerance, so the result is minimal
+	n := float64(len(line) - 2)
+	if sagitta := float64(radius) * (1 - math.Cos(math.Pi/n/2)); sagitta <= float64(tolerance) {
+		t.Errorf("Arc used %d segments, but %v would have been enough", len(line)-1, n)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestFloatTrackInterpolation(t *testing.T) {
+	tests := []struct {
+		interpolation Interpolation
+		ease          [2]Vec2
+		samples       map[float64]float64
+	}{
+		{InterpolateStep, [2]Vec2{}, map[float64]float64{-1: 0, 0: 0, 1.9: 0, 2: 10, 3: 10}},
+		{InterpolateLinear, [2]Vec2{}, map[float64]float64{-1: 0, 0: 0, .5: 2.5, 1: 5, 2: 10, 3: 10}},
+		// The control points of a straight line give linear interpolation
+		{InterpolateBezier, [2]Vec2{{1. / 3, 1. / 3}, {2. / 3, 2. / 3}}, map[float64]float64{.5: 2.5, 1: 5, 1.5: 7.5}},
+		// CSS's ease-in-out is symmetric, and its ease is 0.8024 of the way there half way through
+		{InterpolateBezier, [2]Vec2{{.42, 0}, {.58, 1}}, map[float64]float64{0: 0, 1: 5, 2: 10}},
+		{InterpolateBezier, [2]Vec2{{.25, .1}, {.25, 1}}, map[float64]float64{1: 8.024034}},
+	}
+
+	for _, c := range tests {
+		tr, err := NewFloatTrack([]FloatKey{
+			{Time: 0, Value: 0, Interpolation: c.interpolation, Ease: c.ease},
+			{Time: 2, Value: 10},
+		}, WrapClamp)
+		if err != nil {
+			t.Fatalf("Creating a track with %v failed: %v", c.interpolation, err)
+		}
+		for time, expected := range c.samples {
+			if v := tr.Sample(time); !FloatEqualThreshold(v, expected, 1e-5) {
+				t.Errorf("%v track with ease %v at time %v is %v, expected %v", c.interpolation, c.ease, time, v, expected)
+			}
+		}
+	}
+
+	// Flat tangents give smoothstep, and steep ones overshoot
+	tr := &FloatTrack{Keys: []FloatKey{
+		{Time: 1, Value: 0, Interpolation: InterpolateHermite},
+		{Time: 2, Value: 1, InTangent: 0, OutTangent: 4, Interpolation: InterpolateHermite},
+		{Time: 4, Value: 1, InTangent: -4},
+	}}
+	for time, expected := range map[float64]float64{1: 0, 1.25: .15625, 1.5: .5, 2: 1, 3: 3, 4: 1} {
+		if v := tr.Sample(time); !FloatEqualThreshold(v, expected, 1e-5) {
+			t.Errorf("Hermite track at time %v is %v, expected %v", time, v, expected)
+		}
+	}
+}
+
+func TestBezierEase(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 1000; i++ {
+		p1 := Vec2{rand.Float64(), rand.Float64()*4 - 2}
+		p2 := Vec2{rand.Float64(), rand.Float64()*4 - 2}
+		x := rand.Float64()
+
+		// The curve's x at the found parameter should be x
+		y := bezierEase(p1, p2, x)
+		found := false
+		for s := float64(0); s <= 1; s += 1. / 4096 {
+			point := CubicBezierCurve2D(s, Vec2{0, 0}, p1, p2, Vec2{1, 1})
+			if Abs(point[0]-x) < 1e-3 && Abs(point[1]-y) < 1e-2 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("Easing %v with control points %v and %v gives %v, which isn't on the curve", x, p1, p2, y)
+		}
+	}
+}
+
+func TestTrackWrap(t *testing.T) {
+	keys := []FloatKey{{Time: 1, Value: 0, Interpolation: InterpolateLinear}, {Time: 3, Value: 2}}
+	tests := map[WrapMode]map[float64]float64{
+		WrapClamp:    {0: 0, 2: 1, 4: 2},
+		WrapLoop:     {0: 1, 1: 0, 2.5: 1.5, 3: 0, 3.5: .5, 9: 0, -5.5: 1.5},
+		WrapPingPong: {0: 1, 1: 0, 2.5: 1.5, 3: 2, 3.5: 1.5, 5: 0, 5.5: .5, -5.5: 1.5},
+	}
+
+	for wrap, samples := range tests {
+		tr, err := NewFloatTrack(keys, wrap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for time, expected := range samples {
+			if v := tr.Sample(time); !FloatEqualThreshold(v, expected, 1e-5) {
+				t.Errorf("%v track at time %v is %v, expected %v", wrap, time, v, expected)
+			}
+		}
+	}
+
+	// Times that aren't finite map to a keyframe rather than past the end of the track
+	for _, wrap := range []WrapMode{WrapClamp, WrapLoop, WrapPingPong} {
+		tr, err := NewFloatTrack(keys, wrap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, time := range []float64{float64(math.NaN()), float64(math.Inf(1)), float64(math.Inf(-1))} {
+			if v := tr.Sample(time); v != 0 && v != 2 {
+				t.Errorf("%v track at time %v is %v, expected the value of a keyframe", wrap, time, v)
+			}
+		}
+	}
+	var c keyCursor
+	if k, frac := c.find(float64(math.NaN()), 2, func(i int) float64 { return keys[i].Time }); k != 1 || frac != 0 {
+		t.Errorf("Keyframe at time NaN is %d at %v, expected 1 at 0", k, frac)
+	}
+
+	// A single keyframe is constant
+	if v := (&FloatTrack{Keys: []FloatKey{{Time: 1, Value: 7}}, Wrap: WrapLoop}).Sample(5); v != 7 {
+		t.Errorf("Track with one keyframe is %v, expected 7", v)
+	}
+}
+
+func TestTrackCursor(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	keys := make([]FloatKey, 50)
+	for i := range keys {
+		keys[i] = FloatKey{Time: float64(i) + rand.Float64()*.9, Value: rand.Float64(), Interpolation: InterpolateLinear}
+	}
+	tr, err := NewFloatTrack(keys, WrapClamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sampling forwards, backwards and at random gives the same values as a fresh track, which searches every time
+	times := make([]float64, 500)
+	for i := range times {
+		times[i] = rand.Float64()*60 - 5
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	for i := 0; i < 3; i++ {
+		for _, time := range times {
+			fresh := &FloatTrack{Keys: keys}
+			if v, expected := tr.Sample(time), fresh.Sample(time); v != expected {
+				t.Fatalf("Track at time %v is %v after sampling other times, expected %v", time, v, expected)
+			}
+		}
+		if i == 0 {
+			sort.Slice(times, func(i, j int) bool { return times[i] > times[j] })
+		} else {
+			rand.Shuffle(len(times), func(i, j int) { times[i], times[j] = times[j], times[i] })
+		}
+	}
+}
+
+func TestVec3Track(t *testing.T) {
+	tr, err := NewVec3Track([]Vec3Key{
+		{Time: 0, Value: Vec3{0, 0, 0}, Interpolation: InterpolateLinear},
+		{Time: 1, Value: Vec3{2, 4, 6}, OutTangent: Vec3{0, 0, 0}, Interpolation: InterpolateHermite},
+		{Time: 2, Value: Vec3{4, 4, 4}},
+	}, WrapClamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for time, expected := range map[float64]Vec3{.5: {1, 2, 3}, 1: {2, 4, 6}, 1.5: {3, 4, 5}, 2: {4, 4, 4}} {
+		if v := tr.Sample(time); !v.ApproxEqualThreshold(expected, 1e-5) {
+			t.Errorf("Track at time %v is %v, expected %v", time, v, expected)
+		}
+	}
+	if r := tr.Range(); r != [2]float64{0, 2} {
+		t.Errorf("Track's range is %v", r)
+	}
+}
+
+func TestQuatTrack(t *testing.T) {
+	y := Vec3{0, 1, 0}
+	tr, err := NewQuatTrack([]QuatKey{
+		{Time: 0, Value: QuatIdent(), Interpolation: InterpolateLinear},
+		// The negated quaternion is the same rotation, so the track still turns the short way
+		{Time: 1, Value: QuatRotate(math.Pi/2, y).Scale(-1), Interpolation: InterpolateBezier, Ease: [2]Vec2{{.42, 0}, {.58, 1}}},
+		{Time: 2, Value: QuatRotate(math.Pi, y), Interpolation: InterpolateHermite},
+		{Time: 3, Value: QuatRotate(math.Pi, y)},
+	}, WrapPingPong)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for time, degrees := range map[float64]float64{0: 0, .25: 22.5, .5: 45, 1.5: 135, 2.5: 180, 4.5: 135, 6.5: 45} {
+		q := tr.Sample(time)
+		expected := QuatRotate(DegToRad(degrees), y)
+		if !q.ApproxEqualThreshold(expected, 1e-4) && !q.ApproxEqualThreshold(expected.Scale(-1), 1e-4) {
+			t.Errorf("Track at time %v is %v, expected %v", time, q, expected)
+		}
+	}
+}
+
+func TestTrackValidate(t *testing.T) {
+	for _, keys := range [][]FloatKey{
+		nil,
+		{{Time: 1}, {Time: 1}},
+		{{Time: 1}, {Time: 0}},
+		{{Time: 0, Interpolation: Interpolation(7)}},
+		{{Time: 0, Interpolation: InterpolateBezier, Ease: [2]Vec2{{1.5, 0}, {.5, 1}}}},
+	} {
+		if _, err := NewFloatTrack(keys, WrapClamp); err == nil {
+			t.Errorf("Creating a track with keyframes %v succeeded", keys)
+		}
+	}
+
+	if s := InterpolateHermite.String() + " " + WrapPingPong.String() + " " + WrapMode(5).String(); s != "InterpolateHermite WrapPingPong WrapMode(5)" {
+		t.Errorf("Names are %q", s)
+	}
+}
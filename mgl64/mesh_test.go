@@ -0,0 +1,130 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"testing"
+)
+
+// checkMesh verifies the invariants every generated mesh should satisfy: consistent attribute lengths,
+// indices in range, unit normals and tangents perpendicular to them, and counter-clockwise triangles
+// facing the same way as their vertex normals.
+func checkMesh(t *testing.T, name string, m *Mesh) {
+	n := m.NumVertices()
+	if len(m.Normals) != n || len(m.Tangents) != n || len(m.UVs) != n {
+		t.Fatalf("%s: attribute lengths differ: %d positions, %d normals, %d tangents, %d UVs", name, n, len(m.Normals), len(m.Tangents), len(m.UVs))
+	}
+	if len(m.Indices)%3 != 0 || len(m.Indices) == 0 {
+		t.Fatalf("%s: has %d indices", name, len(m.Indices))
+	}
+
+	for i := 0; i < n; i++ {
+		if !FloatEqualThreshold(m.Normals[i].Len(), 1, 1e-4) {
+			t.Errorf("%s: normal %d is not normalized: %v", name, i, m.Normals[i])
+			return
+		}
+		if Abs(m.Tangents[i].Vec3().Dot(m.Normals[i])) > 1e-4 || m.Tangents[i][3] != 1 {
+			t.Errorf("%s: tangent %d is not perpendicular to normal: %v %v", name, i, m.Tangents[i], m.Normals[i])
+			return
+		}
+	}
+
+	for i := 0; i < len(m.Indices); i += 3 {
+		a, b, c := m.Indices[i], m.Indices[i+1], m.Indices[i+2]
+		if int(a) >= n || int(b) >= n || int(c) >= n {
+			t.Fatalf("%s: triangle %d has index out of range", name, i/3)
+		}
+
+		faceNormal := m.Positions[b].Sub(m.Positions[a]).Cross(m.Positions[c].Sub(m.Positions[a]))
+		vertexNormals := m.Normals[a].Add(m.Normals[b]).Add(m.Normals[c])
+		if faceNormal.Dot(vertexNormals) <= 0 {
+			t.Errorf("%s: triangle %d winds the wrong way", name, i/3)
+			return
+		}
+
+		// The tangent should follow increasing U across the face. This is skipped on the faces
+		// touching the poles of spheres, where U is undefined.
+		if Abs(m.Positions[a][0])+Abs(m.Positions[a][2]) < 1e-4 || Abs(m.Positions[b][0])+Abs(m.Positions[b][2]) < 1e-4 ||
+			Abs(m.Positions[c][0])+Abs(m.Positions[c][2]) < 1e-4 {
+			continue
+		}
+		e1, e2 := m.Positions[b].Sub(m.Positions[a]), m.Positions[c].Sub(m.Positions[a])
+		du1, dv1 := m.UVs[b][0]-m.UVs[a][0], m.UVs[b][1]-m.UVs[a][1]
+		du2, dv2 := m.UVs[c][0]-m.UVs[a][0], m.UVs[c][1]-m.UVs[a][1]
+		if det := du1*dv2 - du2*dv1; det != 0 {
+			uDir := e1.Mul(dv2).Sub(e2.Mul(dv1)).Mul(1 / det)
+			if uDir.Dot(m.Tangents[a].Vec3()) <= 0 {
+				t.Errorf("%s: tangent of triangle %d does not point towards increasing U", name, i/3)
+				return
+			}
+		}
+	}
+}
+
+func TestCubeMesh(t *testing.T) {
+	m := CubeMesh(2)
+	checkMesh(t, "cube", m)
+
+	if m.NumVertices() != 24 || m.NumTriangles() != 12 {
+		t.Errorf("Cube has %d vertices and %d triangles, expected 24 and 12", m.NumVertices(), m.NumTriangles())
+	}
+	for _, p := range m.Positions {
+		if Abs(p[0]) != 1 || Abs(p[1]) != 1 || Abs(p[2]) != 1 {
+			t.Errorf("Cube corner %v is not at distance 1 on every axis", p)
+		}
+	}
+}
+
+func TestSphereMeshes(t *testing.T) {
+	uv := UVSphereMesh(2, 16, 8)
+	checkMesh(t, "UV sphere", uv)
+
+	// Every stack is a quad strip, except the triangles touching the poles
+	if tris := uv.NumTriangles(); tris != 16*2*(8-1) {
+		t.Errorf("UV sphere has %d triangles, expected %d", tris, 16*2*(8-1))
+	}
+
+	ico := IcosphereMesh(2, 2)
+	checkMesh(t, "icosphere", ico)
+
+	if tris := ico.NumTriangles(); tris != 20*4*4 {
+		t.Errorf("Icosphere has %d triangles, expected %d", tris, 20*4*4)
+	}
+
+	for name, m := range map[string]*Mesh{"UV sphere": uv, "icosphere": ico} {
+		for _, p := range m.Positions {
+			if !FloatEqualThreshold(p.Len(), 2, 1e-5) {
+				t.Errorf("%s vertex %v is not on the sphere", name, p)
+				break
+			}
+		}
+	}
+}
+
+func TestLatheMeshes(t *testing.T) {
+	checkMesh(t, "cylinder", CylinderMesh(1, 2, 12))
+	checkMesh(t, "cone", ConeMesh(1, 2, 12))
+	checkMesh(t, "torus", TorusMesh(2, .5, 16, 8))
+	checkMesh(t, "capsule", CapsuleMesh(.5, 2, 12, 4))
+
+	capsule := CapsuleMesh(.5, 2, 12, 4)
+	min, max := capsule.Positions[0], capsule.Positions[0]
+	for _, p := range capsule.Positions {
+		SetMin(&min[1], &p[1])
+		SetMax(&max[1], &p[1])
+	}
+	if !FloatEqual(min[1], -1.5) || !FloatEqual(max[1], 1.5) {
+		t.Errorf("Capsule spans %v to %v in Y, expected -1.5 to 1.5", min[1], max[1])
+	}
+}
+
+func TestPlaneMesh(t *testing.T) {
+	m := PlaneMesh(4, 2, 4, 2)
+	checkMesh(t, "plane", m)
+
+	if m.NumVertices() != 15 || m.NumTriangles() != 16 {
+		t.Errorf("Plane has %d vertices and %d triangles, expected 15 and 16", m.NumVertices(), m.NumTriangles())
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+// snapPositions rounds the positions of a mesh to a grid, so the vertices along seams, which differ by rounding, are
+// in the same place.
+func snapPositions(m *Mesh) {
+	for i, p := range m.Positions {
+		for j := range p {
+			m.Positions[i][j] = float64(math.Round(float64(p[j])*1e4) / 1e4)
+		}
+	}
+}
+
+func TestGenerateNormals(t *testing.T) {
+	// The cube's faces meet at right angles, so anything less is flat
+	for _, crease := range []float64{0, math.Pi / 4} {
+		cube := CubeMesh(2)
+		expected := append([]Vec3(nil), cube.Normals...)
+		cube.Normals = nil
+		cube.GenerateNormals(crease)
+		checkMesh(t, "flat cube", cube)
+		if cube.NumVertices() != 24 {
+			t.Errorf("Flat cube with crease angle %v has %d vertices, expected 24", crease, cube.NumVertices())
+		}
+		for i, n := range cube.Normals {
+			if !n.ApproxEqualThreshold(expected[i], 1e-6) {
+				t.Errorf("Normal %d of flat cube is %v, expected %v", i, n, expected[i])
+			}
+		}
+	}
+
+	cube := CubeMesh(2)
+	cube.GenerateNormals(math.Pi)
+	for i, n := range cube.Normals {
+		if expected := cube.Positions[i].Normalize(); !n.ApproxEqualThreshold(expected, 1e-6) {
+			t.Errorf("Normal %d of smooth cube is %v, expected %v", i, n, expected)
+		}
+	}
+
+	// Smoothing is across the seam and the poles, which have several vertices in the same place once rounding is
+	// snapped away
+	sphere := UVSphereMesh(2, 32, 16)
+	snapPositions(sphere)
+	vertices := sphere.NumVertices()
+	sphere.GenerateNormals(math.Pi / 6)
+	if err := sphere.GenerateTangents(); err != nil {
+		t.Fatal(err)
+	}
+	checkMesh(t, "smooth sphere", sphere)
+	if sphere.NumVertices() != vertices {
+		t.Errorf("Smooth sphere has %d vertices, expected no copies", sphere.NumVertices())
+	}
+	for i, n := range sphere.Normals {
+		if n.Dot(sphere.Positions[i].Normalize()) < .999 {
+			t.Errorf("Normal %d of smooth sphere is %v, expected it near %v", i, n, sphere.Positions[i].Normalize())
+		}
+	}
+
+	// A cylinder has a crease at each cap, where it already has separate vertices for the side and the cap
+	cylinder := CylinderMesh(1, 2, 12)
+	snapPositions(cylinder)
+	vertices = cylinder.NumVertices()
+	cylinder.GenerateNormals(math.Pi / 3)
+	if err := cylinder.GenerateTangents(); err != nil {
+		t.Fatal(err)
+	}
+	checkMesh(t, "creased cylinder", cylinder)
+	if cylinder.NumVertices() != vertices {
+		t.Errorf("Creased cylinder has %d vertices, expected %d", cylinder.NumVertices(), vertices)
+	}
+}
+
+func TestGenerateNormalsCopies(t *testing.T) {
+	// A fold along the X axis, sharing the vertices along it, which each need two normals when flat
+	m := &Mesh{
+		Positions: []Vec3{{0, 0, 0}, {1, 0, 0}, {0, 0, -1}, {0, -1, 0}},
+		UVs:       []Vec2{{0, 0}, {1, 0}, {0, 1}, {0, -1}},
+		Indices:   []uint32{0, 1, 2, 1, 0, 3},
+	}
+	m.GenerateNormals(0)
+
+	if len(m.Positions) != 6 || len(m.Normals) != 6 || len(m.UVs) != 6 {
+		t.Fatalf("Fold has %d positions, %d normals and %d UVs, expected 6 of each",
+			len(m.Positions), len(m.Normals), len(m.UVs))
+	}
+	for i, n := range []Vec3{{0, 1, 0}, {0, 0, 1}} {
+		for _, v := range m.Indices[3*i : 3*i+3] {
+			if !m.Normals[v].ApproxEqualThreshold(n, 1e-6) {
+				t.Errorf("Normal of vertex %d of triangle %d is %v, expected %v", v, i, m.Normals[v], n)
+			}
+		}
+	}
+	if m.Indices[3] != 4 || m.Indices[4] != 5 || m.UVs[4] != m.UVs[1] || m.UVs[5] != m.UVs[0] {
+		t.Errorf("Second triangle is %v, with UVs %v", m.Indices[3:], m.UVs)
+	}
+
+	m.GenerateNormals(math.Pi)
+	if n := m.Normals[0]; !n.ApproxEqualThreshold(Vec3{0, 1, 1}.Normalize(), 1e-6) {
+		t.Errorf("Smooth normal at the fold is %v", n)
+	}
+}
+
+func TestGenerateTangents(t *testing.T) {
+	for name, m := range map[string]*Mesh{
+		"cube":     CubeMesh(2),
+		"sphere":   UVSphereMesh(2, 16, 8),
+		"torus":    TorusMesh(2, .5, 16, 8),
+		"plane":    PlaneMesh(4, 2, 4, 2),
+		"cylinder": CylinderMesh(1, 2, 12),
+	} {
+		expected := append([]Vec4(nil), m.Tangents...)
+		m.Tangents = nil
+		if err := m.GenerateTangents(); err != nil {
+			t.Fatalf("Generating tangents of %s failed: %v", name, err)
+		}
+		checkMesh(t, name, m)
+		if len(m.Tangents) != len(expected) {
+			t.Errorf("%s has %d vertices after generating tangents, expected %d", name, len(m.Tangents), len(expected))
+			continue
+		}
+
+		// Faces with right angles and uniform UVs have exactly the generated meshes' tangents
+		if name == "cube" || name == "plane" {
+			for i, tangent := range m.Tangents {
+				if !tangent.ApproxEqualThreshold(expected[i], 1e-6) {
+					t.Errorf("Tangent %d of %s is %v, expected %v", i, name, tangent, expected[i])
+				}
+			}
+		}
+	}
+
+	if err := (&Mesh{Positions: make([]Vec3, 3), UVs: make([]Vec2, 3), Indices: []uint32{0, 1, 2}}).GenerateTangents(); err == nil {
+		t.Error("Generating tangents without normals succeeded")
+	}
+}
+
+func TestGenerateTangentsMirrored(t *testing.T) {
+	// Two triangles in the XY plane sharing the edge from vertex 0 to 1, with U mirrored across it
+	m := &Mesh{
+		Positions: []Vec3{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {-1, 0, 0}},
+		Normals:   []Vec3{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {0, 0, 1}},
+		UVs:       []Vec2{{0, 0}, {0, 1}, {1, 0}, {1, 0}},
+		Indices:   []uint32{0, 2, 1, 0, 1, 3},
+	}
+	if err := m.GenerateTangents(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Positions) != 6 {
+		t.Fatalf("Mirrored triangles have %d vertices, expected the shared 2 to be copied", len(m.Positions))
+	}
+	for i, expected := range []Vec4{{1, 0, 0, 1}, {-1, 0, 0, -1}} {
+		for _, v := range m.Indices[3*i : 3*i+3] {
+			tangent := m.Tangents[v]
+			if !tangent.ApproxEqualThreshold(expected, 1e-6) {
+				t.Errorf("Tangent of vertex %d of triangle %d is %v, expected %v", v, i, tangent, expected)
+			}
+
+			// The bitangent follows increasing V either way
+			if bitangent := m.Normals[v].Cross(tangent.Vec3()).Mul(tangent[3]); !bitangent.ApproxEqualThreshold(Vec3{0, 1, 0}, 1e-6) {
+				t.Errorf("Bitangent of vertex %d of triangle %d is %v", v, i, bitangent)
+			}
+		}
+	}
+}
+
+func TestBoundingSphere(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	if center, radius := BoundingSphere(nil); center != (Vec3{}) || radius != 0 {
+		t.Errorf("Bounding sphere of no points is %v, %v", center, radius)
+	}
+	if center, radius := BoundingSphere([]Vec3{{1, 2, 3}}); center != (Vec3{1, 2, 3}) || radius != 0 {
+		t.Errorf("Bounding sphere of one point is %v, %v", center, radius)
+	}
+
+	cube := CubeMesh(2)
+	if bounds := cube.Bounds(); bounds != (AABB{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}) {
+		t.Errorf("Cube bounds are %v", bounds)
+	}
+	if center, radius := cube.BoundingSphere(); !center.ApproxEqualThreshold(Vec3{}, 1e-6) || !FloatEqual(radius, float64(math.Sqrt(3))) {
+		t.Errorf("Cube bounding sphere is %v, %v, expected the origin and √3", center, radius)
+	}
+
+	for i := 0; i < 100; i++ {
+		points := make([]Vec3, 1+rand.Intn(50))
+		offset := Vec3{rand.Float64()*20 - 10, rand.Float64()*20 - 10, rand.Float64()*20 - 10}
+		for j := range points {
+			// Points in a unit ball, so the smallest sphere has a radius of at most 1
+			p := Vec3{rand.Float64()*2 - 1, rand.Float64()*2 - 1, rand.Float64()*2 - 1}
+			if p.Len() > 1 {
+				p = p.Normalize()
+			}
+			points[j] = p.Add(offset)
+		}
+
+		center, radius := BoundingSphere(points)
+		for _, p := range points {
+			if p.Sub(center).Len() > radius {
+				t.Fatalf("Point %v is outside bounding sphere %v, %v", p, center, radius)
+			}
+		}
+		if radius > 1.25 {
+			t.Errorf("Bounding sphere of points in a unit ball has radius %v", radius)
+		}
+	}
+}
+
+// sortedTriangles returns the triangles of an index list, each rotated to start with its lowest index, in order.
+func sortedTriangles(indices []uint32) [][3]uint32 {
+	triangles := make([][3]uint32, len(indices)/3)
+	for i := range triangles {
+		tri := [3]uint32{indices[3*i], indices[3*i+1], indices[3*i+2]}
+		for tri[0] > tri[1] || tri[0] > tri[2] {
+			tri = [3]uint32{tri[1], tri[2], tri[0]}
+		}
+		triangles[i] = tri
+	}
+
+	sort.Slice(triangles, func(i, j int) bool {
+		a, b := triangles[i], triangles[j]
+		return a[0] < b[0] || a[0] == b[0] && (a[1] < b[1] || a[1] == b[1] && a[2] < b[2])
+	})
+	return triangles
+}
+
+func TestOptimizeVertexCache(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	m := PlaneMesh(1, 1, 64, 64)
+
+	// Shuffle the triangles, which makes the cache all but useless
+	n := m.NumTriangles()
+	for i := n - 1; i > 0; i-- {
+		j := rand.Intn(i + 1)
+		for k := 0; k < 3; k++ {
+			m.Indices[3*i+k], m.Indices[3*j+k] = m.Indices[3*j+k], m.Indices[3*i+k]
+		}
+	}
+	shuffled := append([]uint32(nil), m.Indices...)
+	before := AverageCacheMissRatio(m.Indices, 16)
+
+	m.OptimizeVertexCache()
+	after := AverageCacheMissRatio(m.Indices, 16)
+	if after > .8 || after > before/2 {
+		t.Errorf("Average cache miss ratio is %v after optimizing, and %v before", after, before)
+	}
+
+	a, b := sortedTriangles(shuffled), sortedTriangles(m.Indices)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Optimizing changed triangle %v to %v", a[i], b[i])
+		}
+	}
+
+	// Separate pieces and an index list with no triangles
+	indices := OptimizeVertexCache([]uint32{0, 1, 2, 3, 4, 5, 2, 1, 6}, 7)
+	if len(indices) != 9 || AverageCacheMissRatio(indices, 16) != 7./3 {
+		t.Errorf("Optimizing separate triangles gives %v", indices)
+	}
+	if indices := OptimizeVertexCache(nil, 0); len(indices) != 0 {
+		t.Errorf("Optimizing no triangles gives %v", indices)
+	}
+}
+
+func TestAverageCacheMissRatio(t *testing.T) {
+	// A strip of triangles misses only on its first two vertices and each new one
+	strip := []uint32{0, 1, 2, 2, 1, 3, 2, 3, 4, 4, 3, 5}
+	if acmr := AverageCacheMissRatio(strip, 4); acmr != 6./4 {
+		t.Errorf("Strip has average cache miss ratio %v, expected 1.5", acmr)
+	}
+
+	// With a cache of 2, every third vertex of a repeated triangle pushes out the one needed next
+	if acmr := AverageCacheMissRatio([]uint32{0, 1, 2, 0, 1, 2}, 2); acmr != 3 {
+		t.Errorf("Repeated triangle with a small cache has average cache miss ratio %v, expected 3", acmr)
+	}
+}
@@ -0,0 +1,445 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+)
+
+// Noise generates gradient and cellular noise. Each Noise is determined by its seed alone, so the results can be saved
+// or compared with golden values. They match on every platform and Go version to within rounding, not bit for bit,
+// since the compiler may fuse multiplications and additions on some architectures, like arm64, ppc64le and s390x.
+//
+// The gradient noise functions return the noise and its gradient, for normal mapping or for fractal noise that needs
+// derivatives; discard the gradient if it isn't needed. Internally all dimensions are computed with Vec4s, ignoring the
+// unused components.
+type Noise struct {
+	// A permutation of 0-255, repeated so that indices don't need wrapping
+	perm [512]uint8
+}
+
+// NewNoise creates a Noise from a seed.
+func NewNoise(seed int64) *Noise {
+	n := &Noise{}
+	for i := 0; i < 256; i++ {
+		n.perm[i] = uint8(i)
+	}
+
+	// Shuffle with splitmix64, rather than math/rand, to keep the permutation fixed for a seed
+	state := uint64(seed)
+	for i := 255; i > 0; i-- {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		z ^= z >> 31
+
+		j := int(z % uint64(i+1))
+		n.perm[i], n.perm[j] = n.perm[j], n.perm[i]
+	}
+	copy(n.perm[256:], n.perm[:256])
+
+	return n
+}
+
+// hash returns a pseudorandom byte for a lattice cell.
+func (n *Noise) hash(cell [4]int, dim int) int {
+	h := 0
+	for i := 0; i < dim; i++ {
+		h = int(n.perm[h+cell[i]&255])
+	}
+
+	return h
+}
+
+var (
+	// Gradient tables, with power of two lengths so that hashes pick evenly from them
+	gradients2 = []Vec4{
+		{1, 0, 0, 0}, {-1, 0, 0, 0}, {0, 1, 0, 0}, {0, -1, 0, 0},
+		{1, 1, 0, 0}, {-1, 1, 0, 0}, {1, -1, 0, 0}, {-1, -1, 0, 0},
+	}
+	// The edges of a cube, with four repeated as in Ken Perlin's improved noise
+	gradients3 = []Vec4{
+		{1, 1, 0, 0}, {-1, 1, 0, 0}, {1, -1, 0, 0}, {-1, -1, 0, 0},
+		{1, 0, 1, 0}, {-1, 0, 1, 0}, {1, 0, -1, 0}, {-1, 0, -1, 0},
+		{0, 1, 1, 0}, {0, -1, 1, 0}, {0, 1, -1, 0}, {0, -1, -1, 0},
+		{1, 1, 0, 0}, {-1, 1, 0, 0}, {0, -1, 1, 0}, {0, -1, -1, 0},
+	}
+	// The edges of a hypercube: every vector with one zero and the other components 1 or -1
+	gradients4 = func() []Vec4 {
+		var grads []Vec4
+		for zero := 0; zero < 4; zero++ {
+			for signs := 0; signs < 8; signs++ {
+				var g Vec4
+				bit := uint(0)
+				for i := range g {
+					if i != zero {
+						g[i] = float64(1 - 2*(signs>>bit&1))
+						bit++
+					}
+				}
+				grads = append(grads, g)
+			}
+		}
+
+		return grads
+	}()
+)
+
+// gradients returns the gradient table for a dimension.
+func gradients(dim int) []Vec4 {
+	switch dim {
+	case 2:
+		return gradients2
+	case 3:
+		return gradients3
+	default:
+		return gradients4
+	}
+}
+
+// Scales that bring each kind of noise to roughly [-1,1], found by sampling
+var (
+	perlinScales  = [5]float64{2: 1, 3: 1, 4: .84}
+	simplexScales = [5]float64{2: 70, 3: 76, 4: 62}
+)
+
+// perlin computes classic Perlin noise and its gradient in the first dim components of p.
+func (n *Noise) perlin(p Vec4, dim int) (float64, Vec4) {
+	grads := gradients(dim)
+
+	var cell [4]int
+	var frac, weights, weightDerivs Vec4
+	for i := 0; i < dim; i++ {
+		floor := math.Floor(float64(p[i]))
+		cell[i] = int(floor)
+		frac[i] = p[i] - float64(floor)
+
+		// The quintic fade curve 6t^5 - 15t^4 + 10t^3 and its derivative
+		t := frac[i]
+		weights[i] = t * t * t * (t*(t*6-15) + 10)
+		weightDerivs[i] = 30 * t * t * (t*(t-2) + 1)
+	}
+
+	var value float64
+	var gradient Vec4
+	for corner := 0; corner < 1<<uint(dim); corner++ {
+		c := cell
+		var offset, w, dw Vec4
+		weight := float64(1)
+		for i := 0; i < dim; i++ {
+			if corner>>uint(i)&1 == 1 {
+				c[i]++
+				offset[i] = frac[i] - 1
+				w[i], dw[i] = weights[i], weightDerivs[i]
+			} else {
+				offset[i] = frac[i]
+				w[i], dw[i] = 1-weights[i], -weightDerivs[i]
+			}
+			weight *= w[i]
+		}
+
+		g := grads[n.hash(c, dim)&(len(grads)-1)]
+		dot := g.Dot(offset)
+		value += weight * dot
+
+		for i := 0; i < dim; i++ {
+			// The derivative of the weight is the product of every weight but this one's, times this one's derivative
+			dWeight := dw[i]
+			for j := 0; j < dim; j++ {
+				if j != i {
+					dWeight *= w[j]
+				}
+			}
+			gradient[i] += weight*g[i] + dot*dWeight
+		}
+	}
+
+	return value * perlinScales[dim], gradient.Mul(perlinScales[dim])
+}
+
+// simplex computes simplex noise and its gradient in the first dim components of p, as described by Stefan Gustavson in
+// "Simplex noise demystified".
+func (n *Noise) simplex(p Vec4, dim int) (float64, Vec4) {
+	grads := gradients(dim)
+
+	// Skew the input space so that the simplices become half of a cube each...
+	skew := (float64(math.Sqrt(float64(dim+1))) - 1) / float64(dim)
+	unskew := (1 - 1/float64(math.Sqrt(float64(dim+1)))) / float64(dim)
+
+	var sum float64
+	for i := 0; i < dim; i++ {
+		sum += p[i]
+	}
+	var cell [4]int
+	var cellSum int
+	for i := 0; i < dim; i++ {
+		cell[i] = int(math.Floor(float64(p[i] + sum*skew)))
+		cellSum += cell[i]
+	}
+
+	// ...and find the offset from the cell's origin in unskewed space
+	var offset Vec4
+	for i := 0; i < dim; i++ {
+		offset[i] = p[i] - float64(cell[i]) + float64(cellSum)*unskew
+	}
+
+	// The simplex containing p goes from the cell's origin along the axes in order of decreasing offset
+	var order [4]int
+	for i := 0; i < dim; i++ {
+		order[i] = i
+		for j := i; j > 0 && offset[order[j]] > offset[order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+
+	var value float64
+	var gradient Vec4
+	c := cell
+	for corner := 0; corner <= dim; corner++ {
+		if corner > 0 {
+			axis := order[corner-1]
+			c[axis]++
+			offset[axis]--
+		}
+		// Moving along the skewed axes moves every unskewed coordinate back a little
+		var d Vec4
+		for i := 0; i < dim; i++ {
+			d[i] = offset[i] + float64(corner)*unskew
+		}
+
+		t := .5 - d.Dot(d)
+		if t <= 0 {
+			continue
+		}
+		g := grads[n.hash(c, dim)&(len(grads)-1)]
+		dot := g.Dot(d)
+		t2 := t * t
+
+		value += t2 * t2 * dot
+		gradient = gradient.Add(g.Mul(t2 * t2)).Sub(d.Mul(8 * t2 * t * dot))
+	}
+
+	return value * simplexScales[dim], gradient.Mul(simplexScales[dim])
+}
+
+// worley finds the distances to the nearest two feature points in the first dim components of p. Each lattice cell
+// holds one feature point at a pseudorandom position.
+func (n *Noise) worley(p Vec4, dim int) (f1, f2 float64) {
+	var cell [4]int
+	for i := 0; i < dim; i++ {
+		cell[i] = int(math.Floor(float64(p[i])))
+	}
+
+	f1, f2 = float64(math.Inf(1)), float64(math.Inf(1))
+	neighbors := 1
+	for i := 0; i < dim; i++ {
+		neighbors *= 3
+	}
+	for neighbor := 0; neighbor < neighbors; neighbor++ {
+		c := cell
+		for i, k := 0, neighbor; i < dim; i, k = i+1, k/3 {
+			c[i] += k%3 - 1
+		}
+
+		h := n.hash(c, dim)
+		var d Vec4
+		for i := 0; i < dim; i++ {
+			jitter := (float64(n.perm[h+1+i*61]) + .5) / 256
+			d[i] = float64(c[i]) + jitter - p[i]
+		}
+
+		dist := d.Len()
+		if dist < f1 {
+			f1, f2 = dist, f1
+		} else if dist < f2 {
+			f2 = dist
+		}
+	}
+
+	return f1, f2
+}
+
+// Perlin2 returns classic Perlin noise in two dimensions, in roughly [-1,1], and its gradient. It is zero at every
+// integer point.
+func (n *Noise) Perlin2(p Vec2) (float64, Vec2) {
+	value, gradient := n.perlin(p.Vec4(0, 0), 2)
+	return value, gradient.Vec2()
+}
+
+// Perlin3 returns classic Perlin noise in three dimensions, in roughly [-1,1], and its gradient. It is zero at every
+// integer point.
+func (n *Noise) Perlin3(p Vec3) (float64, Vec3) {
+	value, gradient := n.perlin(p.Vec4(0), 3)
+	return value, gradient.Vec3()
+}
+
+// Perlin4 returns classic Perlin noise in four dimensions, in roughly [-1,1], and its gradient. It is zero at every
+// integer point. The fourth dimension is often time, to animate 3D noise.
+func (n *Noise) Perlin4(p Vec4) (float64, Vec4) {
+	return n.perlin(p, 4)
+}
+
+// Simplex2 returns simplex noise in two dimensions, in roughly [-1,1], and its gradient. Simplex noise is cheaper than
+// Perlin noise in higher dimensions, and has fewer directional artifacts.
+func (n *Noise) Simplex2(p Vec2) (float64, Vec2) {
+	value, gradient := n.simplex(p.Vec4(0, 0), 2)
+	return value, gradient.Vec2()
+}
+
+// Simplex3 returns simplex noise in three dimensions, in roughly [-1,1], and its gradient.
+func (n *Noise) Simplex3(p Vec3) (float64, Vec3) {
+	value, gradient := n.simplex(p.Vec4(0), 3)
+	return value, gradient.Vec3()
+}
+
+// Simplex4 returns simplex noise in four dimensions, in roughly [-1,1], and its gradient.
+func (n *Noise) Simplex4(p Vec4) (float64, Vec4) {
+	return n.simplex(p, 4)
+}
+
+// Worley2 returns Worley (cellular) noise in two dimensions: the distances from p to the nearest and second nearest of a
+// set of feature points scattered one per unit square. F1 makes cells with dark centers, and F2-F1 dark cell borders.
+// Only the neighboring cells are searched, so F2 is very occasionally a little too large.
+func (n *Noise) Worley2(p Vec2) (f1, f2 float64) {
+	return n.worley(p.Vec4(0, 0), 2)
+}
+
+// Worley3 returns Worley noise in three dimensions, with one feature point per unit cube.
+func (n *Noise) Worley3(p Vec3) (f1, f2 float64) {
+	return n.worley(p.Vec4(0), 3)
+}
+
+// Worley4 returns Worley noise in four dimensions, with one feature point per unit hypercube.
+func (n *Noise) Worley4(p Vec4) (f1, f2 float64) {
+	return n.worley(p, 4)
+}
+
+// Fractal sums octaves of a noise function at increasing frequencies and decreasing amplitudes, for detail at every
+// scale. The sums are normalized by the total amplitude, so they have the same range as the noise (or [0,1] for Ridged and
+// Turbulence), and their gradients follow from the chain rule.
+type Fractal struct {
+	// The number of octaves to sum. The first has a frequency and amplitude of 1.
+	Octaves int
+	// The frequency multiplier from each octave to the next, usually 2.
+	Lacunarity float64
+	// The amplitude multiplier from each octave to the next, usually 0.5.
+	Gain float64
+}
+
+type fractalKind int
+
+const (
+	fractalFBm fractalKind = iota
+	fractalRidged
+	fractalTurbulence
+)
+
+// sum adds up the octaves of noise at p, which is padded to a Vec4.
+func (f Fractal) sum(kind fractalKind, noise func(Vec4) (float64, Vec4), p Vec4) (float64, Vec4) {
+	var total, norm float64
+	var gradient Vec4
+	amplitude, frequency := float64(1), float64(1)
+	for octave := 0; octave < f.Octaves; octave++ {
+		value, g := noise(p.Mul(frequency))
+
+		// The derivative of the shaped value with respect to the noise value
+		deriv := float64(1)
+		switch kind {
+		case fractalRidged:
+			// Sharp ridges where the noise crosses zero
+			ridge := 1 - Abs(value)
+			if value < 0 {
+				deriv = 2 * ridge
+			} else {
+				deriv = -2 * ridge
+			}
+			value = ridge * ridge
+		case fractalTurbulence:
+			if value < 0 {
+				deriv = -1
+			}
+			value = Abs(value)
+		}
+
+		total += amplitude * value
+		gradient = gradient.Add(g.Mul(amplitude * frequency * deriv))
+		norm += amplitude
+
+		amplitude *= f.Gain
+		frequency *= f.Lacunarity
+	}
+
+	if norm == 0 {
+		return 0, Vec4{}
+	}
+	return total / norm, gradient.Mul(1 / norm)
+}
+
+func (f Fractal) sum2(kind fractalKind, noise func(Vec2) (float64, Vec2), p Vec2) (float64, Vec2) {
+	value, gradient := f.sum(kind, func(q Vec4) (float64, Vec4) {
+		v, g := noise(q.Vec2())
+		return v, g.Vec4(0, 0)
+	}, p.Vec4(0, 0))
+
+	return value, gradient.Vec2()
+}
+
+func (f Fractal) sum3(kind fractalKind, noise func(Vec3) (float64, Vec3), p Vec3) (float64, Vec3) {
+	value, gradient := f.sum(kind, func(q Vec4) (float64, Vec4) {
+		v, g := noise(q.Vec3())
+		return v, g.Vec4(0)
+	}, p.Vec4(0))
+
+	return value, gradient.Vec3()
+}
+
+// FBm2 returns fractional Brownian motion: the plain sum of the octaves of noise, such as Noise.Perlin2 or
+// Noise.Simplex2, at p, and its gradient.
+func (f Fractal) FBm2(noise func(Vec2) (float64, Vec2), p Vec2) (float64, Vec2) {
+	return f.sum2(fractalFBm, noise, p)
+}
+
+// FBm3 returns fractional Brownian motion in three dimensions, and its gradient.
+func (f Fractal) FBm3(noise func(Vec3) (float64, Vec3), p Vec3) (float64, Vec3) {
+	return f.sum3(fractalFBm, noise, p)
+}
+
+// FBm4 returns fractional Brownian motion in four dimensions, and its gradient.
+func (f Fractal) FBm4(noise func(Vec4) (float64, Vec4), p Vec4) (float64, Vec4) {
+	return f.sum(fractalFBm, noise, p)
+}
+
+// Ridged2 returns ridged noise, in [0,1], and its gradient. Each octave is (1-|noise|)², which has sharp ridges where
+// the noise crosses zero, like mountain ranges.
+func (f Fractal) Ridged2(noise func(Vec2) (float64, Vec2), p Vec2) (float64, Vec2) {
+	return f.sum2(fractalRidged, noise, p)
+}
+
+// Ridged3 returns ridged noise in three dimensions, and its gradient.
+func (f Fractal) Ridged3(noise func(Vec3) (float64, Vec3), p Vec3) (float64, Vec3) {
+	return f.sum3(fractalRidged, noise, p)
+}
+
+// Ridged4 returns ridged noise in four dimensions, and its gradient.
+func (f Fractal) Ridged4(noise func(Vec4) (float64, Vec4), p Vec4) (float64, Vec4) {
+	return f.sum(fractalRidged, noise, p)
+}
+
+// Turbulence2 returns turbulence, in [0,1], and its gradient. Each octave is |noise|, which has creases where the noise
+// crosses zero, like billowing smoke or marble veins. The gradient is undefined along the creases.
+func (f Fractal) Turbulence2(noise func(Vec2) (float64, Vec2), p Vec2) (float64, Vec2) {
+	return f.sum2(fractalTurbulence, noise, p)
+}
+
+// Turbulence3 returns turbulence in three dimensions, and its gradient.
+func (f Fractal) Turbulence3(noise func(Vec3) (float64, Vec3), p Vec3) (float64, Vec3) {
+	return f.sum3(fractalTurbulence, noise, p)
+}
+
+// Turbulence4 returns turbulence in four dimensions, and its gradient.
+func (f Fractal) Turbulence4(noise func(Vec4) (float64, Vec4), p Vec4) (float64, Vec4) {
+	return f.sum(fractalTurbulence, noise, p)
+}
@@ -0,0 +1,306 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+)
+
+// The Pack functions compress vectors for vertex buffers and textures. Where GLSL has a built in function of the same
+// name, the packing is the same, so a shader can unpack the data with it (or the vertex fetch can, with the matching
+// vertex attribute format). The first component is in the lowest bits, which in little endian memory is the order
+// the GPU reads.
+//
+// PackRGB10A2 and PackRGBA8 in color.go pack unsigned normalized colors.
+
+// FloatToHalf converts f to an IEEE 754 half precision (binary16) float, rounding to the nearest, ties to even. Values
+// too large for a half become infinities, and NaNs stay NaNs.
+func FloatToHalf(f float64) uint16 {
+	bits := math.Float64bits(float64(f))
+	sign := uint16(bits>>48) & 0x8000
+	exponent := int(bits>>52&0x7ff) - 1023 + 15
+	mantissa := bits & (1<<52 - 1)
+
+	switch {
+	case exponent == 0x7ff-1023+15 && mantissa != 0:
+		return sign | 0x7e00
+	case exponent >= 0x1f:
+		return sign | 0x7c00
+	case exponent < -10:
+		// Less than half the smallest subnormal
+		return sign
+	}
+
+	// Shift the 52 bit mantissa down to 10, or further for subnormals, which also need their leading 1
+	shift := uint(52 - 10)
+	if exponent <= 0 {
+		mantissa |= 1 << 52
+		shift += uint(1 - exponent)
+		exponent = 0
+	}
+
+	rest, halfway := mantissa&(1<<shift-1), uint64(1)<<(shift-1)
+	mantissa >>= shift
+	if rest > halfway || rest == halfway && mantissa&1 == 1 {
+		// Rounding up may carry into the exponent, which makes the next power of two, or infinity
+		mantissa++
+	}
+
+	return sign | uint16(exponent<<10+int(mantissa))
+}
+
+// HalfToFloat converts an IEEE 754 half precision float to a float32, which is always exact.
+func HalfToFloat(h uint16) float64 {
+	exponent, mantissa := int(h>>10&0x1f), float64(h&0x3ff)
+
+	var f float64
+	switch exponent {
+	case 0:
+		f = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		f = math.Inf(1)
+		if mantissa != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mantissa+0x400, exponent-25)
+	}
+
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return float64(f)
+}
+
+// PackHalf2x16 converts the components of v to half precision floats, and packs them into 16 bits each.
+func PackHalf2x16(v Vec2) uint32 {
+	return uint32(FloatToHalf(v[0])) | uint32(FloatToHalf(v[1]))<<16
+}
+
+// UnpackHalf2x16 unpacks a vector packed by PackHalf2x16.
+func UnpackHalf2x16(p uint32) Vec2 {
+	return Vec2{HalfToFloat(uint16(p)), HalfToFloat(uint16(p >> 16))}
+}
+
+// PackHalf4x16 converts the components of v to half precision floats, and packs them into 16 bits each, which is the
+// vertex attribute format GL_HALF_FLOAT with a size of 4. Pack a Vec3 with a fourth component of 0 or 1.
+func PackHalf4x16(v Vec4) uint64 {
+	return uint64(PackHalf2x16(Vec2{v[0], v[1]})) | uint64(PackHalf2x16(Vec2{v[2], v[3]}))<<32
+}
+
+// UnpackHalf4x16 unpacks a vector packed by PackHalf4x16.
+func UnpackHalf4x16(p uint64) Vec4 {
+	xy, zw := UnpackHalf2x16(uint32(p)), UnpackHalf2x16(uint32(p>>32))
+	return Vec4{xy[0], xy[1], zw[0], zw[1]}
+}
+
+// snorm converts c, clamped to [-1,1], to a signed integer with the given maximum, rounding to the nearest (halves
+// away from zero), and returns its two's complement bits.
+func snorm(c float64, max uint32) uint32 {
+	return uint32(int32(math.Round(float64(Clamp(c, -1, 1) * float64(max)))))
+}
+
+// fromSnorm converts the lowest bits of p to a float in [-1,1]. The smallest value, one less than -max, is -1 like -max.
+func fromSnorm(p uint32, bits uint, max uint32) float64 {
+	// Shift the sign bit to the top, and back with sign extension
+	i := int32(p<<(32-bits)) >> (32 - bits)
+	return Clamp(float64(i)/float64(max), -1, 1)
+}
+
+// PackUnorm2x16 packs the components of v, clamped to [0,1], into 16 bits each as unsigned normalized integers.
+func PackUnorm2x16(v Vec2) uint32 {
+	return unorm(v[0], 0xffff) | unorm(v[1], 0xffff)<<16
+}
+
+// UnpackUnorm2x16 unpacks a vector packed by PackUnorm2x16.
+func UnpackUnorm2x16(p uint32) Vec2 {
+	return Vec2{float64(p&0xffff) / 0xffff, float64(p>>16) / 0xffff}
+}
+
+// PackSnorm2x16 packs the components of v, clamped to [-1,1], into 16 bits each as signed normalized integers.
+func PackSnorm2x16(v Vec2) uint32 {
+	return snorm(v[0], 0x7fff)&0xffff | snorm(v[1], 0x7fff)<<16
+}
+
+// UnpackSnorm2x16 unpacks a vector packed by PackSnorm2x16.
+func UnpackSnorm2x16(p uint32) Vec2 {
+	return Vec2{fromSnorm(p, 16, 0x7fff), fromSnorm(p>>16, 16, 0x7fff)}
+}
+
+// PackUnorm4x8 packs the components of v, clamped to [0,1], into 8 bits each as unsigned normalized integers.
+func PackUnorm4x8(v Vec4) uint32 {
+	return unorm(v[0], 0xff) | unorm(v[1], 0xff)<<8 | unorm(v[2], 0xff)<<16 | unorm(v[3], 0xff)<<24
+}
+
+// UnpackUnorm4x8 unpacks a vector packed by PackUnorm4x8.
+func UnpackUnorm4x8(p uint32) Vec4 {
+	return Vec4{float64(p&0xff) / 0xff, float64(p>>8&0xff) / 0xff, float64(p>>16&0xff) / 0xff, float64(p>>24) / 0xff}
+}
+
+// PackSnorm4x8 packs the components of v, clamped to [-1,1], into 8 bits each as signed normalized integers.
+func PackSnorm4x8(v Vec4) uint32 {
+	return snorm(v[0], 0x7f)&0xff | snorm(v[1], 0x7f)&0xff<<8 | snorm(v[2], 0x7f)&0xff<<16 | snorm(v[3], 0x7f)<<24
+}
+
+// UnpackSnorm4x8 unpacks a vector packed by PackSnorm4x8.
+func UnpackSnorm4x8(p uint32) Vec4 {
+	return Vec4{fromSnorm(p, 8, 0x7f), fromSnorm(p>>8, 8, 0x7f), fromSnorm(p>>16, 8, 0x7f), fromSnorm(p>>24, 8, 0x7f)}
+}
+
+// PackUnorm4x16 packs the components of v, clamped to [0,1], into 16 bits each as unsigned normalized integers.
+func PackUnorm4x16(v Vec4) uint64 {
+	return uint64(PackUnorm2x16(Vec2{v[0], v[1]})) | uint64(PackUnorm2x16(Vec2{v[2], v[3]}))<<32
+}
+
+// UnpackUnorm4x16 unpacks a vector packed by PackUnorm4x16.
+func UnpackUnorm4x16(p uint64) Vec4 {
+	xy, zw := UnpackUnorm2x16(uint32(p)), UnpackUnorm2x16(uint32(p>>32))
+	return Vec4{xy[0], xy[1], zw[0], zw[1]}
+}
+
+// PackSnorm4x16 packs the components of v, clamped to [-1,1], into 16 bits each as signed normalized integers.
+func PackSnorm4x16(v Vec4) uint64 {
+	return uint64(PackSnorm2x16(Vec2{v[0], v[1]})) | uint64(PackSnorm2x16(Vec2{v[2], v[3]}))<<32
+}
+
+// UnpackSnorm4x16 unpacks a vector packed by PackSnorm4x16.
+func UnpackSnorm4x16(p uint64) Vec4 {
+	xy, zw := UnpackSnorm2x16(uint32(p)), UnpackSnorm2x16(uint32(p>>32))
+	return Vec4{xy[0], xy[1], zw[0], zw[1]}
+}
+
+// PackSnormRGB10A2 packs the components of v, clamped to [-1,1], into 10 bits for each of x, y and z and 2 bits for
+// w as signed normalized integers, which is the vertex attribute format GL_INT_2_10_10_10_REV, normalized. It suits
+// normals, or tangents with their handedness in w.
+func PackSnormRGB10A2(v Vec4) uint32 {
+	return snorm(v[0], 0x1ff)&0x3ff | snorm(v[1], 0x1ff)&0x3ff<<10 | snorm(v[2], 0x1ff)&0x3ff<<20 | snorm(v[3], 1)<<30
+}
+
+// UnpackSnormRGB10A2 unpacks a vector packed by PackSnormRGB10A2.
+func UnpackSnormRGB10A2(p uint32) Vec4 {
+	return Vec4{fromSnorm(p, 10, 0x1ff), fromSnorm(p>>10, 10, 0x1ff), fromSnorm(p>>20, 10, 0x1ff), fromSnorm(p>>30, 2, 1)}
+}
+
+// OctahedronEncode maps a unit vector to a point in [-1,1]², by projecting it onto an octahedron and unfolding the
+// lower half over the upper half. Packed with PackSnorm2x16, it keeps unit vectors such as normals to within about
+// 0.003 degrees in 4 bytes rather than 12.
+func OctahedronEncode(n Vec3) Vec2 {
+	n = n.Mul(1 / (Abs(n[0]) + Abs(n[1]) + Abs(n[2])))
+	if n[2] >= 0 {
+		return Vec2{n[0], n[1]}
+	}
+	return Vec2{(1 - Abs(n[1])) * signNotZero(n[0]), (1 - Abs(n[0])) * signNotZero(n[1])}
+}
+
+// OctahedronDecode maps a point encoded by OctahedronEncode back to a unit vector.
+func OctahedronDecode(e Vec2) Vec3 {
+	n := Vec3{e[0], e[1], 1 - Abs(e[0]) - Abs(e[1])}
+	if n[2] < 0 {
+		n[0], n[1] = (1-Abs(e[1]))*signNotZero(e[0]), (1-Abs(e[0]))*signNotZero(e[1])
+	}
+	return n.Normalize()
+}
+
+// signNotZero is 1 for 0, rather than 0, so the folded half of the octahedron covers its edges.
+func signNotZero(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
+
+// PackOctahedron2x16 packs a unit vector into the 16 bit signed normalized components of its octahedral encoding. Of
+// the four nearest encodings, it picks the one that decodes closest to n, so unpacking and packing again gives the
+// same vector.
+func PackOctahedron2x16(n Vec3) uint32 {
+	e := OctahedronEncode(n)
+	base := Vec2{
+		float64(math.Floor(float64(Clamp(e[0], -1, 1)*0x7fff))) / 0x7fff,
+		float64(math.Floor(float64(Clamp(e[1], -1, 1)*0x7fff))) / 0x7fff,
+	}
+
+	var best uint32
+	bestDistance := float64(math.Inf(1))
+	for i := 0; i < 4; i++ {
+		candidate := PackSnorm2x16(base.Add(Vec2{float64(i&1) / 0x7fff, float64(i>>1) / 0x7fff}))
+		// Compare distances rather than dot products, which are too close to 1 to tell apart
+		d := UnpackOctahedron2x16(candidate).Sub(n)
+		if distance := d.Dot(d); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best
+}
+
+// UnpackOctahedron2x16 unpacks a unit vector packed by PackOctahedron2x16.
+func UnpackOctahedron2x16(p uint32) Vec3 {
+	return OctahedronDecode(UnpackSnorm2x16(p))
+}
+
+// qTangentBias is the smallest magnitude of W in a QTangent, which is the smallest nonzero 16 bit signed normalized
+// value, so W keeps its sign after packing.
+const qTangentBias = 1. / 0x7fff
+
+// QTangent encodes a tangent frame, as in Mesh's Normals and Tangents, as a quaternion: the rotation from the X, Y and
+// Z axes to the tangent, bitangent and normal, with the handedness of the frame in the sign of W. The tangent is
+// made perpendicular to the normal first. This takes one Vec4 per vertex rather than a Vec3 and a Vec4, and a shader
+// can rotate by it directly; pack it with PackQTangent.
+func QTangent(normal Vec3, tangent Vec4) Quat {
+	n := normal.Normalize()
+	t := tangent.Vec3().Sub(n.Mul(n.Dot(tangent.Vec3()))).Normalize()
+	q := basisToQuat(t, n.Cross(t), n)
+
+	// Make W positive, but not too small to keep a sign
+	if q.W < 0 {
+		q = q.Scale(-1)
+	}
+	if q.W < qTangentBias {
+		q.V = q.V.Mul(float64(math.Sqrt(1-qTangentBias*qTangentBias)) / q.V.Len())
+		q.W = qTangentBias
+	}
+
+	if tangent[3] < 0 {
+		q = q.Scale(-1)
+	}
+	return q
+}
+
+// QTangentFrame decodes a tangent frame encoded by QTangent.
+func QTangentFrame(q Quat) (normal Vec3, tangent Vec4) {
+	q = q.Normalize()
+	handedness := signNotZero(q.W)
+	return q.Rotate(Vec3{0, 0, 1}), q.Rotate(Vec3{1, 0, 0}).Vec4(handedness)
+}
+
+// PackQTangent packs a QTangent into 16 bit signed normalized X, Y, Z and W components, in that order.
+func PackQTangent(q Quat) uint64 {
+	return PackSnorm4x16(q.V.Vec4(q.W))
+}
+
+// UnpackQTangent unpacks a QTangent packed by PackQTangent.
+func UnpackQTangent(p uint64) Quat {
+	v := UnpackSnorm4x16(p)
+	return Quat{v[3], v.Vec3()}
+}
+
+// basisToQuat returns the rotation from the X, Y and Z axes to an orthonormal right handed basis, with Ken Shoemake's
+// method, which takes the square root of the largest of the four possible diagonal sums for accuracy.
+func basisToQuat(x, y, z Vec3) Quat {
+	switch trace := x[0] + y[1] + z[2]; {
+	case trace > 0:
+		s := float64(math.Sqrt(float64(trace+1))) * 2
+		return Quat{s / 4, Vec3{(y[2] - z[1]) / s, (z[0] - x[2]) / s, (x[1] - y[0]) / s}}
+	case x[0] > y[1] && x[0] > z[2]:
+		s := float64(math.Sqrt(float64(1+x[0]-y[1]-z[2]))) * 2
+		return Quat{(y[2] - z[1]) / s, Vec3{s / 4, (y[0] + x[1]) / s, (z[0] + x[2]) / s}}
+	case y[1] > z[2]:
+		s := float64(math.Sqrt(float64(1+y[1]-x[0]-z[2]))) * 2
+		return Quat{(z[0] - x[2]) / s, Vec3{(y[0] + x[1]) / s, s / 4, (z[1] + y[2]) / s}}
+	default:
+		s := float64(math.Sqrt(float64(1+z[2]-x[0]-y[1]))) * 2
+		return Quat{(x[1] - y[0]) / s, Vec3{(z[0] + x[2]) / s, (z[1] + y[2]) / s, s / 4}}
+	}
+}
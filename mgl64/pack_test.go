@@ -0,0 +1,190 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestHalf(t *testing.T) {
+	tests := []struct {
+		f float64
+		h uint16
+	}{
+		{0, 0}, {math.Copysign(0, -1), 0x8000}, {1, 0x3c00}, {-2, 0xc000}, {.1, 0x2e66},
+		{65504, 0x7bff}, {65519, 0x7bff}, {65520, 0x7c00}, {1e10, 0x7c00}, {math.Inf(-1), 0xfc00},
+		{math.Ldexp(1, -14), 0x0400}, {math.Ldexp(1, -24), 0x0001}, {math.Ldexp(1023, -24), 0x03ff},
+		// Ties round to even
+		{math.Ldexp(1, -25), 0}, {math.Ldexp(3, -25), 0x0002}, {math.Ldexp(1.5, -25), 0x0001},
+		{1 + math.Ldexp(1, -11), 0x3c00}, {1 + math.Ldexp(3, -11), 0x3c02}, {1e-10, 0},
+	}
+	for _, c := range tests {
+		if h := FloatToHalf(float64(c.f)); h != c.h {
+			t.Errorf("%v as a half is %#04x, expected %#04x", c.f, h, c.h)
+		}
+	}
+	if h := FloatToHalf(float64(math.NaN())); h&0x7c00 != 0x7c00 || h&0x3ff == 0 {
+		t.Errorf("NaN as a half is %#04x", h)
+	}
+
+	// Every half converts to a float and back exactly
+	for i := 0; i <= 0xffff; i++ {
+		h := uint16(i)
+		f := HalfToFloat(h)
+		if math.IsNaN(float64(f)) != (h&0x7c00 == 0x7c00 && h&0x3ff != 0) {
+			t.Errorf("Half %#04x is %v, which is wrongly NaN or not", h, f)
+		} else if back := FloatToHalf(f); back != h && !math.IsNaN(float64(f)) {
+			t.Errorf("Half %#04x is %v, which converts back to %#04x", h, f, back)
+		}
+	}
+
+	// Random floats round to the nearest half
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 10000; i++ {
+		f := float64(math.Ldexp(rand.Float64()*2-1, rand.Intn(42)-26))
+		h := FloatToHalf(f)
+		err := Abs(HalfToFloat(h) - f)
+		for _, neighbor := range []uint16{h - 1, h + 1} {
+			if neighbor&0x7fff < 0x7c00 && Abs(HalfToFloat(neighbor)-f) < err {
+				t.Errorf("%v as a half is %v, but %v is closer", f, HalfToFloat(h), HalfToFloat(neighbor))
+			}
+		}
+	}
+}
+
+func TestNormalizedPacking(t *testing.T) {
+	tests := []struct {
+		name             string
+		packed, expected uint64
+	}{
+		{"PackSnorm2x16", uint64(PackSnorm2x16(Vec2{-1, 1})), 0x7fff8001},
+		{"PackSnorm2x16 clamped", uint64(PackSnorm2x16(Vec2{2, -.25})), 0xe0007fff},
+		{"PackUnorm2x16", uint64(PackUnorm2x16(Vec2{.5, 1})), 0xffff8000},
+		{"PackUnorm4x8", uint64(PackUnorm4x8(Vec4{1, 0, .5, -3})), 0x008000ff},
+		{"PackSnorm4x8", uint64(PackSnorm4x8(Vec4{-1, 1, 0, -.25})), 0xe0007f81},
+		{"PackHalf2x16", uint64(PackHalf2x16(Vec2{1, -2})), 0xc0003c00},
+		{"PackHalf4x16", PackHalf4x16(Vec4{1, -2, 0, 1}), 0x3c000000c0003c00},
+		{"PackSnorm4x16", PackSnorm4x16(Vec4{0, 0, 1, -1}), 0x80017fff00000000},
+		{"PackUnorm4x16", PackUnorm4x16(Vec4{0, 1, 0, 1}), 0xffff0000ffff0000},
+		{"PackSnormRGB10A2", uint64(PackSnormRGB10A2(Vec4{1, -1, 0, -1})), 0xc00001ff | 0x201<<10},
+	}
+	for _, c := range tests {
+		if c.packed != c.expected {
+			t.Errorf("%s gives %#x, expected %#x", c.name, c.packed, c.expected)
+		}
+	}
+
+	// Every packed value unpacks and packs again to the same bits, except the smallest signed value, which is -1 like
+	// the next one
+	for i := uint32(0); i <= 0xffff; i++ {
+		p := i | (0xffff-i)<<16
+		if back := PackUnorm2x16(UnpackUnorm2x16(p)); back != p {
+			t.Errorf("Unorm %#x unpacks to %v, which packs to %#x", p, UnpackUnorm2x16(p), back)
+		}
+		if i == 0x8000 || 0xffff-i == 0x8000 {
+			continue
+		}
+		if back := PackSnorm2x16(UnpackSnorm2x16(p)); back != p {
+			t.Errorf("Snorm %#x unpacks to %v, which packs to %#x", p, UnpackSnorm2x16(p), back)
+		}
+		if wide := uint64(p) | uint64(p)<<32; PackSnorm4x16(UnpackSnorm4x16(wide)) != wide || PackUnorm4x16(UnpackUnorm4x16(wide)) != wide {
+			t.Errorf("Snorm or unorm %#x doesn't unpack and pack to the same bits", wide)
+		}
+	}
+	for i := uint32(0); i <= 0xff; i++ {
+		p := i | (i*7+1)&0xff<<8 | (0xff-i)<<16 | (i*13)&0xff<<24
+		if back := PackUnorm4x8(UnpackUnorm4x8(p)); back != p {
+			t.Errorf("Unorm %#x unpacks to %v, which packs to %#x", p, UnpackUnorm4x8(p), back)
+		}
+		if i == 0x80 {
+			continue
+		}
+		p = i | i<<8 | i<<16 | i<<24
+		if back := PackSnorm4x8(UnpackSnorm4x8(p)); back != p {
+			t.Errorf("Snorm %#x unpacks to %v, which packs to %#x", p, UnpackSnorm4x8(p), back)
+		}
+	}
+	for i := uint32(0); i < 1<<10; i++ {
+		if i == 0x200 {
+			continue
+		}
+		// The 2 bit w is -1, 0 or 1, not -2
+		p := i | i<<10 | i<<20 | []uint32{0, 1, 3}[i%3]<<30
+		if back := PackSnormRGB10A2(UnpackSnormRGB10A2(p)); back != p {
+			t.Errorf("Snorm %#x unpacks to %v, which packs to %#x", p, UnpackSnormRGB10A2(p), back)
+		}
+	}
+	if v := UnpackSnorm2x16(0x80008000); v != (Vec2{-1, -1}) {
+		t.Errorf("Smallest snorm unpacks to %v, expected -1", v)
+	}
+}
+
+func TestOctahedron(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	normals := []Vec3{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}, Vec3{1, -1, -1}.Normalize()}
+	for i := 0; i < 10000; i++ {
+		normals = append(normals, SquareToSphere(Vec2{rand.Float64(), rand.Float64()}))
+	}
+
+	var maxAngle float64
+	for _, n := range normals {
+		e := OctahedronEncode(n)
+		if d := OctahedronDecode(e); Abs(e[0]) > 1 || Abs(e[1]) > 1 || !elementsClose(d[:], n[:], 1e-5) {
+			t.Errorf("%v encodes to %v, which decodes to %v", n, e, d)
+		}
+
+		p := PackOctahedron2x16(n)
+		decoded := UnpackOctahedron2x16(p)
+		// The angle from the chord, which is more precise than from the dot product
+		maxAngle = math.Max(maxAngle, 2*math.Asin(float64(decoded.Sub(n).Len()/2)))
+		// Packing again gives the same bits, or on the seams of the octahedron, bits that decode to the same vector
+		if again := PackOctahedron2x16(decoded); again != p && UnpackOctahedron2x16(again) != decoded {
+			t.Errorf("%v packs to %#x, which unpacks to %v, which packs to %#x", n, p, decoded, again)
+		}
+	}
+
+	if degrees := maxAngle * 180 / math.Pi; degrees > .003 {
+		t.Errorf("Packed normals are up to %v degrees off", degrees)
+	}
+}
+
+func TestQTangent(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	type frame struct {
+		normal  Vec3
+		tangent Vec4
+	}
+	frames := []frame{
+		{Vec3{0, 0, 1}, Vec4{1, 0, 0, 1}},
+		{Vec3{0, 0, 1}, Vec4{1, 0, 0, -1}},
+		// Half turns, where W is 0
+		{Vec3{0, 0, -1}, Vec4{1, 0, 0, 1}},
+		{Vec3{0, 0, -1}, Vec4{1, 0, 0, -1}},
+		{Vec3{0, 1, 0}, Vec4{-1, 0, 0, -1}},
+	}
+	for i := 0; i < 1000; i++ {
+		q := randomQuat(rand)
+		frames = append(frames, frame{q.Rotate(Vec3{0, 0, 1}), q.Rotate(Vec3{1, 0, 0}).Vec4(float64(rand.Intn(2)*2 - 1))})
+	}
+
+	for _, f := range frames {
+		q := QTangent(f.normal, f.tangent)
+		for name, decode := range map[string]Quat{"QTangent": q, "Packed QTangent": UnpackQTangent(PackQTangent(q))} {
+			normal, tangent := QTangentFrame(decode)
+			if !elementsClose(normal[:], f.normal[:], 1e-4) || !elementsClose(tangent[:], f.tangent[:], 1e-4) {
+				t.Errorf("%s of normal %v and tangent %v is %v, which decodes to %v and %v", name, f.normal, f.tangent, decode, normal, tangent)
+			}
+		}
+	}
+
+	// The tangent is made perpendicular to the normal
+	if _, tangent := QTangentFrame(QTangent(Vec3{0, 0, 2}, Vec4{1, 0, 1, 1})); !elementsClose(tangent[:], []float64{1, 0, 0, 1}, 1e-6) {
+		t.Errorf("Tangent of a frame with a slanted tangent is %v, expected it made perpendicular", tangent)
+	}
+}
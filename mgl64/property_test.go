@@ -0,0 +1,232 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// The property tests check invariants that should hold for any input, on many random inputs, rather than comparing a
+// few hand computed values. The generators keep the inputs well conditioned, so the tolerances can be tight.
+
+const propertyIterations = 1000
+
+func randomVec3(rand *rand.Rand) Vec3 {
+	return Vec3{rand.Float64()*2 - 1, rand.Float64()*2 - 1, rand.Float64()*2 - 1}
+}
+
+func randomQuat(rand *rand.Rand) Quat {
+	return CubeToRotation(Vec3{rand.Float64(), rand.Float64(), rand.Float64()})
+}
+
+// randomMatrix fills an n by n matrix (column major, like Mat2, Mat3 and Mat4) with entries in [-1,1], plus n or -n on
+// the diagonal. The matrix is strictly diagonally dominant, so its condition number is small.
+func randomMatrix(rand *rand.Rand, m []float64, n int) {
+	for i := range m {
+		m[i] = rand.Float64()*2 - 1
+	}
+	for i := 0; i < n; i++ {
+		if rand.Intn(2) == 0 {
+			m[i*n+i] += float64(n)
+		} else {
+			m[i*n+i] -= float64(n)
+		}
+	}
+}
+
+func randomMat2(rand *rand.Rand) (m Mat2) {
+	randomMatrix(rand, m[:], 2)
+	return m
+}
+
+func randomMat3(rand *rand.Rand) (m Mat3) {
+	randomMatrix(rand, m[:], 3)
+	return m
+}
+
+func randomMat4(rand *rand.Rand) (m Mat4) {
+	randomMatrix(rand, m[:], 4)
+	return m
+}
+
+// elementsClose is true if every element of a is within tolerance of b's. Unlike ApproxEqualThreshold, the tolerance is
+// absolute, which suits elements that should be zero.
+func elementsClose(a, b []float64, tolerance float64) bool {
+	for i := range a {
+		if Abs(a[i]-b[i]) > tolerance {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameRotation is true if q1 and q2 are the same rotation, which is when they're equal or opposite.
+func sameRotation(q1, q2 Quat, tolerance float64) bool {
+	return Abs(Abs(q1.Normalize().Dot(q2.Normalize()))-1) <= tolerance
+}
+
+func TestPropertyInverse(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ident2, ident3, ident4 := Ident2(), Ident3(), Ident4()
+
+	for i := 0; i < propertyIterations; i++ {
+		m2 := randomMat2(rand)
+		if p := m2.Mul2(m2.Inv()); !elementsClose(p[:], ident2[:], 1e-5) {
+			t.Errorf("%v times its inverse is %v, expected identity", m2, p)
+		}
+		if p := m2.Inv().Mul2(m2); !elementsClose(p[:], ident2[:], 1e-5) {
+			t.Errorf("Inverse of %v times it is %v, expected identity", m2, p)
+		}
+
+		m3 := randomMat3(rand)
+		if p := m3.Mul3(m3.Inv()); !elementsClose(p[:], ident3[:], 1e-5) {
+			t.Errorf("%v times its inverse is %v, expected identity", m3, p)
+		}
+		if p := m3.Inv().Mul3(m3); !elementsClose(p[:], ident3[:], 1e-5) {
+			t.Errorf("Inverse of %v times it is %v, expected identity", m3, p)
+		}
+
+		m4 := randomMat4(rand)
+		if p := m4.Mul4(m4.Inv()); !elementsClose(p[:], ident4[:], 1e-5) {
+			t.Errorf("%v times its inverse is %v, expected identity", m4, p)
+		}
+		if p := m4.Inv().Mul4(m4); !elementsClose(p[:], ident4[:], 1e-5) {
+			t.Errorf("Inverse of %v times it is %v, expected identity", m4, p)
+		}
+		if twice := m4.Inv().Inv(); !elementsClose(twice[:], m4[:], 1e-5) {
+			t.Errorf("Inverse of the inverse of %v is %v", m4, twice)
+		}
+		if det, invDet := m4.Det(), m4.Inv().Det(); !FloatEqualThreshold(det*invDet, 1, 1e-5) {
+			t.Errorf("Determinant of %v is %v, and of its inverse %v, expected reciprocals", m4, det, invDet)
+		}
+	}
+}
+
+func TestPropertyTranspose(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < propertyIterations; i++ {
+		a, b := randomMat4(rand), randomMat4(rand)
+		if a.Transpose().Transpose() != a {
+			t.Errorf("Transposing %v twice gives %v", a, a.Transpose().Transpose())
+		}
+		if ab, ba := a.Mul4(b).Transpose(), b.Transpose().Mul4(a.Transpose()); !elementsClose(ab[:], ba[:], 1e-5) {
+			t.Errorf("Transpose of %v * %v is %v, expected %v", a, b, ab, ba)
+		}
+		if det, product := a.Mul4(b).Det(), a.Det()*b.Det(); !FloatEqualThreshold(det, product, 1e-5) {
+			t.Errorf("Determinant of %v * %v is %v, expected the product of their determinants %v", a, b, det, product)
+		}
+
+		m3 := randomMat3(rand)
+		if m3.Transpose().Transpose() != m3 {
+			t.Errorf("Transposing %v twice gives %v", m3, m3.Transpose().Transpose())
+		}
+		if !FloatEqualThreshold(m3.Det(), m3.Transpose().Det(), 1e-5) {
+			t.Errorf("Determinant of %v is %v, but of its transpose %v", m3, m3.Det(), m3.Transpose().Det())
+		}
+	}
+}
+
+func TestPropertyQuat(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < propertyIterations; i++ {
+		q, r := randomQuat(rand), randomQuat(rand)
+		v := randomVec3(rand)
+
+		if !FloatEqualThreshold(q.Len(), 1, 1e-5) {
+			t.Errorf("Random rotation %v isn't normalized", q)
+		}
+
+		rotated := q.Rotate(v)
+		if byMatrix := q.Mat4().Mul4x1(v.Vec4(0)).Vec3(); !elementsClose(byMatrix[:], rotated[:], 1e-5) {
+			t.Errorf("%v rotated by %v is %v, but by its matrix %v", v, q, rotated, byMatrix)
+		}
+		if byConjugate := q.Mul(Quat{0, v}).Mul(q.Conjugate()).V; !elementsClose(byConjugate[:], rotated[:], 1e-5) {
+			t.Errorf("%v rotated by %v is %v, but by conjugation %v", v, q, rotated, byConjugate)
+		}
+		if !FloatEqualThreshold(rotated.Len(), v.Len(), 1e-5) {
+			t.Errorf("Rotating %v by %v changes its length to %v", v, q, rotated.Len())
+		}
+
+		if composed, nested := q.Mul(r).Rotate(v), q.Rotate(r.Rotate(v)); !elementsClose(composed[:], nested[:], 1e-5) {
+			t.Errorf("%v rotated by %v * %v is %v, expected %v", v, q, r, composed, nested)
+		}
+		if back := q.Inverse().Rotate(rotated); !elementsClose(back[:], v[:], 1e-5) {
+			t.Errorf("%v rotated by %v and back is %v", v, q, back)
+		}
+		if !FloatEqualThreshold(q.Dot(r), r.Dot(q), 1e-6) || !FloatEqualThreshold(q.Dot(q), 1, 1e-5) {
+			t.Errorf("Dot products of %v and %v are wrong", q, r)
+		}
+	}
+}
+
+func TestPropertySlerp(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < propertyIterations; i++ {
+		q1, q2 := randomQuat(rand), randomQuat(rand)
+		if i%10 == 0 {
+			// Nearly equal rotations
+			q2 = QuatRotate(rand.Float64()*1e-3, randomVec3(rand).Normalize()).Mul(q1)
+		}
+
+		for _, slerp := range []struct {
+			name string
+			f    func(q1, q2 Quat, amount float64) Quat
+		}{{"Slerp", QuatSlerp}, {"Nlerp", QuatNlerp}} {
+			if start := slerp.f(q1, q2, 0); !sameRotation(start, q1, 1e-5) {
+				t.Errorf("%s from %v to %v starts at %v", slerp.name, q1, q2, start)
+			}
+			if end := slerp.f(q1, q2, 1); !sameRotation(end, q2, 1e-5) {
+				t.Errorf("%s from %v to %v ends at %v", slerp.name, q1, q2, end)
+			}
+			if mid := slerp.f(q1, q2, rand.Float64()); !FloatEqualThreshold(mid.Len(), 1, 1e-5) {
+				t.Errorf("%s from %v to %v isn't normalized: %v", slerp.name, q1, q2, mid)
+			}
+		}
+
+		// Slerp moves at a constant angular speed, so the middle is equally far from both ends
+		amount := rand.Float64()
+		q := QuatSlerp(q1, q2, amount)
+		if total, first := q1.Dot(q2), q1.Dot(q); total > 0 && total < 1-1e-3 {
+			expected := float64(math.Acos(float64(total))) * amount
+			if angle := float64(math.Acos(float64(Clamp(first, -1, 1)))); Abs(angle-expected) > 1e-3 {
+				t.Errorf("Slerp from %v to %v by %v is %v from the start, expected %v", q1, q2, amount, angle, expected)
+			}
+		}
+	}
+}
+
+func TestPropertyAnglesToQuat(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < propertyIterations; i++ {
+		angles := Vec3{rand.Float64()*8 - 4, rand.Float64()*8 - 4, rand.Float64()*8 - 4}
+		order := RotationOrder(rand.Intn(12))
+
+		q := AnglesToQuat(angles[0], angles[1], angles[2], order)
+		if expected := composeAngles(angles, order); !sameRotation(q, expected, 1e-5) {
+			t.Errorf("Angles %v in order %v give %v, expected %v", angles, order, q, expected)
+		}
+	}
+}
+
+// composeAngles returns the rotation AnglesToQuat should give, by multiplying a rotation about each axis in turn.
+func composeAngles(angles Vec3, order RotationOrder) Quat {
+	name := [...]string{"XYX", "XYZ", "XZX", "XZY", "YXY", "YXZ", "YZY", "YZX", "ZYZ", "ZYX", "ZXZ", "ZXY"}[order]
+	axes := map[rune]Vec3{'X': {1, 0, 0}, 'Y': {0, 1, 0}, 'Z': {0, 0, 1}}
+
+	q := QuatIdent()
+	for i, axis := range name {
+		q = q.Mul(QuatRotate(angles[i], axes[axis]))
+	}
+
+	return q
+}
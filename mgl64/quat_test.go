@@ -86,6 +86,28 @@ func TestQuatRotationToMatrix(t *testing.T) {
 	}
 }
 
+func TestMat4ToQuat(t *testing.T) {
+	// Rotations by less and more than a half turn, and about each axis, exercise each case of the conversion
+	for _, c := range []struct {
+		angle float64
+		axis  Vec3
+	}{
+		{0, Vec3{0, 0, 1}},
+		{1, Vec3{1, 2, 3}},
+		{3, Vec3{1, .1, .2}},
+		{3, Vec3{.1, 1, .2}},
+		{3, Vec3{.1, .2, 1}},
+		{math.Pi, Vec3{0, 1, 0}},
+	} {
+		q := QuatRotate(c.angle, c.axis.Normalize())
+		// q and -q are the same rotation
+		result := Mat4ToQuat(q.Mat4())
+		if !result.ApproxEqualThreshold(q, 1e-4) && !result.ApproxEqualThreshold(q.Scale(-1), 1e-4) {
+			t.Errorf("Quaternion of the matrix of %v is %v", q, result)
+		}
+	}
+}
+
 // Taken from the Matlab AnglesToQuat documentation example
 func TestAnglesToQuatZYX(t *testing.T) {
 	q := AnglesToQuat(.7854, 0.1, 0, ZYX)
@@ -0,0 +1,245 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// The SquareTo functions warp uniformly distributed points in the unit square [0,1)² to other shapes, keeping them
+// uniformly distributed (or distributed as stated). Feed them with a Sampler for random points, or with a low
+// discrepancy sequence such as Sobol2 for evenly spread points that converge faster in Monte Carlo integration.
+
+// SquareToCircle maps u to a point on the unit circle, using only u[0].
+func SquareToCircle(u Vec2) Vec2 {
+	s, c := math.Sincos(2 * math.Pi * float64(u[0]))
+	return Vec2{float64(c), float64(s)}
+}
+
+// SquareToDisk maps u to a point in the unit disk, with Peter Shirley and Kenneth Chiu's concentric mapping, which keeps
+// nearby points nearby and so preserves the stratification of the input.
+func SquareToDisk(u Vec2) Vec2 {
+	x, y := 2*u[0]-1, 2*u[1]-1
+	if x == 0 && y == 0 {
+		return Vec2{}
+	}
+
+	var r, theta float64
+	if Abs(x) > Abs(y) {
+		r, theta = x, math.Pi/4*(y/x)
+	} else {
+		r, theta = y, math.Pi/2-math.Pi/4*(x/y)
+	}
+
+	s, c := math.Sincos(float64(theta))
+	return Vec2{r * float64(c), r * float64(s)}
+}
+
+// SquareToSphere maps u to a point on the unit sphere.
+func SquareToSphere(u Vec2) Vec3 {
+	z := 1 - 2*u[1]
+	r := float64(math.Sqrt(math.Max(0, float64(1-z*z))))
+	return SquareToCircle(u).Mul(r).Vec3(z)
+}
+
+// SquareToHemisphere maps u to a point on the unit hemisphere around +Z.
+func SquareToHemisphere(u Vec2) Vec3 {
+	z := 1 - u[1]
+	r := float64(math.Sqrt(math.Max(0, float64(1-z*z))))
+	return SquareToCircle(u).Mul(r).Vec3(z)
+}
+
+// SquareToCosineHemisphere maps u to a point on the unit hemisphere around +Z, with a density proportional to the cosine
+// of the angle from +Z (cos/π), which is the ideal distribution for sampling diffuse reflection.
+func SquareToCosineHemisphere(u Vec2) Vec3 {
+	// Project points uniform in the disk up to the hemisphere
+	d := SquareToDisk(u)
+	return d.Vec3(float64(math.Sqrt(math.Max(0, float64(1-d.Dot(d))))))
+}
+
+// SquareToTriangle maps u to barycentric coordinates (v, w) of a point in a triangle, uniformly distributed over its
+// area. The point is a + v(b-a) + w(c-a), as with Triangle.Barycentric.
+func SquareToTriangle(u Vec2) Vec2 {
+	// Fold the square's upper half onto the lower half
+	if u[0]+u[1] > 1 {
+		return Vec2{1 - u[0], 1 - u[1]}
+	}
+	return u
+}
+
+// CubeToRotation maps u in the unit cube [0,1)³ to a rotation, with Ken Shoemake's method. Rotations made from uniform
+// points are uniformly distributed.
+func CubeToRotation(u Vec3) Quat {
+	r1, r2 := math.Sqrt(float64(1-u[0])), math.Sqrt(float64(u[0]))
+	s1, c1 := math.Sincos(2 * math.Pi * float64(u[1]))
+	s2, c2 := math.Sincos(2 * math.Pi * float64(u[2]))
+
+	return Quat{float64(r2 * c2), Vec3{float64(r1 * s1), float64(r1 * c1), float64(r2 * s2)}}
+}
+
+// Sampler generates random points in various shapes, determined only by its seed.
+type Sampler struct {
+	rand *rand.Rand
+}
+
+// NewSampler creates a Sampler from a seed.
+func NewSampler(seed int64) *Sampler {
+	return &Sampler{rand.New(rand.NewSource(seed))}
+}
+
+// Uniform returns a random number in [0,1).
+func (s *Sampler) Uniform() float64 {
+	return float64(s.rand.Float64())
+}
+
+// Square returns a random point in the unit square [0,1)².
+func (s *Sampler) Square() Vec2 {
+	return Vec2{s.Uniform(), s.Uniform()}
+}
+
+// OnCircle returns a random point on the unit circle.
+func (s *Sampler) OnCircle() Vec2 {
+	return SquareToCircle(s.Square())
+}
+
+// InDisk returns a random point in the unit disk.
+func (s *Sampler) InDisk() Vec2 {
+	return SquareToDisk(s.Square())
+}
+
+// OnSphere returns a random point on the unit sphere, which is also a random direction.
+func (s *Sampler) OnSphere() Vec3 {
+	return SquareToSphere(s.Square())
+}
+
+// InSphere returns a random point in the unit ball.
+func (s *Sampler) InSphere() Vec3 {
+	return s.OnSphere().Mul(float64(math.Cbrt(float64(s.Uniform()))))
+}
+
+// OnHemisphere returns a random point on the unit hemisphere around normal, which must be normalized.
+func (s *Sampler) OnHemisphere(normal Vec3) Vec3 {
+	return toNormalSpace(SquareToHemisphere(s.Square()), normal)
+}
+
+// OnCosineHemisphere returns a random point on the unit hemisphere around normal, which must be normalized, with the
+// cosine weighted distribution of SquareToCosineHemisphere.
+func (s *Sampler) OnCosineHemisphere(normal Vec3) Vec3 {
+	return toNormalSpace(SquareToCosineHemisphere(s.Square()), normal)
+}
+
+// toNormalSpace rotates v from around +Z to around normal.
+func toNormalSpace(v, normal Vec3) Vec3 {
+	tangent := anyPerpendicular(normal)
+	bitangent := normal.Cross(tangent)
+	return tangent.Mul(v[0]).Add(bitangent.Mul(v[1])).Add(normal.Mul(v[2]))
+}
+
+// InTriangle returns a random point in a triangle.
+func (s *Sampler) InTriangle(t Triangle) Vec3 {
+	b := SquareToTriangle(s.Square())
+	return t[0].Add(t[1].Sub(t[0]).Mul(b[0])).Add(t[2].Sub(t[0]).Mul(b[1]))
+}
+
+// InAABB returns a random point in a box.
+func (s *Sampler) InAABB(b AABB) Vec3 {
+	size := b.Size()
+	return b.Min.Add(Vec3{size[0] * s.Uniform(), size[1] * s.Uniform(), size[2] * s.Uniform()})
+}
+
+// Rotation returns a uniformly distributed random rotation.
+func (s *Sampler) Rotation() Quat {
+	return CubeToRotation(Vec3{s.Uniform(), s.Uniform(), s.Uniform()})
+}
+
+// belowOne keeps x in [0,1) after rounding to float32.
+func belowOne(x float64) float64 {
+	if f := float64(x); f < 1 {
+		return f
+	}
+	return float64(math.Nextafter32(1, 0))
+}
+
+// RadicalInverse mirrors the digits of index in the given base around the radix point, so 6 in base 2 (110) becomes
+// 0.011 (0.375). It is the van der Corput sequence in that base, and base must be at least 2.
+func RadicalInverse(index, base int) float64 {
+	if base == 2 {
+		return belowOne(float64(bits.Reverse64(uint64(index))) / (1 << 64))
+	}
+
+	var result float64
+	scale := 1 / float64(base)
+	for ; index > 0; index /= base {
+		result += float64(index%base) * scale
+		scale /= float64(base)
+	}
+
+	return belowOne(result)
+}
+
+// Halton2 returns the point at index of the two dimensional Halton sequence, which uses bases 2 and 3. Unlike Hammersley
+// points, the sequence can be extended indefinitely.
+func Halton2(index int) Vec2 {
+	return Vec2{RadicalInverse(index, 2), RadicalInverse(index, 3)}
+}
+
+// Halton3 returns the point at index of the three dimensional Halton sequence, which uses bases 2, 3 and 5.
+func Halton3(index int) Vec3 {
+	return Vec3{RadicalInverse(index, 2), RadicalInverse(index, 3), RadicalInverse(index, 5)}
+}
+
+// Hammersley2 returns the point at index of a set of n two dimensional Hammersley points, which are spread more evenly
+// than the Halton sequence but only as a set of a known size.
+func Hammersley2(index, n int) Vec2 {
+	return Vec2{float64(index) / float64(n), RadicalInverse(index, 2)}
+}
+
+// sobolDirections holds the direction numbers of the first three dimensions of the Sobol sequence, from the primitive
+// polynomials and initial numbers of Stephen Joe and Frances Kuo.
+var sobolDirections = func() [3][32]uint32 {
+	var v [3][32]uint32
+	for k := range v[0] {
+		v[0][k] = 1 << uint(31-k)
+	}
+
+	// x + 1, with m1 = 1
+	v[1][0] = 1 << 31
+	for k := 1; k < 32; k++ {
+		v[1][k] = v[1][k-1] ^ v[1][k-1]>>1
+	}
+
+	// x² + x + 1, with m1 = 1 and m2 = 3
+	v[2][0], v[2][1] = 1<<31, 3<<30
+	for k := 2; k < 32; k++ {
+		v[2][k] = v[2][k-2] ^ v[2][k-2]>>2 ^ v[2][k-1]
+	}
+
+	return v
+}()
+
+// sobol returns a component of the Sobol point at index.
+func sobol(index uint32, dim int) float64 {
+	var x uint32
+	for k := 0; index != 0; k, index = k+1, index>>1 {
+		if index&1 != 0 {
+			x ^= sobolDirections[dim][k]
+		}
+	}
+
+	return belowOne(float64(x) / (1 << 32))
+}
+
+// Sobol2 returns the point at index of the two dimensional Sobol sequence. Every power of two run of points starting
+// at index 0 is a (0,m,2)-net: any box [a/2^i, (a+1)/2^i) × [b/2^j, (b+1)/2^j) with the area of one point holds exactly one.
+func Sobol2(index uint32) Vec2 {
+	return Vec2{sobol(index, 0), sobol(index, 1)}
+}
+
+// Sobol3 returns the point at index of the three dimensional Sobol sequence.
+func Sobol3(index uint32) Vec3 {
+	return Vec3{sobol(index, 0), sobol(index, 1), sobol(index, 2)}
+}
@@ -0,0 +1,155 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSamplerDeterministic(t *testing.T) {
+	a, b := NewSampler(7), NewSampler(7)
+	for i := 0; i < 100; i++ {
+		if p, q := a.OnSphere(), b.OnSphere(); p != q {
+			t.Fatalf("Samplers with the same seed differ at %d: %v and %v", i, p, q)
+		}
+	}
+}
+
+func TestSamplerShapes(t *testing.T) {
+	s := NewSampler(time.Now().UnixNano())
+	const n = 20000
+	normal := Vec3{1, 2, -2}.Normalize()
+	tri := Triangle{{1, 0, 0}, {3, 1, 0}, {0, 4, 2}}
+	box := AABB{Vec3{-1, 2, 3}, Vec3{0, 5, 3.5}}
+
+	var sphereMean, triangleMean Vec3
+	var innerDisk, innerBall int
+	var hemisphereCos, cosineCos float64
+	var rotated Vec3
+	for i := 0; i < n; i++ {
+		if p := s.OnCircle(); !FloatEqualThreshold(p.Len(), 1, 1e-5) {
+			t.Errorf("Point on circle %v has length %v", p, p.Len())
+		}
+
+		d := s.InDisk()
+		if d.Len() > 1+1e-6 {
+			t.Errorf("Point in disk %v has length %v", d, d.Len())
+		}
+		if d.Len() < .5 {
+			innerDisk++
+		}
+
+		p := s.OnSphere()
+		if !FloatEqualThreshold(p.Len(), 1, 1e-5) {
+			t.Errorf("Point on sphere %v has length %v", p, p.Len())
+		}
+		sphereMean = sphereMean.Add(p)
+
+		if b := s.InSphere(); b.Len() > 1+1e-6 {
+			t.Errorf("Point in sphere %v has length %v", b, b.Len())
+		} else if b.Len() < .5 {
+			innerBall++
+		}
+
+		h, c := s.OnHemisphere(normal), s.OnCosineHemisphere(normal)
+		if h.Dot(normal) < 0 || c.Dot(normal) < 0 || !FloatEqualThreshold(h.Len(), 1, 1e-5) || !FloatEqualThreshold(c.Len(), 1, 1e-5) {
+			t.Errorf("Points %v and %v aren't on the hemisphere around %v", h, c, normal)
+		}
+		hemisphereCos += h.Dot(normal)
+		cosineCos += c.Dot(normal)
+
+		q := s.InTriangle(tri)
+		if u, v, w := tri.Barycentric(q); u < -1e-5 || v < -1e-5 || w < -1e-5 {
+			t.Errorf("Point %v isn't in triangle %v", q, tri)
+		}
+		triangleMean = triangleMean.Add(q)
+
+		if q := s.InAABB(box); !box.Contains(q) {
+			t.Errorf("Point %v isn't in box %v", q, box)
+		}
+
+		r := s.Rotation()
+		if !FloatEqualThreshold(r.Len(), 1, 1e-5) {
+			t.Errorf("Random rotation %v isn't normalized", r)
+		}
+		rotated = rotated.Add(r.Rotate(Vec3{0, 0, 1}))
+	}
+
+	// Statistical checks, several standard deviations wide
+	expectations := []struct {
+		name            string
+		value, expected float64
+	}{
+		{"Fraction of disk points within .5", float64(innerDisk) / n, .25},
+		{"Fraction of ball points within .5", float64(innerBall) / n, .125},
+		{"Mean cosine on hemisphere", hemisphereCos / n, .5},
+		{"Mean cosine on cosine weighted hemisphere", cosineCos / n, 2. / 3},
+	}
+	for _, e := range expectations {
+		if Abs(e.value-e.expected) > .02 {
+			t.Errorf("%s is %v, expected %v", e.name, e.value, e.expected)
+		}
+	}
+	if mean := sphereMean.Mul(1. / n); mean.Len() > .03 {
+		t.Errorf("Mean of points on sphere is %v, expected 0", mean)
+	}
+	if mean := rotated.Mul(1. / n); mean.Len() > .03 {
+		t.Errorf("Mean of randomly rotated vectors is %v, expected 0", mean)
+	}
+	centroid := tri[0].Add(tri[1]).Add(tri[2]).Mul(1. / 3)
+	if mean := triangleMean.Mul(1. / n); mean.Sub(centroid).Len() > .05 {
+		t.Errorf("Mean of points in triangle is %v, expected its centroid %v", mean, centroid)
+	}
+}
+
+func TestLowDiscrepancySequences(t *testing.T) {
+	inverses := []struct {
+		index, base int
+		expected    float64
+	}{
+		{0, 2, 0}, {1, 2, .5}, {2, 2, .25}, {3, 2, .75}, {6, 2, .375},
+		{1, 3, 1. / 3}, {2, 3, 2. / 3}, {3, 3, 1. / 9}, {5, 3, 7. / 9},
+		{1, 5, .2}, {5, 5, .04},
+	}
+	for _, c := range inverses {
+		if r := RadicalInverse(c.index, c.base); !FloatEqual(r, c.expected) {
+			t.Errorf("Radical inverse of %d in base %d is %v, expected %v", c.index, c.base, r, c.expected)
+		}
+	}
+	if h := Halton3(5); !h.ApproxEqual(Vec3{.625, 7. / 9, .04}) {
+		t.Errorf("Halton point 5 is %v, expected %v", h, Vec3{.625, 7. / 9, .04})
+	}
+
+	sobol := []Vec2{{0, 0}, {.5, .5}, {.25, .75}, {.75, .25}, {.125, .625}}
+	for i, expected := range sobol {
+		if p := Sobol2(uint32(i)); p != expected {
+			t.Errorf("Sobol point %d is %v, expected %v", i, p, expected)
+		}
+	}
+	if p := Sobol3(math.MaxUint32); p[0] >= 1 || p[1] >= 1 || p[2] >= 1 {
+		t.Errorf("Last Sobol point %v isn't in the unit cube", p)
+	}
+
+	// Check that 256 points are a (0,8,2)-net: every box of area 1/256 with power of two sides holds exactly one
+	const m = 8
+	sets := map[string]func(i int) Vec2{
+		"Sobol":      func(i int) Vec2 { return Sobol2(uint32(i)) },
+		"Hammersley": func(i int) Vec2 { return Hammersley2(i, 1<<m) },
+	}
+	for name, point := range sets {
+		for i := uint(0); i <= m; i++ {
+			counts := make(map[[2]int]int)
+			for j := 0; j < 1<<m; j++ {
+				p := point(j)
+				counts[[2]int{int(p[0] * float64(int(1)<<i)), int(p[1] * float64(int(1)<<(m-i)))}]++
+			}
+			if len(counts) != 1<<m {
+				t.Errorf("%s points don't fill the %d by %d grid of boxes", name, 1<<i, 1<<(m-i))
+			}
+		}
+	}
+}
@@ -252,6 +252,8 @@ func BezierSplineInterpolate3D(t float64, ranges [][2]float64, cPoints [][]Vec3)
 //
 // For the overly serious: the function is just for fun. It does nothing except prints a Maxis reference. Technically you could "reticulate splines"
 // by joining a bunch of splines together, but that ruins the joke.
+//
+// This function is deprecated. To actually join bezier curves together, use NewBezierSpline2D
 func ReticulateSplines(ranges [][][2]float64, cPoints [][][]Vec2, withLlamas bool) {
 	if !withLlamas {
 		fmt.Println("You can't reticulate splines without llamas, silly.")
@@ -296,9 +298,10 @@ func choose(n, k int) (result int) {
 	} else if n == 0 {
 		return 0
 	}
-	result = (n - (k - 1))
-	for i := 2; i <= k; i++ {
-		result *= (n - (k - i)) / i
+	result = 1
+	for i := 1; i <= k; i++ {
+		// The product of i consecutive integers is always divisible by i!, so this never truncates
+		result = result * (n - (k - i)) / i
 	}
 
 	return result
@@ -0,0 +1,246 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"fmt"
+)
+
+// JointTransform is the transform of a joint relative to its parent: it scales, then rotates, then translates.
+type JointTransform struct {
+	Translation Vec3
+	Rotation    Quat
+	Scale       Vec3
+}
+
+// IdentJointTransform returns the transform that leaves a joint where its parent is.
+func IdentJointTransform() JointTransform {
+	return JointTransform{Rotation: QuatIdent(), Scale: Vec3{1, 1, 1}}
+}
+
+// Mat4 returns the transform as a matrix.
+func (t JointTransform) Mat4() Mat4 {
+	m := t.Rotation.Normalize().Mat4()
+	for i := 0; i < 3; i++ {
+		m.SetCol(i, m.Col(i).Mul(t.Scale[i]))
+	}
+	m.SetCol(3, t.Translation.Vec4(1))
+	return m
+}
+
+// JointTransformLerp interpolates between two transforms, linearly for their translations and scales and with
+// QuatNlerp along the shortest path for their rotations.
+func JointTransformLerp(t1, t2 JointTransform, amount float64) JointTransform {
+	r2 := t2.Rotation
+	if t1.Rotation.Dot(r2) < 0 {
+		r2 = r2.Scale(-1)
+	}
+	return JointTransform{
+		Translation: t1.Translation.Add(t2.Translation.Sub(t1.Translation).Mul(amount)),
+		Rotation:    QuatNlerp(t1.Rotation, r2, amount),
+		Scale:       t1.Scale.Add(t2.Scale.Sub(t1.Scale).Mul(amount)),
+	}
+}
+
+// Joint is a joint, or bone, of a skeleton.
+type Joint struct {
+	Name   string
+	Parent int // The index of the parent joint, which comes before this one, or -1 for a root
+
+	// Rest is the joint's transform relative to its parent in the pose the mesh was modeled in.
+	Rest JointTransform
+
+	// InverseBind takes model space to the joint's space in the rest pose.
+	InverseBind Mat4
+}
+
+// Skeleton is a hierarchy of joints, with parents before their children.
+type Skeleton struct {
+	Joints []Joint
+}
+
+// NewSkeleton creates a skeleton of joints and sets their inverse bind matrices from their rest transforms. To use
+// inverse bind matrices from a file instead, set them afterwards.
+func NewSkeleton(joints []Joint) (*Skeleton, error) {
+	s := &Skeleton{Joints: joints}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	transforms := s.ModelTransforms(s.RestPose(), nil)
+	for i := range s.Joints {
+		s.Joints[i].InverseBind = transforms[i].Inv()
+	}
+
+	return s, nil
+}
+
+// Validate checks that each joint's parent comes before it.
+func (s *Skeleton) Validate() error {
+	for i, j := range s.Joints {
+		if j.Parent < -1 || j.Parent >= i {
+			return fmt.Errorf("joint %d has parent %d, which doesn't come before it", i, j.Parent)
+		}
+	}
+	return nil
+}
+
+// Find returns the index of the first joint named name, or -1 if there isn't one.
+func (s *Skeleton) Find(name string) int {
+	for i, j := range s.Joints {
+		if j.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// RestPose returns a new pose with the joints' rest transforms.
+func (s *Skeleton) RestPose() Pose {
+	p := make(Pose, len(s.Joints))
+	for i, j := range s.Joints {
+		p[i] = j.Rest
+	}
+	return p
+}
+
+// ModelTransforms appends the transform of each joint of the pose, from the joint's space to model space, to dst and
+// returns the result.
+func (s *Skeleton) ModelTransforms(p Pose, dst []Mat4) []Mat4 {
+	start := len(dst)
+	for i, j := range s.Joints {
+		m := p[i].Mat4()
+		if j.Parent >= 0 {
+			m = dst[start+j.Parent].Mul4(m)
+		}
+		dst = append(dst, m)
+	}
+	return dst
+}
+
+// Palette appends each joint's skinning matrix for the pose, which takes a vertex of the mesh in its rest pose to
+// where the joint's movement takes it, to dst and returns the result. This is the array of matrices a skinning shader
+// uses.
+func (s *Skeleton) Palette(p Pose, dst []Mat4) []Mat4 {
+	start := len(dst)
+	dst = s.ModelTransforms(p, dst)
+	for i, j := range s.Joints {
+		dst[start+i] = dst[start+i].Mul4(j.InverseBind)
+	}
+	return dst
+}
+
+// Pose holds a transform for each joint of a skeleton. Its methods set it to combinations of other poses of the same
+// skeleton, which may include itself.
+type Pose []JointTransform
+
+// Blend sets p to the interpolation between p1 and p2 with JointTransformLerp.
+func (p Pose) Blend(p1, p2 Pose, amount float64) {
+	for i := range p {
+		p[i] = JointTransformLerp(p1[i], p2[i], amount)
+	}
+}
+
+// BlendJoints sets p to the interpolation between p1 and p2 with a separate amount for each joint, for blending only
+// part of the skeleton, like the upper body.
+func (p Pose) BlendJoints(p1, p2 Pose, amounts []float64) {
+	for i := range p {
+		p[i] = JointTransformLerp(p1[i], p2[i], amounts[i])
+	}
+}
+
+// Difference sets p to an additive layer that turns reference into pose: it holds the translation to add to each
+// joint, the rotation to apply after the joint's own, and the factor to scale it by.
+func (p Pose) Difference(pose, reference Pose) {
+	for i := range p {
+		t, ref := pose[i], reference[i]
+		p[i] = JointTransform{
+			Translation: t.Translation.Sub(ref.Translation),
+			Rotation:    ref.Rotation.Inverse().Mul(t.Rotation).Normalize(),
+			Scale:       Vec3{t.Scale[0] / ref.Scale[0], t.Scale[1] / ref.Scale[1], t.Scale[2] / ref.Scale[2]},
+		}
+	}
+}
+
+// AddLayer sets p to base with an additive layer made by Difference applied on top, by amount. At 0 it's base, and
+// at 1 the layer's full change is added to each joint.
+func (p Pose) AddLayer(base, layer Pose, amount float64) {
+	for i := range p {
+		t, l := base[i], layer[i]
+		r := l.Rotation
+		if r.W < 0 {
+			r = r.Scale(-1)
+		}
+		scale := Vec3{1, 1, 1}.Add(l.Scale.Sub(Vec3{1, 1, 1}).Mul(amount))
+		p[i] = JointTransform{
+			Translation: t.Translation.Add(l.Translation.Mul(amount)),
+			Rotation:    t.Rotation.Mul(QuatNlerp(QuatIdent(), r, amount)).Normalize(),
+			Scale:       Vec3{t.Scale[0] * scale[0], t.Scale[1] * scale[1], t.Scale[2] * scale[2]},
+		}
+	}
+}
+
+// SkinWeights holds the joints that move each vertex of a mesh, as indices into a skeleton's joints, and how much
+// each one does. Each vertex's weights are normalized when it's skinned; a vertex whose weights are all 0 stays put.
+type SkinWeights struct {
+	Joints  [][4]uint16
+	Weights []Vec4
+}
+
+// Matrix returns the skinning matrix of vertex v: the weighted sum of its joints' matrices from the palette.
+func (w *SkinWeights) Matrix(palette []Mat4, v int) Mat4 {
+	weights := w.Weights[v]
+	sum := weights[0] + weights[1] + weights[2] + weights[3]
+	if sum == 0 {
+		return Ident4()
+	}
+
+	var m Mat4
+	for k, joint := range w.Joints[v] {
+		if weights[k] != 0 {
+			m = m.Add(palette[joint].Mul(weights[k] / sum))
+		}
+	}
+	return m
+}
+
+// Skin sets the positions, normals and tangents of dst to those of src moved by linear blend skinning with the
+// palette, reusing dst's slices if they're long enough, and shares src's UVs and indices with it. w must have joints
+// and weights for each vertex. Normals and tangents are transformed by the blended matrix and normalized, which
+// skews them if joints scale non-uniformly.
+func (w *SkinWeights) Skin(palette []Mat4, src, dst *Mesh) {
+	dst.Positions = resizeVec3s(dst.Positions, len(src.Positions))
+	dst.Normals = resizeVec3s(dst.Normals, len(src.Normals))
+	dst.Tangents = resizeVec4s(dst.Tangents, len(src.Tangents))
+	dst.UVs, dst.Indices = src.UVs, src.Indices
+
+	for v, position := range src.Positions {
+		m := w.Matrix(palette, v)
+		dst.Positions[v] = m.Mul4x1(position.Vec4(1)).Vec3()
+		if v < len(src.Normals) {
+			dst.Normals[v] = m.Mul4x1(src.Normals[v].Vec4(0)).Vec3().Normalize()
+		}
+		if v < len(src.Tangents) {
+			t := src.Tangents[v]
+			dst.Tangents[v] = m.Mul4x1(t.Vec3().Vec4(0)).Vec3().Normalize().Vec4(t[3])
+		}
+	}
+}
+
+// resizeVec3s returns s with length n, reallocating it if it's too short.
+func resizeVec3s(s []Vec3, n int) []Vec3 {
+	if cap(s) < n {
+		return make([]Vec3, n)
+	}
+	return s[:n]
+}
+
+// resizeVec4s returns s with length n, reallocating it if it's too short.
+func resizeVec4s(s []Vec4, n int) []Vec4 {
+	if cap(s) < n {
+		return make([]Vec4, n)
+	}
+	return s[:n]
+}
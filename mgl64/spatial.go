@@ -0,0 +1,458 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math"
+	"sort"
+)
+
+// LooseOctree is a spatial index of boxes that move around, such as the bounds of objects in a scene. Each node of the
+// tree covers a cube, but holds boxes that stick out of it by up to half its size, so every box fits in a node of about
+// its own size and moving it rarely changes its node. Boxes are identified by an id chosen by the caller.
+type LooseOctree struct {
+	root     *octreeNode
+	maxDepth int
+	// The node each id is in, for removal and updates
+	nodes map[int]*octreeNode
+}
+
+type octreeNode struct {
+	// The cube the node covers; its loose bounds are twice as big
+	center   Vec3
+	halfSize float64
+	parent   *octreeNode
+	children [8]*octreeNode
+	items    []octreeItem
+	// The number of items in this node and all of its descendants, so empty subtrees can be skipped and freed
+	total int
+}
+
+type octreeItem struct {
+	id  int
+	box AABB
+}
+
+// NewLooseOctree creates an octree covering bounds, which is grown into a cube. Boxes outside bounds can still be
+// inserted, but they are kept in the root node, which makes queries slower. The tree is at most maxDepth levels deep.
+func NewLooseOctree(bounds AABB, maxDepth int) *LooseOctree {
+	size := bounds.Size()
+	halfSize := size[0]
+	SetMax(&halfSize, &size[1])
+	SetMax(&halfSize, &size[2])
+
+	return &LooseOctree{
+		root:     &octreeNode{center: bounds.Center(), halfSize: halfSize / 2},
+		maxDepth: maxDepth,
+		nodes:    make(map[int]*octreeNode),
+	}
+}
+
+// Len returns the number of boxes in the tree.
+func (o *LooseOctree) Len() int {
+	return len(o.nodes)
+}
+
+// Insert adds a box to the tree, replacing any box already in it with the same id.
+func (o *LooseOctree) Insert(id int, box AABB) {
+	if _, ok := o.nodes[id]; ok {
+		o.Remove(id)
+	}
+
+	size := box.Size()
+	extent := size[0]
+	SetMax(&extent, &size[1])
+	SetMax(&extent, &size[2])
+	center := box.Center()
+
+	// Go down as long as the box fits in the loose bounds of the child containing its center, which it does
+	// whenever it's no bigger than the child's cube
+	node := o.root
+	for depth := 0; depth < o.maxDepth && extent <= node.halfSize && node.contains(center); depth++ {
+		octant := 0
+		for i := 0; i < 3; i++ {
+			if center[i] >= node.center[i] {
+				octant |= 1 << uint(i)
+			}
+		}
+
+		if node.children[octant] == nil {
+			childCenter := node.center
+			for i := 0; i < 3; i++ {
+				if octant&(1<<uint(i)) != 0 {
+					childCenter[i] += node.halfSize / 2
+				} else {
+					childCenter[i] -= node.halfSize / 2
+				}
+			}
+			node.children[octant] = &octreeNode{center: childCenter, halfSize: node.halfSize / 2, parent: node}
+		}
+		node = node.children[octant]
+	}
+
+	node.items = append(node.items, octreeItem{id, box})
+	o.nodes[id] = node
+	for n := node; n != nil; n = n.parent {
+		n.total++
+	}
+}
+
+// Remove removes the box with the given id from the tree, and returns whether it was there.
+func (o *LooseOctree) Remove(id int) bool {
+	node, ok := o.nodes[id]
+	if !ok {
+		return false
+	}
+	delete(o.nodes, id)
+
+	for i, item := range node.items {
+		if item.id == id {
+			node.items[i] = node.items[len(node.items)-1]
+			node.items = node.items[:len(node.items)-1]
+			break
+		}
+	}
+
+	for n := node; n != nil; n = n.parent {
+		n.total--
+		// Free empty subtrees
+		if n.total == 0 && n.parent != nil {
+			for i, c := range n.parent.children {
+				if c == n {
+					n.parent.children[i] = nil
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// Update moves the box with the given id. This is the same as inserting it again, but faster if the box is still inside
+// the loose bounds of its node, which is usually the case for objects moving a little at a time.
+func (o *LooseOctree) Update(id int, box AABB) {
+	if node, ok := o.nodes[id]; ok && node != o.root && node.loose().Union(box) == node.loose() {
+		for i := range node.items {
+			if node.items[i].id == id {
+				node.items[i].box = box
+				return
+			}
+		}
+	}
+
+	o.Insert(id, box)
+}
+
+func (n *octreeNode) contains(p Vec3) bool {
+	return Abs(p[0]-n.center[0]) <= n.halfSize && Abs(p[1]-n.center[1]) <= n.halfSize && Abs(p[2]-n.center[2]) <= n.halfSize
+}
+
+func (n *octreeNode) loose() AABB {
+	h := Vec3{2 * n.halfSize, 2 * n.halfSize, 2 * n.halfSize}
+	return AABB{n.center.Sub(h), n.center.Add(h)}
+}
+
+// QueryAABB appends the ids of the boxes overlapping box to dst and returns the result.
+func (o *LooseOctree) QueryAABB(box AABB, dst []int) []int {
+	return o.query(o.root, dst, box.Intersects)
+}
+
+// QuerySphere appends the ids of the boxes overlapping the sphere to dst and returns the result.
+func (o *LooseOctree) QuerySphere(center Vec3, radius float64, dst []int) []int {
+	return o.query(o.root, dst, func(box AABB) bool { return box.IntersectsSphere(center, radius) })
+}
+
+func (o *LooseOctree) query(n *octreeNode, dst []int, overlaps func(AABB) bool) []int {
+	// The root may hold boxes outside its bounds, so it's always searched
+	if n.total == 0 || (n != o.root && !overlaps(n.loose())) {
+		return dst
+	}
+
+	for _, item := range n.items {
+		if overlaps(item.box) {
+			dst = append(dst, item.id)
+		}
+	}
+	for _, c := range n.children {
+		if c != nil {
+			dst = o.query(c, dst, overlaps)
+		}
+	}
+
+	return dst
+}
+
+// KDTree is a spatial index of a fixed set of points, for finding nearest neighbours. It's a balanced binary tree,
+// splitting the points in half along the axis of greatest spread at each level.
+type KDTree struct {
+	Points []Vec3
+
+	// The tree is implicit: the node covering order[lo:hi] is order[mid] with mid = (lo+hi)/2, its left subtree
+	// is order[lo:mid] and its right subtree is order[mid+1:hi].
+	order []int32
+	// The axis each node splits along, indexed like order
+	axes []uint8
+}
+
+// NewKDTree builds a k-d tree over points. The points slice is kept (not copied) as the Points field, and must not be
+// modified while the tree is used.
+func NewKDTree(points []Vec3) *KDTree {
+	t := &KDTree{Points: points, order: make([]int32, len(points)), axes: make([]uint8, len(points))}
+	for i := range t.order {
+		t.order[i] = int32(i)
+	}
+	t.build(0, len(points))
+
+	return t
+}
+
+func (t *KDTree) build(lo, hi int) {
+	if hi-lo <= 1 {
+		return
+	}
+
+	bounds := EmptyAABB()
+	for _, i := range t.order[lo:hi] {
+		bounds = bounds.Extend(t.Points[i])
+	}
+	size := bounds.Size()
+	axis := 0
+	if size[1] > size[axis] {
+		axis = 1
+	}
+	if size[2] > size[axis] {
+		axis = 2
+	}
+
+	order := t.order[lo:hi]
+	sort.Slice(order, func(a, b int) bool { return t.Points[order[a]][axis] < t.Points[order[b]][axis] })
+
+	mid := (lo + hi) / 2
+	t.axes[mid] = uint8(axis)
+	t.build(lo, mid)
+	t.build(mid+1, hi)
+}
+
+// Nearest returns the index of the point nearest to p, or -1 if the tree is empty.
+func (t *KDTree) Nearest(p Vec3) int {
+	nearest := t.KNearest(p, 1, nil)
+	if len(nearest) == 0 {
+		return -1
+	}
+
+	return nearest[0]
+}
+
+// KNearest appends the indices of the k points nearest to p to dst, nearest first, and returns the result.
+// If there are fewer than k points, all of them are appended.
+func (t *KDTree) KNearest(p Vec3, k int, dst []int) []int {
+	if k <= 0 {
+		return dst
+	}
+
+	// The best points found so far, sorted by distance
+	var best []int32
+	var bestDist []float64
+	maxDist := float64(math.Inf(1))
+	t.search(p, 0, len(t.order), &maxDist, func(i int32, dist float64) {
+		j := sort.Search(len(bestDist), func(j int) bool { return bestDist[j] > dist })
+		if len(best) < k {
+			best, bestDist = append(best, 0), append(bestDist, 0)
+		} else if j == k {
+			return
+		}
+		copy(best[j+1:], best[j:])
+		copy(bestDist[j+1:], bestDist[j:])
+		best[j], bestDist[j] = i, dist
+
+		if len(best) == k {
+			maxDist = bestDist[k-1]
+		}
+	})
+
+	for _, i := range best {
+		dst = append(dst, int(i))
+	}
+	return dst
+}
+
+// Radius appends the indices of the points within radius of p to dst, in no particular order, and returns the result.
+func (t *KDTree) Radius(p Vec3, radius float64, dst []int) []int {
+	maxDist := radius * radius
+	t.search(p, 0, len(t.order), &maxDist, func(i int32, dist float64) {
+		dst = append(dst, int(i))
+	})
+
+	return dst
+}
+
+// search calls found for every point in the subtree order[lo:hi] whose squared distance to p is at most *maxDist,
+// which found may lower as it goes to prune the search.
+func (t *KDTree) search(p Vec3, lo, hi int, maxDist *float64, found func(i int32, dist float64)) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	i := t.order[mid]
+	d := t.Points[i].Sub(p)
+	if dist := d.Dot(d); dist <= *maxDist {
+		found(i, dist)
+	}
+
+	// Search the side p is on first, then the other side if the splitting plane is close enough
+	axis := t.axes[mid]
+	planeDist := p[axis] - t.Points[i][axis]
+	if planeDist < 0 {
+		t.search(p, lo, mid, maxDist, found)
+		if planeDist*planeDist <= *maxDist {
+			t.search(p, mid+1, hi, maxDist, found)
+		}
+	} else {
+		t.search(p, mid+1, hi, maxDist, found)
+		if planeDist*planeDist <= *maxDist {
+			t.search(p, lo, mid, maxDist, found)
+		}
+	}
+}
+
+// HashGrid is a spatial index of points, such as particles, that is rebuilt from scratch whenever they move. Space is
+// divided into cubic cells, which are hashed into a fixed size table so that the grid needs no bounds. Queries are
+// fastest when the query radius is about the size of a cell.
+type HashGrid struct {
+	CellSize float64
+	Points   []Vec3
+
+	// The points in bucket b are entries[cellStart[b]:cellStart[b+1]]
+	cellStart []int32
+	entries   []int32
+
+	// The bounds of the cells that have points in them
+	cellMin, cellMax [3]int32
+}
+
+// NewHashGrid creates an empty grid with the given cell size and number of hash buckets. A good number of buckets is
+// about the number of points.
+func NewHashGrid(cellSize float64, buckets int) *HashGrid {
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	return &HashGrid{CellSize: cellSize, cellStart: make([]int32, buckets+1)}
+}
+
+func (g *HashGrid) cell(p Vec3) [3]int32 {
+	return [3]int32{
+		int32(math.Floor(float64(p[0] / g.CellSize))),
+		int32(math.Floor(float64(p[1] / g.CellSize))),
+		int32(math.Floor(float64(p[2] / g.CellSize))),
+	}
+}
+
+func (g *HashGrid) bucket(c [3]int32) int {
+	h := uint32(c[0])*73856093 ^ uint32(c[1])*19349663 ^ uint32(c[2])*83492791
+	return int(h % uint32(len(g.cellStart)-1))
+}
+
+// Build indexes points, replacing whatever the grid held before. The points slice is kept (not copied) as the Points field.
+// This is a counting sort, so it's linear in the number of points and buckets.
+func (g *HashGrid) Build(points []Vec3) {
+	g.Points = points
+	for i := range g.cellStart {
+		g.cellStart[i] = 0
+	}
+	if cap(g.entries) < len(points) {
+		g.entries = make([]int32, len(points))
+	}
+	g.entries = g.entries[:len(points)]
+
+	buckets := make([]int32, len(points))
+	for i, p := range points {
+		c := g.cell(p)
+		if i == 0 {
+			g.cellMin, g.cellMax = c, c
+		}
+		for j := 0; j < 3; j++ {
+			if c[j] < g.cellMin[j] {
+				g.cellMin[j] = c[j]
+			}
+			if c[j] > g.cellMax[j] {
+				g.cellMax[j] = c[j]
+			}
+		}
+		buckets[i] = int32(g.bucket(c))
+		g.cellStart[buckets[i]+1]++
+	}
+	for b := 1; b < len(g.cellStart); b++ {
+		g.cellStart[b] += g.cellStart[b-1]
+	}
+
+	next := append([]int32(nil), g.cellStart[:len(g.cellStart)-1]...)
+	for i, b := range buckets {
+		g.entries[next[b]] = int32(i)
+		next[b]++
+	}
+}
+
+// QueryRadius appends the indices of the points within radius of center to dst and returns the result.
+func (g *HashGrid) QueryRadius(center Vec3, radius float64, dst []int) []int {
+	r := Vec3{radius, radius, radius}
+	return g.query(AABB{center.Sub(r), center.Add(r)}, dst, func(p Vec3) bool {
+		d := p.Sub(center)
+		return d.Dot(d) <= radius*radius
+	})
+}
+
+// QueryAABB appends the indices of the points inside box to dst and returns the result.
+func (g *HashGrid) QueryAABB(box AABB, dst []int) []int {
+	return g.query(box, dst, box.Contains)
+}
+
+func (g *HashGrid) query(box AABB, dst []int, inside func(Vec3) bool) []int {
+	if len(g.Points) == 0 {
+		return dst
+	}
+
+	// Only the cells between those of the points can have any in them
+	var min, max [3]int64
+	cells := 1.0
+	for j := 0; j < 3; j++ {
+		min[j], max[j] = int64(g.cellMin[j]), int64(g.cellMax[j])
+		if c := math.Floor(float64(box.Min[j] / g.CellSize)); c > float64(min[j]) {
+			min[j] = int64(math.Min(c, float64(max[j]+1)))
+		}
+		if c := math.Floor(float64(box.Max[j] / g.CellSize)); c < float64(max[j]) {
+			max[j] = int64(math.Max(c, float64(min[j]-1)))
+		}
+		cells *= float64(max[j] - min[j] + 1)
+	}
+
+	// Checking every point is quicker than visiting more cells than there are points
+	if cells > float64(len(g.Points)) {
+		for i, p := range g.Points {
+			if inside(p) {
+				dst = append(dst, i)
+			}
+		}
+		return dst
+	}
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			for z := min[2]; z <= max[2]; z++ {
+				c := [3]int32{int32(x), int32(y), int32(z)}
+				b := g.bucket(c)
+				for _, i := range g.entries[g.cellStart[b]:g.cellStart[b+1]] {
+					// Other cells may share the bucket, and would be visited again
+					if p := g.Points[i]; g.cell(p) == c && inside(p) {
+						dst = append(dst, int(i))
+					}
+				}
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,198 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mgl64
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func randomPoints(rand *rand.Rand, n int, scale float64) []Vec3 {
+	points := make([]Vec3, n)
+	for i := range points {
+		points[i] = Vec3{(rand.Float64()*2 - 1) * scale, (rand.Float64()*2 - 1) * scale, (rand.Float64()*2 - 1) * scale}
+	}
+
+	return points
+}
+
+func TestLooseOctree(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	o := NewLooseOctree(AABB{Vec3{-10, -10, -10}, Vec3{10, 10, 10}}, 8)
+
+	boxes := make(map[int]AABB)
+	randomBox := func() AABB {
+		min := Vec3{rand.Float64()*24 - 12, rand.Float64()*24 - 12, rand.Float64()*24 - 12}
+		size := rand.Float64() * rand.Float64() * 4
+		return AABB{min, min.Add(Vec3{size, rand.Float64() * size, size})}
+	}
+	for id := 0; id < 500; id++ {
+		boxes[id] = randomBox()
+		o.Insert(id, boxes[id])
+	}
+
+	check := func(stage string) {
+		if o.Len() != len(boxes) {
+			t.Errorf("%s: octree has %d boxes, expected %d", stage, o.Len(), len(boxes))
+		}
+
+		for q := 0; q < 50; q++ {
+			query := randomBox()
+			center, radius := randomPoints(rand, 1, 12)[0], rand.Float64()*3
+
+			var expectedBox, expectedSphere []int
+			for id, box := range boxes {
+				if box.Intersects(query) {
+					expectedBox = append(expectedBox, id)
+				}
+				if box.IntersectsSphere(center, radius) {
+					expectedSphere = append(expectedSphere, id)
+				}
+			}
+			sort.Ints(expectedBox)
+			sort.Ints(expectedSphere)
+
+			if found := o.QueryAABB(query, nil); !sameIndices(found, expectedBox) {
+				t.Errorf("%s: box query found %v, expected %v", stage, found, expectedBox)
+			}
+			if found := o.QuerySphere(center, radius, nil); !sameIndices(found, expectedSphere) {
+				t.Errorf("%s: sphere query found %v, expected %v", stage, found, expectedSphere)
+			}
+		}
+	}
+	check("after inserting")
+
+	// Move everything a little, and some things a lot
+	for id, box := range boxes {
+		offset := randomPoints(rand, 1, .5)[0]
+		if id%10 == 0 {
+			offset = offset.Mul(20)
+		}
+		box = AABB{box.Min.Add(offset), box.Max.Add(offset)}
+		boxes[id] = box
+		o.Update(id, box)
+	}
+	check("after updating")
+
+	for id := 0; id < 500; id += 3 {
+		if !o.Remove(id) {
+			t.Errorf("Removing box %d failed", id)
+		}
+		delete(boxes, id)
+	}
+	if o.Remove(0) {
+		t.Errorf("Removing box 0 twice succeeded")
+	}
+	check("after removing")
+}
+
+func TestKDTree(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := randomPoints(rand, 2000, 10)
+	// Duplicates and points sharing coordinates make ties at the splitting planes
+	points = append(points, points[:100]...)
+	for i := 0; i < 100; i++ {
+		points = append(points, Vec3{points[i][0], points[i+1][1], 0})
+	}
+	tree := NewKDTree(points)
+
+	for q := 0; q < 100; q++ {
+		p := randomPoints(rand, 1, 12)[0]
+		byDist := make([]int, len(points))
+		for i := range byDist {
+			byDist[i] = i
+		}
+		dist := func(i int) float64 { return points[i].Sub(p).Len() }
+		sort.Slice(byDist, func(i, j int) bool { return dist(byDist[i]) < dist(byDist[j]) })
+
+		if nearest := tree.Nearest(p); dist(nearest) != dist(byDist[0]) {
+			t.Errorf("Nearest point to %v is %d at %v, expected %d at %v", p, nearest, dist(nearest), byDist[0], dist(byDist[0]))
+		}
+
+		k := rand.Intn(20) + 1
+		nearest := tree.KNearest(p, k, nil)
+		if len(nearest) != k {
+			t.Fatalf("Found %d nearest points, expected %d", len(nearest), k)
+		}
+		for i, n := range nearest {
+			if dist(n) != dist(byDist[i]) {
+				t.Errorf("Nearest point %d to %v is %v away, expected %v", i, p, dist(n), dist(byDist[i]))
+			}
+		}
+
+		radius := rand.Float64() * 3
+		var expected []int
+		for i := range points {
+			if dist(i) <= radius {
+				expected = append(expected, i)
+			}
+		}
+		if found := tree.Radius(p, radius, nil); !sameIndices(found, expected) {
+			t.Errorf("Points within %v of %v are %v, expected %v", radius, p, found, expected)
+		}
+	}
+
+	if nearest := NewKDTree(nil).Nearest(Vec3{}); nearest != -1 {
+		t.Errorf("Nearest point in empty tree is %d, expected -1", nearest)
+	}
+	if nearest := NewKDTree(points[:3]).KNearest(Vec3{}, 5, nil); len(nearest) != 3 {
+		t.Errorf("5 nearest of 3 points are %v, expected all 3", nearest)
+	}
+}
+
+func TestHashGrid(t *testing.T) {
+	rand := rand.New(rand.NewSource(time.Now().UnixNano()))
+	points := randomPoints(rand, 3000, 10)
+
+	// Few buckets, to make sure hash collisions are handled
+	g := NewHashGrid(.7, 101)
+	for frame := 0; frame < 3; frame++ {
+		for i := range points {
+			points[i] = points[i].Add(randomPoints(rand, 1, 1)[0])
+		}
+		g.Build(points)
+
+		for q := 0; q < 50; q++ {
+			center, radius := randomPoints(rand, 1, 10)[0], rand.Float64()*2
+			box := AABB{center, center.Add(Vec3{rand.Float64(), rand.Float64() * 2, rand.Float64()})}
+
+			var expectedRadius, expectedBox []int
+			for i, p := range points {
+				if p.Sub(center).Len() <= radius {
+					expectedRadius = append(expectedRadius, i)
+				}
+				if box.Contains(p) {
+					expectedBox = append(expectedBox, i)
+				}
+			}
+
+			if found := g.QueryRadius(center, radius, nil); !sameIndices(found, expectedRadius) {
+				t.Errorf("Points within %v of %v are %v, expected %v", radius, center, found, expectedRadius)
+			}
+			if found := g.QueryAABB(box, nil); !sameIndices(found, expectedBox) {
+				t.Errorf("Points in %v are %v, expected %v", box, found, expectedBox)
+			}
+		}
+	}
+
+	// Queries much bigger than the points' cells, or far from them, don't visit every cell they cover
+	all := make([]int, len(points))
+	for i := range all {
+		all[i] = i
+	}
+	if found := g.QueryRadius(Vec3{}, 1000, nil); !sameIndices(found, all) {
+		t.Errorf("Points within 1000 of the origin are %v, expected all of them", found)
+	}
+	huge := AABB{Vec3{-1e30, -1e30, -1e30}, Vec3{1e30, 1e30, 1e30}}
+	if found := g.QueryAABB(huge, nil); !sameIndices(found, all) {
+		t.Errorf("Points in %v are %v, expected all of them", huge, found)
+	}
+	far := AABB{Vec3{1e9, -1e30, -1e30}, Vec3{1e30, 1e30, 1e30}}
+	if found := g.QueryAABB(far, nil); len(found) != 0 {
+		t.Errorf("Points in %v are %v, expected none", far, found)
+	}
+}
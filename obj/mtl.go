@@ -0,0 +1,198 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package obj
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Material is a material from an MTL file. Colors are as written, which is usually linear RGB. Texture maps are file
+// names relative to the MTL file, or "" for none; their options, such as -bm and -s, are skipped.
+type Material struct {
+	Name string
+
+	Ambient          mgl32.Vec3 // Ka
+	Diffuse          mgl32.Vec3 // Kd
+	Specular         mgl32.Vec3 // Ks
+	Emissive         mgl32.Vec3 // Ke
+	SpecularExponent float32    // Ns
+	Opacity          float32    // d, or 1 - Tr
+	RefractiveIndex  float32    // Ni
+	Illumination     int        // illum, the illumination model
+
+	AmbientMap          string // map_Ka
+	DiffuseMap          string // map_Kd
+	SpecularMap         string // map_Ks
+	SpecularExponentMap string // map_Ns
+	EmissiveMap         string // map_Ke
+	OpacityMap          string // map_d
+	BumpMap             string // map_bump or bump
+	DisplacementMap     string // disp
+	NormalMap           string // norm
+}
+
+// textureOptions holds the number of arguments of each texture map option. -o, -s and -t take one to three.
+var textureOptions = map[string]int{
+	"-blendu": 1, "-blendv": 1, "-bm": 1, "-boost": 1, "-cc": 1, "-clamp": 1, "-imfchan": 1, "-mm": 2,
+	"-o": 3, "-s": 3, "-t": 3, "-texres": 1, "-type": 1,
+}
+
+// LoadMaterials reads the materials of an MTL file. Errors name the file and line.
+func LoadMaterials(path string) (map[string]*Material, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	materials, err := ReadMaterials(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", path, err)
+	}
+	return materials, nil
+}
+
+// ReadMaterials reads the materials of an MTL file, by name. Errors start with the line number.
+func ReadMaterials(r io.Reader) (map[string]*Material, error) {
+	materials := make(map[string]*Material)
+	var m *Material
+
+	err := readStatements(r, func(keyword string, args []string) error {
+		if keyword == "newmtl" {
+			if len(args) == 0 {
+				return errors.New("material has no name")
+			}
+			m = &Material{Name: strings.Join(args, " "), Diffuse: mgl32.Vec3{1, 1, 1}, Opacity: 1, RefractiveIndex: 1}
+			materials[m.Name] = m
+			return nil
+		}
+		if m == nil {
+			return errors.New("statement before the first newmtl")
+		}
+
+		var color *mgl32.Vec3
+		var number *float32
+		var texture *string
+		switch keyword {
+		case "Ka":
+			color = &m.Ambient
+		case "Kd":
+			color = &m.Diffuse
+		case "Ks":
+			color = &m.Specular
+		case "Ke":
+			color = &m.Emissive
+		case "Ns":
+			number = &m.SpecularExponent
+		case "d":
+			number = &m.Opacity
+		case "Ni":
+			number = &m.RefractiveIndex
+		case "Tr":
+			var transparency float32
+			if err := parseNumber(args, &transparency); err != nil {
+				return err
+			}
+			m.Opacity = 1 - transparency
+		case "illum":
+			if len(args) != 1 {
+				return errors.New("illumination model needs one argument")
+			}
+			illum, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("illumination model %q isn't a number", args[0])
+			}
+			m.Illumination = illum
+		case "map_Ka":
+			texture = &m.AmbientMap
+		case "map_Kd":
+			texture = &m.DiffuseMap
+		case "map_Ks":
+			texture = &m.SpecularMap
+		case "map_Ns":
+			texture = &m.SpecularExponentMap
+		case "map_Ke":
+			texture = &m.EmissiveMap
+		case "map_d":
+			texture = &m.OpacityMap
+		case "map_bump", "map_Bump", "bump":
+			texture = &m.BumpMap
+		case "disp":
+			texture = &m.DisplacementMap
+		case "norm":
+			texture = &m.NormalMap
+		}
+
+		switch {
+		case color != nil:
+			if len(args) > 0 && (args[0] == "spectral" || args[0] == "xyz") {
+				return fmt.Errorf("%s colors aren't supported", args[0])
+			}
+			// One number is a gray
+			c, err := parseFloats(args, 1, 3)
+			if err != nil {
+				return err
+			}
+			if len(args) < 3 {
+				c[1], c[2] = c[0], c[0]
+			}
+			*color = mgl32.Vec3{c[0], c[1], c[2]}
+		case number != nil:
+			return parseNumber(args, number)
+		case texture != nil:
+			file, err := textureFile(args)
+			if err != nil {
+				return err
+			}
+			*texture = file
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+func parseNumber(args []string, number *float32) error {
+	v, err := parseFloats(args, 1, 1)
+	if err != nil {
+		return err
+	}
+	*number = v[0]
+	return nil
+}
+
+// textureFile returns the file name of a texture map statement, after its options. The name may contain spaces.
+func textureFile(args []string) (string, error) {
+	for len(args) > 0 {
+		n, ok := textureOptions[args[0]]
+		if !ok {
+			break
+		}
+		args = args[1:]
+
+		// Skip up to n arguments, but only numbers for the options that take several
+		for i := 0; i < n && len(args) > 0; i++ {
+			if _, err := strconv.ParseFloat(args[0], 32); err != nil && n > 1 && i > 0 {
+				break
+			}
+			args = args[1:]
+		}
+	}
+
+	if len(args) == 0 {
+		return "", errors.New("texture map has no file name")
+	}
+	return strings.Join(args, " "), nil
+}
@@ -0,0 +1,357 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package obj reads Wavefront OBJ models and their MTL material libraries into mgl32 vertex data.
+//
+// Faces with any number of vertices are triangulated as fans, with or without texture coordinates and normals, and
+// with positive or negative (relative) indices. Faces without normals get normals computed from their smoothing group.
+// Free form geometry, lines and points are ignored, as are statements the package doesn't know.
+package obj
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Model is a model read from an OBJ file. All its faces share one mesh, so it can be uploaded as a single set of
+// buffers and drawn a part at a time.
+type Model struct {
+	// Mesh holds the vertices of the faces, one for each distinct combination of position, texture coordinates and
+	// normal. UVs is nil if no face has texture coordinates; otherwise vertices without them have (0,0). Tangents is
+	// nil.
+	Mesh *mgl32.Mesh
+
+	// Parts divide the mesh's indices into runs of faces with the same object, groups and material, in file order.
+	Parts []Part
+
+	// MaterialLibraries are the MTL files named by mtllib statements, in order and relative to the OBJ file.
+	MaterialLibraries []string
+
+	// Materials are the materials from the material libraries, by name. Read leaves it empty, while Load fills it.
+	Materials map[string]*Material
+}
+
+// Part is a run of faces with the same object, groups and material.
+type Part struct {
+	Object   string   // The name from the last o statement, or ""
+	Groups   []string // The names from the last g statement, or nil
+	Material string   // The name from the last usemtl statement, or ""
+
+	// The faces are Mesh.Indices[First:First+Count]
+	First, Count int
+}
+
+// vertexKey identifies a distinct vertex. A vertex whose normal is computed can only be shared by faces of the
+// same smoothing group, or in smoothing group 0 (flat shading), only by the triangles of one face.
+type vertexKey struct {
+	position, uv, normal int // Indices from 0, or -1 for none
+	smoothing            int
+}
+
+// reader holds the state of reading an OBJ file.
+type reader struct {
+	positions []mgl32.Vec3
+	uvs       []mgl32.Vec2
+	normals   []mgl32.Vec3
+
+	model    *Model
+	vertices map[vertexKey]uint32
+	computed []bool // Whether each vertex's normal is to be computed
+
+	object, material string
+	groups           []string
+	smoothing        int
+	faces            int
+	newPart          bool
+}
+
+// Load reads an OBJ file and the material libraries it names, which must exist. Errors name the file and line.
+func Load(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	model, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", path, err)
+	}
+
+	for _, library := range model.MaterialLibraries {
+		libraryPath := filepath.Join(filepath.Dir(path), filepath.FromSlash(library))
+		materials, err := LoadMaterials(libraryPath)
+		if err != nil {
+			return nil, err
+		}
+		for name, m := range materials {
+			model.Materials[name] = m
+		}
+	}
+
+	return model, nil
+}
+
+// Read reads an OBJ model, without its materials. Errors start with the line number.
+func Read(r io.Reader) (*Model, error) {
+	rd := &reader{
+		model:    &Model{Mesh: &mgl32.Mesh{}, Materials: make(map[string]*Material)},
+		vertices: make(map[vertexKey]uint32),
+		newPart:  true,
+	}
+
+	err := readStatements(r, func(keyword string, args []string) error {
+		switch keyword {
+		case "v":
+			v, err := parseFloats(args, 3, 3)
+			if err != nil {
+				return err
+			}
+			rd.positions = append(rd.positions, mgl32.Vec3{v[0], v[1], v[2]})
+		case "vt":
+			v, err := parseFloats(args, 1, 2)
+			if err != nil {
+				return err
+			}
+			rd.uvs = append(rd.uvs, mgl32.Vec2{v[0], v[1]})
+		case "vn":
+			v, err := parseFloats(args, 3, 3)
+			if err != nil {
+				return err
+			}
+			rd.normals = append(rd.normals, mgl32.Vec3{v[0], v[1], v[2]})
+		case "f":
+			return rd.face(args)
+		case "o":
+			rd.object, rd.newPart = strings.Join(args, " "), true
+		case "g":
+			rd.groups, rd.newPart = args, true
+		case "usemtl":
+			rd.material, rd.newPart = strings.Join(args, " "), true
+		case "s":
+			if len(args) != 1 {
+				return errors.New("smoothing group needs one argument")
+			}
+			if args[0] == "off" {
+				rd.smoothing = 0
+				return nil
+			}
+			s, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("smoothing group %q isn't a number or off", args[0])
+			}
+			rd.smoothing = s
+		case "mtllib":
+			rd.model.MaterialLibraries = append(rd.model.MaterialLibraries, args...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rd.computeNormals()
+	return rd.model, nil
+}
+
+// readStatements calls f with the keyword and arguments of each statement of an OBJ or MTL file, skipping comments and
+// joining lines continued with a backslash. Errors are prefixed with the line number.
+func readStatements(r io.Reader, f func(keyword string, args []string) error) error {
+	scanner := bufio.NewScanner(r)
+	line, statement := 0, ""
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if strings.HasSuffix(text, "\\") {
+			statement += text[:len(text)-1] + " "
+			continue
+		}
+
+		fields := strings.Fields(statement + text)
+		statement = ""
+		if len(fields) == 0 {
+			continue
+		}
+		if err := f(fields[0], fields[1:]); err != nil {
+			return fmt.Errorf("%d: %s: %v", line, fields[0], err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseFloats parses at least min numbers and returns max numbers, with missing ones 0 and extra ones ignored.
+func parseFloats(args []string, min, max int) ([]float32, error) {
+	if len(args) < min {
+		return nil, fmt.Errorf("%d numbers, expected at least %d", len(args), min)
+	}
+
+	v := make([]float32, max)
+	for i := 0; i < max && i < len(args); i++ {
+		f, err := strconv.ParseFloat(args[i], 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q isn't a number", args[i])
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
+
+// index converts an OBJ index, from 1 or relative to the end if negative, to an index from 0 into n elements.
+func index(s string, n int, what string) (int, error) {
+	i, err := strconv.Atoi(s)
+	switch {
+	case err != nil:
+		return 0, fmt.Errorf("%s index %q isn't a number", what, s)
+	case i > 0 && i <= n:
+		return i - 1, nil
+	case i < 0 && -i <= n:
+		return n + i, nil
+	}
+	return 0, fmt.Errorf("%s index %d is out of range, with %d %ss", what, i, n, what)
+}
+
+// face adds a face, triangulated as a fan around its first vertex.
+func (rd *reader) face(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("face has %d vertices, expected at least 3", len(args))
+	}
+	rd.faces++
+
+	mesh := rd.model.Mesh
+	if rd.newPart {
+		rd.model.Parts = append(rd.model.Parts, Part{
+			Object:   rd.object,
+			Groups:   rd.groups,
+			Material: rd.material,
+			First:    len(mesh.Indices),
+		})
+		rd.newPart = false
+	}
+
+	vertices := make([]uint32, len(args))
+	for i, arg := range args {
+		v, err := rd.vertex(arg)
+		if err != nil {
+			return err
+		}
+		vertices[i] = v
+	}
+
+	for i := 2; i < len(vertices); i++ {
+		mesh.Indices = append(mesh.Indices, vertices[0], vertices[i-1], vertices[i])
+	}
+	rd.model.Parts[len(rd.model.Parts)-1].Count = len(mesh.Indices) - rd.model.Parts[len(rd.model.Parts)-1].First
+
+	return nil
+}
+
+// vertex finds or adds the vertex for a v, v/vt, v//vn or v/vt/vn reference.
+func (rd *reader) vertex(ref string) (uint32, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("vertex %q has too many parts", ref)
+	}
+
+	key := vertexKey{uv: -1, normal: -1}
+	var err error
+	if key.position, err = index(parts[0], len(rd.positions), "position"); err != nil {
+		return 0, err
+	}
+	if len(parts) > 1 && parts[1] != "" {
+		if key.uv, err = index(parts[1], len(rd.uvs), "texture coordinate"); err != nil {
+			return 0, err
+		}
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		if key.normal, err = index(parts[2], len(rd.normals), "normal"); err != nil {
+			return 0, err
+		}
+	}
+	if key.normal < 0 {
+		key.smoothing = rd.smoothing
+		if rd.smoothing == 0 {
+			// Tell apart the vertices of each flat face with a smoothing group that is never used
+			key.smoothing = -rd.faces
+		}
+	}
+
+	if v, ok := rd.vertices[key]; ok {
+		return v, nil
+	}
+
+	mesh := rd.model.Mesh
+	v := uint32(len(mesh.Positions))
+	rd.vertices[key] = v
+	mesh.Positions = append(mesh.Positions, rd.positions[key.position])
+
+	if key.uv >= 0 && mesh.UVs == nil {
+		// The first vertex with texture coordinates, so the earlier vertices need them too
+		mesh.UVs = make([]mgl32.Vec2, len(mesh.Positions)-1)
+	}
+	if mesh.UVs != nil {
+		uv := mgl32.Vec2{}
+		if key.uv >= 0 {
+			uv = rd.uvs[key.uv]
+		}
+		mesh.UVs = append(mesh.UVs, uv)
+	}
+
+	normal := mgl32.Vec3{}
+	if key.normal >= 0 {
+		normal = rd.normals[key.normal]
+	}
+	mesh.Normals = append(mesh.Normals, normal)
+	rd.computed = append(rd.computed, key.normal < 0)
+
+	return v, nil
+}
+
+// computeNormals sets the normals of the vertices that have none to the average of the normals of their faces,
+// weighted by the angle of each face at the vertex, so the result doesn't depend on how the faces are triangulated.
+func (rd *reader) computeNormals() {
+	mesh := rd.model.Mesh
+	for i := 0; i < len(mesh.Indices); i += 3 {
+		corners := [3]uint32{mesh.Indices[i], mesh.Indices[i+1], mesh.Indices[i+2]}
+		var edges [3]mgl32.Vec3
+		for j, v := range corners {
+			edges[j] = mesh.Positions[corners[(j+1)%3]].Sub(mesh.Positions[v])
+		}
+
+		normal := edges[0].Cross(edges[1])
+		if normal.Len() == 0 {
+			continue
+		}
+		normal = normal.Normalize()
+
+		for j, v := range corners {
+			if !rd.computed[v] {
+				continue
+			}
+			// The angle between the edges leaving the corner
+			a, b := edges[j], edges[(j+2)%3].Mul(-1)
+			angle := math.Atan2(float64(a.Cross(b).Len()), float64(a.Dot(b)))
+			mesh.Normals[v] = mesh.Normals[v].Add(normal.Mul(float32(angle)))
+		}
+	}
+
+	for v, computed := range rd.computed {
+		if n := mesh.Normals[v]; computed && n.Len() > 0 {
+			mesh.Normals[v] = n.Normalize()
+		}
+	}
+}
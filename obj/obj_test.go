@@ -0,0 +1,235 @@
+// Copyright 2014 The go-gl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package obj
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const quadAndTriangle = `# A quad and a triangle
+mtllib scene.mtl
+o Floor
+v 0 0 0
+v 1 0 0
+v 1 0 -1
+v 0 0 -1
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn 0 1 0
+usemtl Stone
+f 1/1/1 2/2/1 3/3/1 4/4/1
+
+o Marker
+g a b
+v 0 1 0
+v 1 1 0 \
+  1.0
+usemtl Paint
+f -2 -1 -3/4
+`
+
+func TestRead(t *testing.T) {
+	model, err := Read(strings.NewReader(quadAndTriangle))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	mesh := model.Mesh
+	if len(mesh.Positions) != 7 || len(mesh.UVs) != 7 || len(mesh.Normals) != 7 || mesh.Tangents != nil {
+		t.Fatalf("Mesh has %d positions, %d UVs, %d normals and %d tangents, expected 7, 7, 7 and none",
+			len(mesh.Positions), len(mesh.UVs), len(mesh.Normals), len(mesh.Tangents))
+	}
+	if indices := []uint32{0, 1, 2, 0, 2, 3, 4, 5, 6}; !reflect.DeepEqual(mesh.Indices, indices) {
+		t.Errorf("Indices are %v, expected %v", mesh.Indices, indices)
+	}
+
+	// The triangle has no normals, so they're its face normal
+	slope := mgl32.Vec3{0, 1, -1}.Normalize()
+	expected := []struct {
+		position, normal mgl32.Vec3
+		uv               mgl32.Vec2
+	}{
+		{mgl32.Vec3{1, 0, -1}, mgl32.Vec3{0, 1, 0}, mgl32.Vec2{1, 1}},
+		{mgl32.Vec3{0, 1, 0}, slope, mgl32.Vec2{0, 0}},
+		{mgl32.Vec3{1, 1, 0}, slope, mgl32.Vec2{0, 0}},
+		{mgl32.Vec3{0, 0, -1}, slope, mgl32.Vec2{0, 1}},
+	}
+	for i, e := range expected {
+		v := []int{2, 4, 5, 6}[i]
+		if mesh.Positions[v] != e.position || !mesh.Normals[v].ApproxEqual(e.normal) || mesh.UVs[v] != e.uv {
+			t.Errorf("Vertex %d is %v, %v and %v, expected %v", v, mesh.Positions[v], mesh.Normals[v], mesh.UVs[v], e)
+		}
+	}
+
+	parts := []Part{
+		{Object: "Floor", Material: "Stone", First: 0, Count: 6},
+		{Object: "Marker", Groups: []string{"a", "b"}, Material: "Paint", First: 6, Count: 3},
+	}
+	if !reflect.DeepEqual(model.Parts, parts) {
+		t.Errorf("Parts are %+v, expected %+v", model.Parts, parts)
+	}
+	if !reflect.DeepEqual(model.MaterialLibraries, []string{"scene.mtl"}) {
+		t.Errorf("Material libraries are %v", model.MaterialLibraries)
+	}
+}
+
+func TestSmoothingGroups(t *testing.T) {
+	// Two faces of a cube meeting at the edge from vertex 1 to 2, smooth then flat
+	const faces = `
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+v 1 0 -1
+v 1 1 -1
+f 1 2 3 4
+f 2 5 6 3
+`
+	smooth, err := Read(strings.NewReader("s 1" + faces))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if n := len(smooth.Mesh.Positions); n != 6 {
+		t.Errorf("Smooth faces have %d vertices, expected them to share the edge's 2 of 8", n)
+	}
+	if n, expected := smooth.Mesh.Normals[1], (mgl32.Vec3{1, 0, 1}).Normalize(); !n.ApproxEqual(expected) {
+		t.Errorf("Normal on the smooth edge is %v, expected %v", n, expected)
+	}
+
+	flat, err := Read(strings.NewReader("s off" + faces))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if n := len(flat.Mesh.Positions); n != 8 {
+		t.Errorf("Flat faces have %d vertices, expected 8", n)
+	}
+	for i, n := range flat.Mesh.Normals {
+		if n != (mgl32.Vec3{0, 0, 1}) && n != (mgl32.Vec3{1, 0, 0}) {
+			t.Errorf("Normal %d of flat faces is %v", i, n)
+		}
+	}
+	if flat.Mesh.UVs != nil {
+		t.Errorf("Faces without texture coordinates have UVs %v", flat.Mesh.UVs)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		obj, message string
+	}{
+		{"v 1 2", "1: v: 2 numbers, expected at least 3"},
+		{"v 1 2 3\nvn 0 x 0", `2: vn: "x" isn't a number`},
+		{"v 1 2 3\nv 1 2 3\n\nf 1 2", "4: f: face has 2 vertices, expected at least 3"},
+		{"v 1 2 3\nf 1 1 4", "2: f: position index 4 is out of range, with 1 positions"},
+		{"v 1 2 3\nf 1 -2 1", "2: f: position index -2 is out of range, with 1 positions"},
+		{"v 1 2 3\nf 1/1 1 1", "2: f: texture coordinate index 1 is out of range, with 0 texture coordinates"},
+		{"v 1 2 3\nf 0 1 1", "2: f: position index 0 is out of range, with 1 positions"},
+		{"v 1 2 3\nf 1/a 1 1", `2: f: texture coordinate index "a" isn't a number`},
+		{"v 1 2 3\nf 1/1/1/1 1 1", `2: f: vertex "1/1/1/1" has too many parts`},
+		{"s x", `1: s: smoothing group "x" isn't a number or off`},
+	}
+
+	for _, c := range tests {
+		if _, err := Read(strings.NewReader(c.obj)); err == nil || err.Error() != c.message {
+			t.Errorf("Reading %q gives error %v, expected %q", c.obj, err, c.message)
+		}
+	}
+}
+
+const sceneMTL = `newmtl Stone
+Ka 0.1
+Kd 0.5 0.4 0.3
+Ns 10
+Tr 0.25
+illum 2
+map_Kd -s 2 2 -bm 0.5 textures/stone diffuse.png
+bump -bm 2 stone_bump.png
+
+newmtl Paint
+Kd 1 0 0
+d 0.5
+`
+
+func TestReadMaterials(t *testing.T) {
+	materials, err := ReadMaterials(strings.NewReader(sceneMTL))
+	if err != nil {
+		t.Fatalf("ReadMaterials failed: %v", err)
+	}
+
+	expected := map[string]*Material{
+		"Stone": {
+			Name:             "Stone",
+			Ambient:          mgl32.Vec3{.1, .1, .1},
+			Diffuse:          mgl32.Vec3{.5, .4, .3},
+			SpecularExponent: 10,
+			Opacity:          .75,
+			RefractiveIndex:  1,
+			Illumination:     2,
+			DiffuseMap:       "textures/stone diffuse.png",
+			BumpMap:          "stone_bump.png",
+		},
+		"Paint": {Name: "Paint", Diffuse: mgl32.Vec3{1, 0, 0}, Opacity: .5, RefractiveIndex: 1},
+	}
+	if !reflect.DeepEqual(materials, expected) {
+		for name, m := range materials {
+			t.Errorf("Material %s is %+v, expected %+v", name, m, expected[name])
+		}
+	}
+
+	for mtl, message := range map[string]string{
+		"Kd 1 1 1":           "1: Kd: statement before the first newmtl",
+		"newmtl a\nNs":       "2: Ns: 0 numbers, expected at least 1",
+		"newmtl a\nmap_Kd":   "2: map_Kd: texture map has no file name",
+		"newmtl a\nKd xyz 1": "2: Kd: xyz colors aren't supported",
+	} {
+		if _, err := ReadMaterials(strings.NewReader(mtl)); err == nil || err.Error() != message {
+			t.Errorf("Reading %q gives error %v, expected %q", mtl, err, message)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	objPath := filepath.Join(dir, "scene.obj")
+	if err := ioutil.WriteFile(objPath, []byte(quadAndTriangle), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(objPath); err == nil || !strings.Contains(err.Error(), "scene.mtl") {
+		t.Errorf("Loading with a missing material library gives error %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "scene.mtl"), []byte(sceneMTL), 0666); err != nil {
+		t.Fatal(err)
+	}
+	model, err := Load(objPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(model.Materials) != 2 || model.Materials[model.Parts[0].Material].DiffuseMap != "textures/stone diffuse.png" {
+		t.Errorf("Materials are %v", model.Materials)
+	}
+
+	if err := ioutil.WriteFile(objPath, []byte("v 1 2 3\nf 1 1 2\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(objPath); err == nil || err.Error() != objPath+":2: f: position index 2 is out of range, with 1 positions" {
+		t.Errorf("Loading a broken file gives error %v", err)
+	}
+}